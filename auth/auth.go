@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"crypto/subtle"
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
@@ -8,6 +9,8 @@ import (
 	"go.uber.org/zap"
 	"logo_api/model"
 	"logo_api/service"
+	"logo_api/settings"
+	"strings"
 	"time"
 )
 
@@ -140,79 +143,150 @@ func GetTokenExpiration(tokenString string) (time.Time, error) {
 // AuthRequired 认证中间件 (接受 Service 实例)
 func AuthRequired(svc *service.ResourceService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 从 Header 中获取完整的 Authorization 字符串
-		authHeader := c.GetHeader("Authorization")
-
-		// 1. 检查 JWT 签名和有效期
-		claims, err := CheckToken(authHeader) // CheckToken 已经完成了签名和有效期检查
-		if err != nil || claims == nil {
-			zap.L().Error("CheckToken() failed", zap.Error(err))
-			model.Error(c, model.CodeUnauthorized, "Unauthorized: Invalid or expired token. "+err.Error())
-			c.Abort()
-			return
+		if authenticate(c) {
+			c.Next()
 		}
+	}
+}
 
-		// -----------------------------------------------------
-		// 2. SSO / Blacklist 状态校验 (重点新增)
-		// -----------------------------------------------------
-
-		tokenString := authHeader[len("Bearer "):] // 提取裸 Token 字符串
-
-		// 2.1 检查 Token 是否在黑名单中 (用于登出/强制撤销)
-		isBlacklisted, err := service.IsTokenBlacklisted(c.Request.Context(), tokenString)
-		if err != nil {
-			// Redis 查询错误
-			zap.L().Error("AuthRequired: Blacklist check failed",
-				zap.String("token", tokenString),
-				zap.Int("userID", claims.UserID),
-				zap.Error(err))
-			model.Error(c, model.CodeServerErr, "Server error during token verification.")
-			c.Abort()
-			return
-		}
-		if isBlacklisted {
-			zap.L().Warn("AuthRequired: Token is blacklisted",
-				zap.String("token", tokenString),
-				zap.Int("userID", claims.UserID))
-			model.Error(c, model.CodeUnauthorized, "Token has been revoked.")
-			c.Abort()
-			return
-		}
+// authenticate 校验 JWT、黑名单与 SSO 状态，成功时把用户信息写入 Context；失败时已写出响应并 Abort
+func authenticate(c *gin.Context) bool {
+	// 从 Header 中获取完整的 Authorization 字符串
+	authHeader := c.GetHeader("Authorization")
+
+	// 1. 检查 JWT 签名和有效期
+	claims, err := CheckToken(authHeader) // CheckToken 已经完成了签名和有效期检查
+	if err != nil || claims == nil {
+		zap.L().Error("CheckToken() failed", zap.Error(err))
+		model.Error(c, model.CodeUnauthorized, "Unauthorized: Invalid or expired token. "+err.Error())
+		c.Abort()
+		return false
+	}
 
-		// 2.2 检查 SSO (单点登录) 状态
-		// 只有当客户端 Token == Redis 中存储的 Token 时，才有效
-		redisToken, err := service.GetUserSessionToken(c.Request.Context(), claims.UserID)
-		if err != nil && !errors.Is(err, service.ErrSessionNotFound) {
-			// 匹配到数据库或 Redis 查询错误 (非 Key 不存在，而是连接或I/O错误)
-			zap.L().Error("AuthRequired: SSO check failed due to server error",
-				zap.Int("userID", claims.UserID),
-				zap.Error(err))
-			model.Error(c, model.CodeServerErr, "Server error during session check.")
-			c.Abort()
-			return
+	// -----------------------------------------------------
+	// 2. SSO / Blacklist 状态校验 (重点新增)
+	// -----------------------------------------------------
+
+	tokenString := authHeader[len("Bearer "):] // 提取裸 Token 字符串
+
+	// 2.1 检查 Token 是否在黑名单中 (用于登出/强制撤销)
+	isBlacklisted, err := service.IsTokenBlacklisted(c.Request.Context(), tokenString)
+	if err != nil {
+		// Redis 查询错误
+		zap.L().Error("AuthRequired: Blacklist check failed",
+			zap.String("token", tokenString),
+			zap.Int("userID", claims.UserID),
+			zap.Error(err))
+		model.Error(c, model.CodeServerErr, "Server error during token verification.")
+		c.Abort()
+		return false
+	}
+	if isBlacklisted {
+		zap.L().Warn("AuthRequired: Token is blacklisted",
+			zap.String("token", tokenString),
+			zap.Int("userID", claims.UserID))
+		model.Error(c, model.CodeUnauthorized, "Token has been revoked.")
+		c.Abort()
+		return false
+	}
+
+	// 2.2 检查 SSO (单点登录) 状态
+	// 只有当客户端 Token == Redis 中存储的 Token 时，才有效
+	redisToken, err := service.GetUserSessionToken(c.Request.Context(), claims.UserID)
+	if err != nil && !errors.Is(err, service.ErrSessionNotFound) {
+		// 匹配到数据库或 Redis 查询错误 (非 Key 不存在，而是连接或I/O错误)
+		zap.L().Error("AuthRequired: SSO check failed due to server error",
+			zap.Int("userID", claims.UserID),
+			zap.Error(err))
+		model.Error(c, model.CodeServerErr, "Server error during session check.")
+		c.Abort()
+		return false
+	}
+
+	// 检查 Session 失效的条件：
+	// 1. err 是 service.ErrSessionNotFound (Session不存在/已过期)
+	// 2. 或者 Redis 中存储的 Token 不匹配请求中的 Token (被新登录覆盖)
+	if errors.Is(err, service.ErrSessionNotFound) || redisToken != tokenString {
+		zap.L().Info("AuthRequired: Session unauthorized or overwritten",
+			zap.Int("userID", claims.UserID),
+			zap.String("requestToken", tokenString),
+			zap.String("redisToken", redisToken))
+		model.Error(c, model.CodeUnauthorized, "Session expired or overwritten by a new login.")
+		c.Abort()
+		return false
+	}
+
+	// -----------------------------------------------------
+
+	// 3. 校验通过，设置 Context 并继续
+	c.Set("user_id", claims.UserID)
+	c.Set("username", claims.Username)
+	c.Set("user_claims", claims)
+	c.Set("tokenString", tokenString) // 存储 Token 字符串，方便 Logout 接口使用
+	zap.L().Info("AuthRequired: Success", zap.Int("userID", claims.UserID))
+	return true
+}
+
+// -------------------------------------------------------------
+// 4. 管理员中间件 (必须放在 AuthRequired 之后)
+// -------------------------------------------------------------
+
+// AdminRequired 校验当前登录用户是否在 settings 配置的管理员列表中
+func AdminRequired() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if checkAdmin(c) {
+			c.Next()
 		}
+	}
+}
+
+// checkAdmin 校验 Context 中的用户是否为管理员；失败时已写出响应并 Abort
+func checkAdmin(c *gin.Context) bool {
+	username := c.GetString("username")
+	if username == "" {
+		zap.L().Error("AdminRequired: username not found in context")
+		model.Error(c, model.CodeUnauthorized)
+		c.Abort()
+		return false
+	}
+	if !IsAdmin(username) {
+		zap.L().Warn("AdminRequired: permission denied", zap.String("username", username))
+		model.Error(c, model.CodeForbidden)
+		c.Abort()
+		return false
+	}
+	return true
+}
+
+// CacheSecretHeader 定时触发器调用 /clearCache 时携带共享密钥的请求头
+const CacheSecretHeader = "X-Cache-Clear-Secret"
 
-		// 检查 Session 失效的条件：
-		// 1. err 是 service.ErrSessionNotFound (Session不存在/已过期)
-		// 2. 或者 Redis 中存储的 Token 不匹配请求中的 Token (被新登录覆盖)
-		if errors.Is(err, service.ErrSessionNotFound) || redisToken != tokenString {
-			zap.L().Info("AuthRequired: Session unauthorized or overwritten",
-				zap.Int("userID", claims.UserID),
-				zap.String("requestToken", tokenString),
-				zap.String("redisToken", redisToken))
-			model.Error(c, model.CodeUnauthorized, "Session expired or overwritten by a new login.")
-			c.Abort()
+// SecretOrAdminRequired 共享密钥或管理员登录二选一：
+// 请求头中的密钥与配置的 cache_clear_secret 一致时直接放行，否则按 AuthRequired + AdminRequired 校验
+func SecretOrAdminRequired(svc *service.ResourceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		secret := settings.Config.CacheClearSecret
+		reqSecret := c.GetHeader(CacheSecretHeader)
+		if secret != "" && reqSecret != "" && subtle.ConstantTimeCompare([]byte(secret), []byte(reqSecret)) == 1 {
+			zap.L().Info("SecretOrAdminRequired: shared secret accepted")
+			c.Next()
 			return
 		}
+		if authenticate(c) && checkAdmin(c) {
+			c.Next()
+		}
+	}
+}
 
-		// -----------------------------------------------------
-
-		// 3. 校验通过，设置 Context 并继续
-		c.Set("user_id", claims.UserID)
-		c.Set("username", claims.Username)
-		c.Set("user_claims", claims)
-		c.Set("tokenString", tokenString) // 存储 Token 字符串，方便 Logout 接口使用
-		zap.L().Info("AuthRequired: Success", zap.Int("userID", claims.UserID))
-		c.Next()
+// IsAdmin 判断用户名是否属于管理员
+func IsAdmin(username string) bool {
+	if settings.Config.AppSettings == nil {
+		return false
+	}
+	for _, admin := range settings.Config.AppSettings.Admins {
+		if strings.TrimSpace(admin) == username {
+			return true
+		}
 	}
+	return false
 }
@@ -22,9 +22,15 @@ func HandleRequest(ctx context.Context, event map[string]interface{}) (string, e
 		Timeout: 10 * time.Second,
 	}
 
-	// 构造请求
+	// 构造请求，携带共享密钥 (与主服务的 cache_clear_secret 配置一致)
 	reqBody := []byte("{}")
-	resp, err := client.Post(targetURL, "application/json", bytes.NewBuffer(reqBody))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, targetURL, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return "", fmt.Errorf("build request failed: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Cache-Clear-Secret", os.Getenv("CACHE_CLEAR_SECRET"))
+	resp, err := client.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("http post failed: %w", err)
 	}
@@ -3,6 +3,8 @@ CREATE TABLE IF NOT EXISTS university (
     slug CHAR(10) PRIMARY KEY NOT NULL COMMENT '教育部学校识别码',
     short_name VARCHAR(20) NOT NULL UNIQUE COMMENT '学校唯一英文简称id',
     title VARCHAR(255) NOT NULL UNIQUE COLLATE utf8mb4_zh_0900_as_cs COMMENT '学校中文全称', -- MySQL 8.0 以上
+    pinyin VARCHAR(255) NOT NULL DEFAULT '' COMMENT '中文全称的全拼，如 shandongligongdaxue，插入/更新时维护',
+    initials VARCHAR(50) NOT NULL DEFAULT '' COMMENT '中文全称的拼音首字母，如 sdlgdx',
     vis VARCHAR(255) COMMENT '视觉形象识别系统网址',
     website VARCHAR(255) NOT NULL COMMENT '学校官网网址',
     full_name_en VARCHAR(100) NOT NULL COMMENT '英文官方全称',
@@ -15,11 +17,18 @@ CREATE TABLE IF NOT EXISTS university (
     main_vector_format VARCHAR(10) COMMENT '主要矢量文件格式，如 svg、ai',
     resource_count INT DEFAULT 0 COMMENT '当前学校资源文件总数',
     computation_id INT DEFAULT NULL COMMENT '主计算文件的id(university_resources表)',
+    palette VARCHAR(100) NOT NULL DEFAULT '' COMMENT '品牌主色及占比，取自主计算文件，如 #003F88:62,#FFFFFF:30',
+    is_deleted TINYINT NOT NULL DEFAULT 0 COMMENT '是否已停用（软删除），0=有效 1=已停用',
+    deleted_time DATETIME DEFAULT NULL COMMENT '停用时间，恢复后清空',
+    version INT NOT NULL DEFAULT 1 COMMENT '编辑版本号，每次修改高校信息时加 1，用作 If-Match 前置条件',
 
     created_time DATETIME DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
     updated_time DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
     INDEX idx_short_name(short_name),
-    INDEX idx_title(title)
+    INDEX idx_title(title),
+    INDEX idx_pinyin(pinyin),
+    INDEX idx_initials(initials),
+    INDEX idx_is_deleted(is_deleted)
 ) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci;
 
 CREATE TABLE IF NOT EXISTS resource (
@@ -36,12 +45,23 @@ CREATE TABLE IF NOT EXISTS resource (
     width INT DEFAULT 0 COMMENT '宽度(px)',
     height INT DEFAULT 0 COMMENT '高度(px)',
     used_for_edge TINYINT DEFAULT 0 COMMENT '是否为边缘计算主输入文件',
-    is_deleted TINYINT NOT NULL DEFAULT 0 COMMENT '该资源是否已经被删除',
+    is_deleted TINYINT NOT NULL DEFAULT 0 COMMENT '该资源是否已经被删除，0=有效 1=已删除 2=已被新版本替换',
+    deleted_time DATETIME DEFAULT NULL COMMENT '软删除时间，用于回收站保留期计算',
+    -- 只有有效资源参与唯一约束，已删除/已替换的记录为 NULL，互不冲突
+    active_flag TINYINT AS (IF(is_deleted = 0, 1, NULL)) STORED COMMENT '有效资源为 1，其余为 NULL',
     background_color VARCHAR(20) NOT NULL COMMENT '背景颜色，可为十六进制或CSS颜色名',
+    version INT NOT NULL DEFAULT 1 COMMENT '版本号，同一版本链内递增',
+    root_id INT DEFAULT NULL COMMENT '版本链中第一个版本的资源id，NULL 表示自身就是第一个版本',
+    prev_id INT DEFAULT NULL COMMENT '被当前版本替换的上一版本资源id',
+    phash CHAR(16) NOT NULL DEFAULT '' COMMENT '感知哈希(dHash，十六进制)，用于近似重复检测，无法计算时为空',
+    palette VARCHAR(100) NOT NULL DEFAULT '' COMMENT '主色及占比，如 #003F88:62,#FFFFFF:30，用于以图识校',
     FOREIGN KEY (short_name) REFERENCES university(short_name) ON UPDATE CASCADE,
     FOREIGN KEY (title) REFERENCES university(title) ON UPDATE CASCADE,
     -- 新增联合唯一索引
-    UNIQUE INDEX `idx_md5_size_deleted` (`md5`, `size`, `is_deleted`) COMMENT '防止同一资源重复插入'
+    UNIQUE INDEX `idx_md5_size_active` (`md5`, `size`, `active_flag`) COMMENT '防止同一资源重复插入（仅限有效资源）',
+    UNIQUE INDEX `idx_short_name_name` (`short_name`, `name`) COMMENT 'COS 路径由 short_name + name 决定，任意状态下都不能重名',
+    INDEX idx_deleted_time(is_deleted, deleted_time),
+    INDEX idx_root_id(root_id)
 ) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci;
 
 CREATE TABLE IF NOT EXISTS user (
@@ -49,4 +69,96 @@ CREATE TABLE IF NOT EXISTS user (
     status INT COMMENT '用户启用状态 1 启用 0 禁用',
     username VARCHAR(50) NOT NULL COMMENT '用户名',
     password VARCHAR(256) NOT NULL COMMENT '用户密码'
-)
\ No newline at end of file
+);
+
+CREATE TABLE IF NOT EXISTS university_rename_job (
+    id INT PRIMARY KEY AUTO_INCREMENT COMMENT '任务id',
+    slug CHAR(10) NOT NULL COMMENT '教育部学校识别码',
+    old_short_name VARCHAR(20) NOT NULL COMMENT '原英文简称(COS 旧目录)',
+    new_short_name VARCHAR(20) NOT NULL COMMENT '新英文简称(COS 新目录)',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' COMMENT '任务状态 pending/running/failed/done',
+    total INT NOT NULL DEFAULT 0 COMMENT '需要迁移的对象总数',
+    done_count INT NOT NULL DEFAULT 0 COMMENT '已完成迁移的对象数',
+    attempts INT NOT NULL DEFAULT 0 COMMENT '任务执行次数',
+    last_error TEXT COMMENT '最近一次错误信息',
+    created_time DATETIME DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    updated_time DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    INDEX idx_status(status),
+    INDEX idx_slug(slug)
+) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci;
+
+CREATE TABLE IF NOT EXISTS university_rename_object (
+    id INT PRIMARY KEY AUTO_INCREMENT COMMENT '对象进度id',
+    job_id INT NOT NULL COMMENT '所属重命名任务id',
+    old_key VARCHAR(600) NOT NULL COMMENT 'COS 旧路径',
+    new_key VARCHAR(600) NOT NULL COMMENT 'COS 新路径',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' COMMENT '对象状态 pending/copied/done',
+    attempts INT NOT NULL DEFAULT 0 COMMENT '失败重试次数',
+    last_error TEXT COMMENT '最近一次错误信息',
+    updated_time DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    UNIQUE INDEX idx_job_old_key(job_id, old_key),
+    FOREIGN KEY (job_id) REFERENCES university_rename_job(id) ON DELETE CASCADE
+) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci;
+
+CREATE TABLE IF NOT EXISTS university_alias (
+    id INT PRIMARY KEY AUTO_INCREMENT COMMENT '别名id',
+    slug CHAR(10) NOT NULL COMMENT '所属高校的教育部学校识别码',
+    alias VARCHAR(255) NOT NULL COMMENT '别名，如 北大、PKU、原校名',
+    alias_type VARCHAR(20) NOT NULL COMMENT '别名类型 former_name/abbreviation/english_alias',
+    valid_from DATE DEFAULT NULL COMMENT '别名生效日期(含)，NULL 表示不限',
+    valid_to DATE DEFAULT NULL COMMENT '别名失效日期(含)，NULL 表示不限',
+    created_time DATETIME DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    updated_time DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    UNIQUE INDEX idx_alias(alias),
+    INDEX idx_slug(slug),
+    FOREIGN KEY (slug) REFERENCES university(slug) ON DELETE CASCADE
+) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci;
+
+-- 已有库升级：资源版本链
+-- ALTER TABLE resource
+--     ADD COLUMN version INT NOT NULL DEFAULT 1 COMMENT '版本号，同一版本链内递增',
+--     ADD COLUMN root_id INT DEFAULT NULL COMMENT '版本链中第一个版本的资源id，NULL 表示自身就是第一个版本',
+--     ADD COLUMN prev_id INT DEFAULT NULL COMMENT '被当前版本替换的上一版本资源id',
+--     ADD INDEX idx_root_id(root_id);
+
+-- 已有库升级：回收站（同一文件的多份副本可以先后被删除）
+-- ALTER TABLE resource
+--     ADD COLUMN deleted_time DATETIME DEFAULT NULL COMMENT '软删除时间，用于回收站保留期计算',
+--     ADD COLUMN active_flag TINYINT AS (IF(is_deleted = 0, 1, NULL)) STORED COMMENT '有效资源为 1，其余为 NULL',
+--     DROP INDEX idx_md5_size_deleted,
+--     ADD UNIQUE INDEX idx_md5_size_active(md5, size, active_flag),
+--     ADD INDEX idx_deleted_time(is_deleted, deleted_time);
+-- UPDATE resource SET deleted_time = last_update_time WHERE is_deleted = 1 AND deleted_time IS NULL;
+
+-- 已有库升级：资源名称唯一（先确认没有同一高校下重名的记录）
+-- ALTER TABLE resource
+--     ADD UNIQUE INDEX idx_short_name_name(short_name, name);
+
+-- 已有库升级：感知哈希（存量资源通过 /admin/resource/features/backfill 补算）
+-- ALTER TABLE resource
+--     ADD COLUMN phash CHAR(16) NOT NULL DEFAULT '' COMMENT '感知哈希(dHash，十六进制)，用于近似重复检测，无法计算时为空';
+
+-- 已有库升级：主色（存量资源同样通过 /admin/resource/features/backfill 补算）
+-- ALTER TABLE resource
+--     ADD COLUMN palette VARCHAR(100) NOT NULL DEFAULT '' COMMENT '主色及占比，如 #003F88:62,#FFFFFF:30，用于以图识校';
+
+-- 已有库升级：高校品牌主色（下一次 RefreshUniversityStats 时自动填充）
+-- ALTER TABLE university
+--     ADD COLUMN palette VARCHAR(100) NOT NULL DEFAULT '' COMMENT '品牌主色及占比，取自主计算文件，如 #003F88:62,#FFFFFF:30';
+
+-- 已有库升级：拼音检索（存量高校通过 /admin/university/pinyin/rebuild 生成）
+-- ALTER TABLE university
+--     ADD COLUMN pinyin VARCHAR(255) NOT NULL DEFAULT '' COMMENT '中文全称的全拼，如 shandongligongdaxue，插入/更新时维护',
+--     ADD COLUMN initials VARCHAR(50) NOT NULL DEFAULT '' COMMENT '中文全称的拼音首字母，如 sdlgdx',
+--     ADD INDEX idx_pinyin(pinyin),
+--     ADD INDEX idx_initials(initials);
+
+-- 已有库升级：高校停用（软删除）
+-- ALTER TABLE university
+--     ADD COLUMN is_deleted TINYINT NOT NULL DEFAULT 0 COMMENT '是否已停用（软删除），0=有效 1=已停用',
+--     ADD COLUMN deleted_time DATETIME DEFAULT NULL COMMENT '停用时间，恢复后清空',
+--     ADD INDEX idx_is_deleted(is_deleted);
+
+-- 已有库升级：高校编辑版本号（乐观锁）
+-- ALTER TABLE university
+--     ADD COLUMN version INT NOT NULL DEFAULT 1 COMMENT '编辑版本号，每次修改高校信息时加 1，用作 If-Match 前置条件';
@@ -0,0 +1,33 @@
+package mysql
+
+import (
+	"go.uber.org/zap"
+	resdo "logo_api/model/resource/do"
+	resdto "logo_api/model/resource/dto"
+	do "logo_api/model/university/do"
+	dto "logo_api/model/university/dto"
+)
+
+// ExportUniversities 按 /university/list 的筛选条件查询全部高校（不分页），按 slug 升序
+func ExportUniversities(req dto.UniversityGetListReq) ([]do.University, error) {
+	var universities []do.University
+	tx := applyUniversityFilters(db.Table("university").Model(&do.University{}), req, facetNone)
+	if err := tx.Order("slug ASC").Find(&universities).Error; err != nil {
+		zap.L().Error("mysql.ExportUniversities() failed", zap.Error(err))
+		return nil, err
+	}
+	zap.L().Info("mysql.ExportUniversities() success", zap.Int("count", len(universities)))
+	return universities, nil
+}
+
+// ExportResources 按 /resource/list 的筛选条件查询全部资源元数据（不分页），按 id 升序
+func ExportResources(req resdto.ResourceGetListReq) ([]resdo.Resource, error) {
+	var resources []resdo.Resource
+	tx := applyResourceFilters(db.Table("resource").Model(&resdo.Resource{}), req)
+	if err := tx.Order("id ASC").Find(&resources).Error; err != nil {
+		zap.L().Error("mysql.ExportResources() failed", zap.Error(err))
+		return nil, err
+	}
+	zap.L().Info("mysql.ExportResources() success", zap.Int("count", len(resources)))
+	return resources, nil
+}
@@ -0,0 +1,157 @@
+package mysql
+
+import (
+	"errors"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"logo_api/model"
+	"logo_api/model/university/do"
+)
+
+// ErrRenameJobInProgress 该高校还有未完成的重命名任务
+var ErrRenameJobInProgress = errors.New("university has an unfinished rename job")
+
+// createRenameJob 在 short_name 更新的同一个事务中创建迁移任务，保证数据库提交后任务一定存在（必须传入事务中的 tx）
+func createRenameJob(tx *gorm.DB, slug, oldShortName, newShortName string) (int, error) {
+	unfinished, err := hasUnfinishedRenameJob(tx, slug)
+	if err != nil {
+		zap.L().Error("createRenameJob() count failed", zap.String("slug", slug), zap.Error(err))
+		return 0, err
+	}
+	if unfinished {
+		// 上一次重命名的对象还没迁移完，再次重命名会导致旧目录中的对象被遗漏
+		zap.L().Error("createRenameJob() failed: unfinished rename job exists", zap.String("slug", slug))
+		return 0, ErrRenameJobInProgress
+	}
+	job := do.RenameJob{
+		Slug:         slug,
+		OldShortName: oldShortName,
+		NewShortName: newShortName,
+		Status:       model.RenameJobPending,
+	}
+	if err = tx.Table("university_rename_job").Omit("CreatedTime", "UpdatedTime").Create(&job).Error; err != nil {
+		zap.L().Error("createRenameJob() failed", zap.String("slug", slug), zap.Error(err))
+		return 0, err
+	}
+	zap.L().Info("createRenameJob() success", zap.Int("id", job.ID), zap.String("from", oldShortName), zap.String("to", newShortName))
+	return job.ID, nil
+}
+
+// hasUnfinishedRenameJob 该高校是否还有未完成的重命名任务
+func hasUnfinishedRenameJob(tx *gorm.DB, slug string) (bool, error) {
+	var count int64
+	err := tx.Table("university_rename_job").
+		Where("slug = ? AND status <> ?", slug, model.RenameJobDone).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// GetRenameJob 根据 id 查询重命名任务
+func GetRenameJob(id int) (do.RenameJob, error) {
+	var job do.RenameJob
+	if err := db.Table("university_rename_job").Where("id = ?", id).First(&job).Error; err != nil {
+		zap.L().Error("GetRenameJob() failed", zap.Int("id", id), zap.Error(err))
+		return do.RenameJob{}, err
+	}
+	return job, nil
+}
+
+// GetRenameJobList 查询重命名任务列表，status 为空表示全部
+func GetRenameJobList(status string) ([]do.RenameJob, error) {
+	var jobs []do.RenameJob
+	tx := db.Table("university_rename_job")
+	if status != "" {
+		tx = tx.Where("status = ?", status)
+	}
+	if err := tx.Order("id DESC").Find(&jobs).Error; err != nil {
+		zap.L().Error("GetRenameJobList() failed", zap.String("status", status), zap.Error(err))
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// GetUnfinishedRenameJobs 查询所有未完成的重命名任务（用于重启后恢复）
+func GetUnfinishedRenameJobs() ([]do.RenameJob, error) {
+	var jobs []do.RenameJob
+	if err := db.Table("university_rename_job").
+		Where("status <> ?", model.RenameJobDone).
+		Order("id ASC").Find(&jobs).Error; err != nil {
+		zap.L().Error("GetUnfinishedRenameJobs() failed", zap.Error(err))
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// GetUnfinishedRenameJobByShortName 查询以 newShortName 为目标、尚未完成的重命名任务
+func GetUnfinishedRenameJobByShortName(newShortName string) (do.RenameJob, error) {
+	var job do.RenameJob
+	err := db.Table("university_rename_job").
+		Where("new_short_name = ? AND status <> ?", newShortName, model.RenameJobDone).
+		Order("id DESC").First(&job).Error
+	return job, err
+}
+
+// UpdateRenameJob 更新重命名任务的状态字段
+func UpdateRenameJob(id int, updates map[string]interface{}) error {
+	if err := db.Table("university_rename_job").Where("id = ?", id).Updates(updates).Error; err != nil {
+		zap.L().Error("UpdateRenameJob() failed", zap.Int("id", id), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// InitRenameObjects 写入任务需要迁移的对象列表（重复执行时忽略已存在的对象），并把任务切换为 running
+func InitRenameObjects(jobID int, objects []do.RenameObject) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if len(objects) > 0 {
+			if err := tx.Table("university_rename_object").
+				Clauses(clause.OnConflict{DoNothing: true}).
+				Omit("UpdatedTime").
+				CreateInBatches(objects, 500).Error; err != nil {
+				zap.L().Error("InitRenameObjects() insert failed", zap.Int("jobID", jobID), zap.Error(err))
+				return err
+			}
+		}
+		var total int64
+		if err := tx.Table("university_rename_object").Where("job_id = ?", jobID).Count(&total).Error; err != nil {
+			return err
+		}
+		return tx.Table("university_rename_job").Where("id = ?", jobID).Updates(map[string]interface{}{
+			"status": model.RenameJobRunning,
+			"total":  total,
+		}).Error
+	})
+}
+
+// GetRenameObjects 查询任务下的对象进度，onlyUnfinished 为 true 时只返回未完成的对象
+func GetRenameObjects(jobID int, onlyUnfinished bool) ([]do.RenameObject, error) {
+	var objects []do.RenameObject
+	tx := db.Table("university_rename_object").Where("job_id = ?", jobID)
+	if onlyUnfinished {
+		tx = tx.Where("status <> ?", model.RenameObjectDone)
+	}
+	if err := tx.Order("id ASC").Find(&objects).Error; err != nil {
+		zap.L().Error("GetRenameObjects() failed", zap.Int("jobID", jobID), zap.Error(err))
+		return nil, err
+	}
+	return objects, nil
+}
+
+// UpdateRenameObject 更新单个对象的迁移进度
+func UpdateRenameObject(id int, updates map[string]interface{}) error {
+	if err := db.Table("university_rename_object").Where("id = ?", id).Updates(updates).Error; err != nil {
+		zap.L().Error("UpdateRenameObject() failed", zap.Int("id", id), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// CountDoneRenameObjects 统计任务中已完成迁移的对象数
+func CountDoneRenameObjects(jobID int) (int64, error) {
+	var count int64
+	err := db.Table("university_rename_object").
+		Where("job_id = ? AND status = ?", jobID, model.RenameObjectDone).
+		Count(&count).Error
+	return count, err
+}
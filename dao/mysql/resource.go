@@ -8,7 +8,6 @@ import (
 	"logo_api/model/resource/do"
 	"logo_api/model/resource/dto"
 	"logo_api/settings"
-	"strings"
 
 	"gorm.io/gorm"
 )
@@ -20,13 +19,17 @@ import (
 // 4. Create() 替换 db.NamedExec() 批量插入。
 // 5. Save() 或 Updates() 替换 UPDATE 语句。
 
+// activeUniversityShortNames 有效（未停用）高校的 short_name 子查询，已停用高校的资源不再对外提供
+const activeUniversityShortNames = "SELECT short_name FROM university WHERE is_deleted = 0"
+
 // QueryFromNameAndSvg 在后缀是Svg的情况下进行查询
 func QueryFromNameAndSvg(preName string, ext string) (settings.UniversityResources, error) {
 	var resource settings.UniversityResources
 
 	// GORM API 要点: 复合 WHERE 条件查询单条记录。
 	// 使用 First() 查找，GORM 自动添加 LIMIT 1
-	err := db.Table("resource").Where("(short_name = ? OR title = ?) AND type = ? AND is_deleted = ?", preName, preName, ext, 0).First(&resource).Error
+	err := db.Table("resource").Where("(short_name = ? OR title = ?) AND type = ? AND is_deleted = ?", preName, preName, ext, 0).
+		Where("short_name IN (" + activeUniversityShortNames + ")").First(&resource).Error
 
 	// 查询出错
 	if err != nil {
@@ -49,7 +52,8 @@ func QueryFromNameAndBitmapInfo(preName string, ext string, size int, width int,
 	// GORM API 要点: 复杂的 WHERE/OR 组合查询
 	// 使用 Where() 包含所有的 AND 条件
 	tx := db.Table("resource").Where("(short_name = ? OR title = ?) AND type = ? AND is_deleted = 0 AND background_color = ? AND is_deleted = ?",
-		preName, preName, ext, bgColor, 0)
+		preName, preName, ext, bgColor, 0).
+		Where("short_name IN (" + activeUniversityShortNames + ")")
 
 	// 使用 Or() 组合宽度/高度的 OR 逻辑
 	tx = tx.Where("(width = ? AND height = ?) OR (width = ? AND height = ? AND is_deleted = ?)",
@@ -65,14 +69,17 @@ func QueryFromNameAndBitmapInfo(preName string, ext string, size int, width int,
 
 			// 虽然直接查没查到，但是还有机会查到 svg 资源，继续去查 svg 资源
 			// GORM 第二次查询: 查找用于 edge 的 SVG 资源
-			err = db.Table("resource").Where("(short_name = ? OR title = ?) AND used_for_edge = ?", preName, preName, 1).First(&resource).Error
+			// 只取有效版本，避免命中已删除或已被替换的历史版本
+			err = db.Table("resource").Where("(short_name = ? OR title = ?) AND used_for_edge = ? AND is_deleted = ?", preName, preName, 1, model.ResourceIsActive).
+				Where("short_name IN (" + activeUniversityShortNames + ")").
+				Order("id DESC").First(&resource).Error
 
 			// 第二次查询出错
 			if err != nil {
 				// svg 资源也没查到
 				if errors.Is(err, gorm.ErrRecordNotFound) {
 					zap.L().Error("svg resource was not founded as well, this bitmap resource could not be found")
-					return settings.UniversityResources{}, errors.New("bitmap and svg resource not found") // 返回一个更明确的错误
+					return settings.UniversityResources{}, fmt.Errorf("bitmap and svg resource not found: %w", gorm.ErrRecordNotFound) // 返回一个更明确的错误
 				}
 				// 其他错误
 				zap.L().Error("db.First() err:", zap.Error(err))
@@ -93,11 +100,19 @@ func QueryFromNameAndBitmapInfo(preName string, ext string, size int, width int,
 
 // InsertResources 对 resource 表进行批量插入
 func InsertResources(resources []*do.Resource) error {
+	_, err := InsertResourcesWithReport(resources)
+	return err
+}
+
+// InsertResourcesWithReport 在一个事务中批量插入资源，返回因重复而被跳过的资源（与 resources 中的指针相同）
+// 插入成功的资源会被回填 ID
+func InsertResourcesWithReport(resources []*do.Resource) ([]*do.Resource, error) {
 	// GORM API 要点: 批量插入。
 	// 对切片使用 db.Create()，GORM 自动处理字段映射和批量 INSERT
+	var duplicates []*do.Resource
 	if len(resources) == 0 {
 		zap.L().Warn("mysql.InsertResources() Warn: resources is empty")
-		return nil
+		return duplicates, nil
 	}
 	// 1. 提取所有待插入资源的 MD5 用于初步筛选查询
 	md5s := make([]string, 0, len(resources))
@@ -108,7 +123,7 @@ func InsertResources(resources []*do.Resource) error {
 	}
 
 	// 开启事务
-	return db.Transaction(func(tx *gorm.DB) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
 		// 2. 查重：从数据库找出 MD5 + short_name 匹配且未删除的记录
 		var existing []struct {
 			Md5       string
@@ -138,6 +153,7 @@ func InsertResources(resources []*do.Resource) error {
 				toInsert = append(toInsert, resource)
 				shortNames[resource.ShortName] = true
 			} else {
+				duplicates = append(duplicates, resource)
 				zap.L().Info("mysql.InsertResources() skip duplicate",
 					zap.String("title", resource.Title),
 					zap.String("md5", resource.Md5))
@@ -166,6 +182,10 @@ func InsertResources(resources []*do.Resource) error {
 		zap.L().Info("mysql.InsertResources() success", zap.Int("count", len(resources)))
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+	return duplicates, nil
 }
 
 func GetAllUniversityResources() ([]settings.UniversityResources, error) {
@@ -201,50 +221,6 @@ func GetResourceByName(name string) (do.Resource, error) {
 	return result, nil
 }
 
-func GetResourceList(req dto.ResourceGetListReq) ([]do.Resource, error) {
-	var (
-		resourceDoList []do.Resource
-	)
-
-	// 1. 初始化查询实例
-	query := db.Table("resource").Where("is_deleted = ?", model.ResourceIsActive)
-	// 2. 模糊查询（处理通配符）
-	if req.Name != "" {
-		nameParam := "%" + req.Name + "%"
-		query = query.Where("(title LIKE ? OR short_name LIKE ?)", nameParam, nameParam)
-	}
-
-	// 3. 排序处理（防止 SQL 语法错误）
-	if req.SortBy != "" {
-		dbSortByMap := map[string]string{ // 请求体参数映射成db字段（把大驼峰映射成下划线）
-			"id":             "id",
-			"name":           "name",
-			"size":           "size",
-			"type":           "type",
-			"lastUpdateTime": "last_update_time", // 核心转换
-		}
-		dbSortBy := dbSortByMap[req.SortBy] // 把大驼峰映射成下划线
-		sortOrder := "ASC"
-		if strings.ToUpper(req.SortOrder) == "DESC" {
-			sortOrder = "DESC"
-		}
-		query = query.Order(fmt.Sprintf("%s %s", dbSortBy, sortOrder))
-	} else {
-		// 默认排序，防止没有 ORDER BY
-		query = query.Order("id DESC")
-	}
-
-	// 4. 执行查询
-	tx := query.Find(&resourceDoList)
-	if tx.Error != nil {
-		zap.L().Error("GetResourceList() failed", zap.Error(tx.Error))
-		return nil, tx.Error
-	}
-	zap.L().Info("GetResourceList() success", zap.Int("count", len(resourceDoList)))
-
-	return resourceDoList, tx.Error
-}
-
 func GetResources(names []string) ([]do.Resource, error) {
 	if len(names) == 0 {
 		return []do.Resource{}, nil
@@ -287,7 +263,10 @@ func DelResource(req dto.ResourceDelReq) error {
 	}
 	return db.Transaction(func(tx *gorm.DB) error {
 		// 1. 将删除的资源设置为 is_deleted = 1
-		result := tx.Table("resource").Where("id = ?", resource.ID).Updates(map[string]interface{}{"is_deleted": model.ResourceIsDeleted})
+		result := tx.Table("resource").Where("id = ?", resource.ID).Updates(map[string]interface{}{
+			"is_deleted":   model.ResourceIsDeleted,
+			"deleted_time": gorm.Expr("NOW()"),
+		})
 		if result.Error != nil {
 			zap.L().Error("mysql.DelResource() failed", zap.Any("req", req), zap.Error(result.Error))
 			return result.Error
@@ -316,7 +295,10 @@ func RecoverResource(req dto.ResourceRecoverReq) error {
 		return fmt.Errorf("resource name is empty for req: %v", req)
 	}
 	return db.Transaction(func(tx *gorm.DB) error {
-		result := tx.Table("resource").Where("id = ?", resource.ID).Updates(map[string]interface{}{"is_deleted": model.ResourceIsActive})
+		result := tx.Table("resource").Where("id = ?", resource.ID).Updates(map[string]interface{}{
+			"is_deleted":   model.ResourceIsActive,
+			"deleted_time": nil,
+		})
 		if result.Error != nil {
 			zap.L().Error("mysql.RecoverResource() failed", zap.Any("req", req), zap.Error(result.Error))
 			return result.Error
@@ -368,6 +350,84 @@ func RefreshUniversityStats(tx *gorm.DB, shortName string) error {
 		updateData["computation_id"] = mainRes.ID
 		updateData["main_vector_format"] = mainRes.Type
 	}
+
+	// 4. 主计算文件发生变化（或主色尚未取得）时重新取主色
+	var current struct {
+		ComputationID *int
+		Palette       string
+	}
+	if err := tx.Table("university").Select("computation_id, palette").
+		Where("short_name = ?", shortName).Scan(&current).Error; err != nil {
+		zap.L().Error("refreshUniversityStats() failed", zap.String("short_name", shortName), zap.Error(err))
+		return err
+	}
+	currentID := 0
+	if current.ComputationID != nil {
+		currentID = *current.ComputationID
+	}
+	if currentID != mainRes.ID || current.Palette == "" {
+		palette, err := universityPalette(tx, shortName, mainRes)
+		if err != nil {
+			zap.L().Error("refreshUniversityStats() failed", zap.String("short_name", shortName), zap.Error(err))
+			return err
+		}
+		updateData["palette"] = palette
+	}
 	// 对受影响的高校进行更新：
 	return tx.Table("university").Where("short_name = ?", shortName).Updates(updateData).Error
 }
+
+// universityPalette 高校主色取自主计算文件；主计算文件是 ai/eps 等无法提取主色的格式时，
+// 退回到该校已提取主色的其他有效资源（SVG 优先，其次面积最大的位图）
+func universityPalette(tx *gorm.DB, shortName string, mainRes do.Resource) (string, error) {
+	if mainRes.Palette != "" {
+		return mainRes.Palette, nil
+	}
+	var fallback do.Resource
+	if err := tx.Table("resource").Select("id, palette").
+		Where("short_name = ? AND is_deleted = ? AND palette <> ''", shortName, model.ResourceIsActive).
+		Order("type = 'svg' DESC, width * height DESC, id DESC").Limit(1).Find(&fallback).Error; err != nil {
+		return "", err
+	}
+	return fallback.Palette, nil
+}
+
+// GetAllResourcesIncludeDeleted 查询全部资源（包括已软删除的资源，它们的 COS 对象仍然保留）
+func GetAllResourcesIncludeDeleted() ([]do.Resource, error) {
+	var resources []do.Resource
+	if err := db.Table("resource").Order("id ASC").Find(&resources).Error; err != nil {
+		zap.L().Error("GetAllResourcesIncludeDeleted() failed", zap.Error(err))
+		return nil, err
+	}
+	zap.L().Info("GetAllResourcesIncludeDeleted() success", zap.Int("count", len(resources)))
+	return resources, nil
+}
+
+// UpdateResourceFileInfo 以 COS 中的实际文件为准，修正资源的 md5 和 size
+func UpdateResourceFileInfo(id int, md5 string, size int) error {
+	result := db.Table("resource").Where("id = ?", id).Updates(map[string]interface{}{"md5": md5, "size": size})
+	if result.Error != nil {
+		zap.L().Error("mysql.UpdateResourceFileInfo() failed", zap.Int("id", id), zap.Error(result.Error))
+		return result.Error
+	}
+	zap.L().Info("mysql.UpdateResourceFileInfo() success", zap.Int("id", id), zap.String("md5", md5), zap.Int("size", size))
+	return nil
+}
+
+// MarkResourceDeleted 将文件已丢失的资源标记为 is_deleted = 1，并同步更新 university 表的数据
+func MarkResourceDeleted(id int, shortName string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Table("resource").Where("id = ?", id).Updates(map[string]interface{}{
+			"is_deleted":   model.ResourceIsDeleted,
+			"deleted_time": gorm.Expr("NOW()"),
+		}).Error; err != nil {
+			zap.L().Error("mysql.MarkResourceDeleted() failed", zap.Int("id", id), zap.Error(err))
+			return err
+		}
+		if err := RefreshUniversityStats(tx, shortName); err != nil {
+			zap.L().Error("mysql.RefreshUniversityStats() failed", zap.String("short_name", shortName), zap.Error(err))
+			return err
+		}
+		return nil
+	})
+}
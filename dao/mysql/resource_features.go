@@ -0,0 +1,47 @@
+package mysql
+
+import (
+	"go.uber.org/zap"
+	"logo_api/model"
+	"logo_api/model/resource/do"
+)
+
+// GetResourceHashes 查询有效且已计算感知哈希的资源，shortName 为空时查询全部高校
+func GetResourceHashes(shortName string) ([]do.Resource, error) {
+	var resources []do.Resource
+	query := db.Table("resource").
+		Select("id, title, short_name, name, type, size, width, height, root_id, phash, palette").
+		Where("is_deleted = ? AND phash <> ''", model.ResourceIsActive)
+	if shortName != "" {
+		query = query.Where("short_name = ?", shortName)
+	}
+	if err := query.Order("id").Find(&resources).Error; err != nil {
+		zap.L().Error("mysql.GetResourceHashes() failed", zap.String("shortName", shortName), zap.Error(err))
+		return nil, err
+	}
+	return resources, nil
+}
+
+// GetResourcesMissingFeatures 按 id 顺序查询还没有感知哈希或主色的位图和 SVG 资源，用于补算存量数据
+func GetResourcesMissingFeatures(afterID, limit int, types []string) ([]do.Resource, error) {
+	var resources []do.Resource
+	if err := db.Table("resource").
+		Where("id > ? AND (phash = '' OR palette = '') AND type IN ?", afterID, types).
+		Order("id").
+		Limit(limit).
+		Find(&resources).Error; err != nil {
+		zap.L().Error("mysql.GetResourcesMissingFeatures() failed", zap.Error(err))
+		return nil, err
+	}
+	return resources, nil
+}
+
+// UpdateResourceFeatures 更新资源的感知哈希和主色
+func UpdateResourceFeatures(id int, pHash, palette string) error {
+	if err := db.Table("resource").Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{"phash": pHash, "palette": palette}).Error; err != nil {
+		zap.L().Error("mysql.UpdateResourceFeatures() failed", zap.Int("id", id), zap.Error(err))
+		return err
+	}
+	return nil
+}
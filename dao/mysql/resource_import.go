@@ -0,0 +1,60 @@
+package mysql
+
+import (
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"logo_api/model"
+	"logo_api/model/resource/do"
+)
+
+// GetResourcesByIDs 根据 id 批量查询资源（任意状态），不存在的 id 不在结果中
+func GetResourcesByIDs(ids []int) ([]do.Resource, error) {
+	var resources []do.Resource
+	if len(ids) == 0 {
+		return resources, nil
+	}
+	if err := db.Table("resource").Where("id IN ?", ids).Find(&resources).Error; err != nil {
+		zap.L().Error("mysql.GetResourcesByIDs() failed", zap.Int("count", len(ids)), zap.Error(err))
+		return nil, err
+	}
+	return resources, nil
+}
+
+// UpdateResourcesMetadata 在一个事务中按 updates（列名 -> 新值）修改 current 中对应资源的元数据，并刷新所属高校的统计
+// 每行写入前加锁重读，资源已被删除或要修改的列已不是 current 中读到的值时返回 ErrResourceVersionChanged 并回滚整批；
+// failed 为出错行在 current 中的下标
+func UpdateResourcesMetadata(current []do.Resource, updates []map[string]interface{}) (failed int, err error) {
+	failed = -1
+	err = db.Transaction(func(tx *gorm.DB) error {
+		shortNames := make(map[string]bool)
+		for i, r := range current {
+			failed = i
+			var locked do.Resource
+			if err := tx.Table("resource").Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("id = ?", r.ID).First(&locked).Error; err != nil {
+				return err
+			}
+			if locked.IsDeleted != model.ResourceIsActive || locked.UsedForEdge != r.UsedForEdge || locked.BackgroundColor != r.BackgroundColor {
+				return ErrResourceVersionChanged
+			}
+			if err := tx.Table("resource").Where("id = ?", r.ID).Updates(updates[i]).Error; err != nil {
+				return err
+			}
+			shortNames[r.ShortName] = true
+		}
+		failed = -1
+		for shortName := range shortNames {
+			if err := RefreshUniversityStats(tx, shortName); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		zap.L().Error("mysql.UpdateResourcesMetadata() failed", zap.Int("failed", failed), zap.Error(err))
+		return failed, err
+	}
+	zap.L().Info("mysql.UpdateResourcesMetadata() success", zap.Int("count", len(current)))
+	return -1, nil
+}
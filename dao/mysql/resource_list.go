@@ -0,0 +1,190 @@
+package mysql
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"logo_api/model"
+	"logo_api/model/resource/do"
+	"logo_api/model/resource/dto"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCursor 游标无法解析，或与本次请求的排序方式不一致
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// resourceSortColumns 请求体中的排序字段映射成 db 字段（把小驼峰映射成下划线）
+var resourceSortColumns = map[string]string{
+	"id":             "id",
+	"name":           "name",
+	"size":           "size",
+	"type":           "type",
+	"lastUpdateTime": "last_update_time",
+}
+
+// resourceListCursor 游标内容：排序方式和上一页最后一条记录的排序值、id
+// 排序值统一存为字符串，时间使用 RFC3339Nano
+type resourceListCursor struct {
+	SortBy    string `json:"s"`
+	SortOrder string `json:"o"`
+	Value     string `json:"v"`
+	ID        int    `json:"i"`
+}
+
+// resourceListSort 解析排序方式，未指定时按 id 倒序；指定了字段但没指定方向时为升序
+func resourceListSort(req dto.ResourceGetListReq) (sortBy, sortOrder string) {
+	if _, ok := resourceSortColumns[req.SortBy]; !ok {
+		return "id", "DESC"
+	}
+	if strings.ToUpper(req.SortOrder) == "DESC" {
+		return req.SortBy, "DESC"
+	}
+	return req.SortBy, "ASC"
+}
+
+// cursorValue 取记录在排序字段上的值
+func cursorValue(r do.Resource, sortBy string) string {
+	switch sortBy {
+	case "name":
+		return r.Name
+	case "size":
+		return fmt.Sprint(r.Size)
+	case "type":
+		return r.Type
+	case "lastUpdateTime":
+		if r.LastUpdateTime != nil {
+			return r.LastUpdateTime.Format(time.RFC3339Nano)
+		}
+		return ""
+	default:
+		return fmt.Sprint(r.ID)
+	}
+}
+
+// encodeResourceCursor 生成指向 r 之后的游标
+func encodeResourceCursor(r do.Resource, sortBy, sortOrder string) string {
+	b, _ := json.Marshal(resourceListCursor{SortBy: sortBy, SortOrder: sortOrder, Value: cursorValue(r, sortBy), ID: r.ID})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeResourceCursor 解析游标并转换为排序字段对应类型的值
+func decodeResourceCursor(cursor, sortBy, sortOrder string) (value interface{}, id int, err error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, 0, ErrInvalidCursor
+	}
+	var c resourceListCursor
+	if err = json.Unmarshal(b, &c); err != nil || c.SortBy != sortBy || c.SortOrder != sortOrder {
+		return nil, 0, ErrInvalidCursor
+	}
+	switch sortBy {
+	case "id", "size":
+		var n int
+		if _, err = fmt.Sscan(c.Value, &n); err != nil {
+			return nil, 0, ErrInvalidCursor
+		}
+		value = n
+	case "lastUpdateTime":
+		t, err := time.Parse(time.RFC3339Nano, c.Value)
+		if err != nil {
+			return nil, 0, ErrInvalidCursor
+		}
+		value = t
+	default:
+		value = c.Value
+	}
+	return value, c.ID, nil
+}
+
+// applyResourceFilters 资源列表的筛选条件；未指定 isDeleted 时只查有效资源
+func applyResourceFilters(tx *gorm.DB, req dto.ResourceGetListReq) *gorm.DB {
+	isDeleted := model.ResourceIsActive
+	if req.IsDeleted != nil {
+		isDeleted = *req.IsDeleted
+	}
+	tx = tx.Where("is_deleted = ?", isDeleted)
+	if req.Name != "" {
+		nameParam := "%" + req.Name + "%"
+		tx = tx.Where("(title LIKE ? OR short_name LIKE ?)", nameParam, nameParam)
+	}
+	if len(req.Types) > 0 {
+		tx = tx.Where("type IN ?", req.Types)
+	}
+	if req.IsVector != nil {
+		tx = tx.Where("is_vector = ?", *req.IsVector)
+	}
+	if req.UsedForEdge != nil {
+		tx = tx.Where("used_for_edge = ?", *req.UsedForEdge)
+	}
+	if req.MinSize != nil {
+		tx = tx.Where("size >= ?", *req.MinSize)
+	}
+	if req.MaxSize != nil {
+		tx = tx.Where("size <= ?", *req.MaxSize)
+	}
+	if req.BackgroundColor != nil {
+		tx = tx.Where("background_color = ?", *req.BackgroundColor)
+	}
+	return tx
+}
+
+// GetResourceList 按条件分页查询资源，totalCount 为符合筛选条件的总数（与分页方式无关）
+// req.Cursor 为空时按 page/pageSize 偏移分页，否则从游标之后取 pageSize 条；
+// 后面还有数据时 nextCursor 指向本页最后一条，否则为空。游标无效时返回 ErrInvalidCursor
+func GetResourceList(req dto.ResourceGetListReq) (resources []do.Resource, totalCount int64, nextCursor string, err error) {
+	sortBy, sortOrder := resourceListSort(req)
+	column := resourceSortColumns[sortBy]
+	var (
+		cursorVal interface{}
+		lastID    int
+	)
+	if req.Cursor != "" {
+		if cursorVal, lastID, err = decodeResourceCursor(req.Cursor, sortBy, sortOrder); err != nil {
+			return nil, 0, "", err
+		}
+	}
+
+	// 1. 统计总数，不受游标影响
+	if err = applyResourceFilters(db.Table("resource"), req).Count(&totalCount).Error; err != nil {
+		zap.L().Error("mysql.GetResourceList() count failed", zap.Any("req", req), zap.Error(err))
+		return nil, 0, "", err
+	}
+
+	// 2. 排序：id 作为第二排序字段，保证顺序稳定、游标位置唯一
+	tx := applyResourceFilters(db.Table("resource"), req).
+		Order(fmt.Sprintf("%s %s", column, sortOrder))
+	if column != "id" {
+		tx = tx.Order("id " + sortOrder)
+	}
+
+	// 3. 分页：游标方式取排序值 (column, id) 严格位于游标之后的记录
+	if req.Cursor != "" {
+		op := ">"
+		if sortOrder == "DESC" {
+			op = "<"
+		}
+		if column == "id" {
+			tx = tx.Where("id "+op+" ?", lastID)
+		} else {
+			tx = tx.Where(fmt.Sprintf("(%s %s ? OR (%s = ? AND id %s ?))", column, op, column, op), cursorVal, cursorVal, lastID)
+		}
+	} else {
+		tx = tx.Offset((req.Page - 1) * req.PageSize)
+	}
+
+	// 4. 多取一条判断是否还有下一页
+	if err = tx.Limit(req.PageSize + 1).Find(&resources).Error; err != nil {
+		zap.L().Error("mysql.GetResourceList() failed", zap.Any("req", req), zap.Error(err))
+		return nil, 0, "", err
+	}
+	if len(resources) > req.PageSize {
+		resources = resources[:req.PageSize]
+		nextCursor = encodeResourceCursor(resources[len(resources)-1], sortBy, sortOrder)
+	}
+	zap.L().Info("mysql.GetResourceList() success", zap.Int("count", len(resources)), zap.Int64("totalCount", totalCount))
+	return resources, totalCount, nextCursor, nil
+}
@@ -0,0 +1,145 @@
+package mysql
+
+import (
+	"errors"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"logo_api/model"
+	"logo_api/model/resource/do"
+)
+
+// ErrResourceVersionChanged 版本链在读取之后被其他请求修改（已被替换、删除或回滚）
+var ErrResourceVersionChanged = errors.New("resource version has changed, reload and retry")
+
+// GetResourceByID 根据 id 查询资源（任意状态）
+func GetResourceByID(id int) (do.Resource, error) {
+	var resource do.Resource
+	if err := db.Table("resource").Where("id = ?", id).First(&resource).Error; err != nil {
+		zap.L().Error("mysql.GetResourceByID() failed", zap.Int("id", id), zap.Error(err))
+		return do.Resource{}, err
+	}
+	return resource, nil
+}
+
+// GetResourceHistory 查询版本链中的全部版本，按版本号从新到旧排列
+func GetResourceHistory(chainID int) ([]do.Resource, error) {
+	var resources []do.Resource
+	if err := db.Table("resource").
+		Where("id = ? OR root_id = ?", chainID, chainID).
+		Order("version DESC, id DESC").
+		Find(&resources).Error; err != nil {
+		zap.L().Error("mysql.GetResourceHistory() failed", zap.Int("chainID", chainID), zap.Error(err))
+		return nil, err
+	}
+	return resources, nil
+}
+
+// ReplaceResource 用 newResource 替换当前版本 current：current 标记为已替换，newResource 作为新版本插入版本链
+func ReplaceResource(current do.Resource, newResource *do.Resource) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		// 1. 锁住当前版本，确认它仍然是有效版本
+		var locked do.Resource
+		if err := tx.Table("resource").Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", current.ID).First(&locked).Error; err != nil {
+			return err
+		}
+		if locked.IsDeleted != model.ResourceIsActive {
+			return ErrResourceVersionChanged
+		}
+		chainID := locked.ChainID()
+		var maxVersion int
+		if err := tx.Table("resource").
+			Where("id = ? OR root_id = ?", chainID, chainID).
+			Select("COALESCE(MAX(version), 1)").
+			Scan(&maxVersion).Error; err != nil {
+			return err
+		}
+
+		// 2. 旧版本标记为已替换
+		if err := tx.Table("resource").Where("id = ?", locked.ID).
+			Updates(map[string]interface{}{"is_deleted": model.ResourceIsReplaced}).Error; err != nil {
+			zap.L().Error("mysql.ReplaceResource() mark replaced failed", zap.Int("id", locked.ID), zap.Error(err))
+			return err
+		}
+
+		// 3. 插入新版本
+		prevID := locked.ID
+		newResource.Version = maxVersion + 1
+		newResource.RootID = &chainID
+		newResource.PrevID = &prevID
+		newResource.IsDeleted = model.ResourceIsActive
+		if err := tx.Table("resource").Omit("last_update_time").Create(newResource).Error; err != nil {
+			zap.L().Error("mysql.ReplaceResource() insert failed", zap.Any("resource", newResource), zap.Error(err))
+			return err
+		}
+
+		// 4. 刷新高校统计（主计算文件可能随之变化）
+		if err := RefreshUniversityStats(tx, locked.ShortName); err != nil {
+			zap.L().Error("mysql.RefreshUniversityStats() failed", zap.String("short_name", locked.ShortName), zap.Error(err))
+			return err
+		}
+		zap.L().Info("mysql.ReplaceResource() success",
+			zap.Int("from", locked.ID), zap.Int("to", newResource.ID), zap.Int("version", newResource.Version))
+		return nil
+	})
+}
+
+// RollbackResource 把版本链的有效版本切换为 target：原有效版本标记为已替换，target 恢复为有效，并刷新 computation_id
+func RollbackResource(target do.Resource) (do.Resource, error) {
+	var previous do.Resource
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var locked do.Resource
+		if err := tx.Table("resource").Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", target.ID).First(&locked).Error; err != nil {
+			return err
+		}
+		if locked.IsDeleted != model.ResourceIsReplaced {
+			return ErrResourceVersionChanged
+		}
+		chainID := locked.ChainID()
+
+		// 1. 当前有效版本（若整条链都已被删除，则没有有效版本）
+		if err := tx.Table("resource").Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("(id = ? OR root_id = ?) AND is_deleted = ?", chainID, chainID, model.ResourceIsActive).
+			Limit(1).Find(&previous).Error; err != nil {
+			return err
+		}
+		if previous.ID > 0 {
+			if err := tx.Table("resource").Where("id = ?", previous.ID).
+				Updates(map[string]interface{}{"is_deleted": model.ResourceIsReplaced}).Error; err != nil {
+				zap.L().Error("mysql.RollbackResource() mark replaced failed", zap.Int("id", previous.ID), zap.Error(err))
+				return err
+			}
+		}
+
+		// 2. 恢复目标版本
+		if err := tx.Table("resource").Where("id = ?", locked.ID).
+			Updates(map[string]interface{}{"is_deleted": model.ResourceIsActive}).Error; err != nil {
+			zap.L().Error("mysql.RollbackResource() restore failed", zap.Int("id", locked.ID), zap.Error(err))
+			return err
+		}
+
+		// 3. 刷新高校统计，computation_id 随有效版本切换
+		if err := RefreshUniversityStats(tx, locked.ShortName); err != nil {
+			zap.L().Error("mysql.RefreshUniversityStats() failed", zap.String("short_name", locked.ShortName), zap.Error(err))
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return do.Resource{}, err
+	}
+	zap.L().Info("mysql.RollbackResource() success", zap.Int("from", previous.ID), zap.Int("to", target.ID))
+	return previous, nil
+}
+
+// ResourceNameExists 判断同一高校下是否已有同名资源（任意状态，COS 路径由 short_name + name 决定）
+func ResourceNameExists(shortName, name string) (bool, error) {
+	var count int64
+	if err := db.Table("resource").Where("short_name = ? AND name = ?", shortName, name).Count(&count).Error; err != nil {
+		zap.L().Error("mysql.ResourceNameExists() failed", zap.String("shortName", shortName), zap.String("name", name), zap.Error(err))
+		return false, err
+	}
+	return count > 0, nil
+}
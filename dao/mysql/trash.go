@@ -0,0 +1,79 @@
+package mysql
+
+import (
+	"errors"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"logo_api/model"
+	"logo_api/model/resource/do"
+	"time"
+)
+
+// ErrResourceNotInTrash 资源不在回收站中（不存在、未删除或已被恢复）
+var ErrResourceNotInTrash = errors.New("resource is not in trash")
+
+// trashDeletedTimeExpr 历史数据没有 deleted_time，用 last_update_time 近似删除时间
+const trashDeletedTimeExpr = "COALESCE(deleted_time, last_update_time)"
+
+// GetTrashList 查询回收站中的资源（is_deleted = 1），按删除时间从新到旧排列，shortName 为空表示全部
+func GetTrashList(shortName string) ([]do.Resource, error) {
+	var resources []do.Resource
+	tx := db.Table("resource").Where("is_deleted = ?", model.ResourceIsDeleted)
+	if shortName != "" {
+		tx = tx.Where("short_name = ?", shortName)
+	}
+	if err := tx.Order(trashDeletedTimeExpr + " DESC").Find(&resources).Error; err != nil {
+		zap.L().Error("mysql.GetTrashList() failed", zap.String("shortName", shortName), zap.Error(err))
+		return nil, err
+	}
+	return resources, nil
+}
+
+// GetExpiredTrash 查询删除时间早于 before 的回收站资源，limit <= 0 表示不限制
+func GetExpiredTrash(before time.Time, limit int) ([]do.Resource, error) {
+	var resources []do.Resource
+	tx := db.Table("resource").
+		Where("is_deleted = ? AND "+trashDeletedTimeExpr+" < ?", model.ResourceIsDeleted, before).
+		Order(trashDeletedTimeExpr + " ASC")
+	if limit > 0 {
+		tx = tx.Limit(limit)
+	}
+	if err := tx.Find(&resources).Error; err != nil {
+		zap.L().Error("mysql.GetExpiredTrash() failed", zap.Time("before", before), zap.Error(err))
+		return nil, err
+	}
+	return resources, nil
+}
+
+// CountResourcesSharingPath 统计除 id 之外、仍然指向同一个 COS 对象 (short_name + name) 的资源数
+func CountResourcesSharingPath(id int, shortName, name string) (int64, error) {
+	var count int64
+	if err := db.Table("resource").
+		Where("id <> ? AND short_name = ? AND name = ?", id, shortName, name).
+		Count(&count).Error; err != nil {
+		zap.L().Error("mysql.CountResourcesSharingPath() failed", zap.Int("id", id), zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
+
+// HardDeleteResource 从 resource 表中彻底删除回收站中的资源；并发恢复时返回 ErrResourceNotInTrash
+func HardDeleteResource(id int) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Table("resource").Where("id = ? AND is_deleted = ?", id, model.ResourceIsDeleted).Delete(&do.Resource{})
+		if result.Error != nil {
+			zap.L().Error("mysql.HardDeleteResource() failed", zap.Int("id", id), zap.Error(result.Error))
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrResourceNotInTrash
+		}
+		// 版本链中指向该资源的 prev_id 置空，root_id 保留（历史查询仍以原链 id 为准）
+		if err := tx.Table("resource").Where("prev_id = ?", id).Update("prev_id", nil).Error; err != nil {
+			zap.L().Error("mysql.HardDeleteResource() clear prev_id failed", zap.Int("id", id), zap.Error(err))
+			return err
+		}
+		zap.L().Info("mysql.HardDeleteResource() success", zap.Int("id", id))
+		return nil
+	})
+}
@@ -1,10 +1,10 @@
 package mysql
 
 import (
-	"context"
 	"errors"
 	"fmt"
 	"go.uber.org/zap"
+	"logo_api/model"
 	do "logo_api/model/university/do"
 	dto "logo_api/model/university/dto"
 	"logo_api/settings"
@@ -89,8 +89,8 @@ func GetInitUniversities() ([]settings.InitUniversities, error) {
 
 func GetUniversityByName(name string) (do.University, error) {
 	var university do.University
-	// 先查到高校准确的 shortName
-	err := db.Table("university").Where("short_name = ? OR title = ?", name, name).First(&university).Error
+	// 先查到高校准确的 shortName（其他标识先通过 ResolveUniversity 解析为 short_name 再传入）
+	err := db.Table("university").Where("(short_name = ? OR title = ?) AND is_deleted = ?", name, name, model.UniversityIsActive).First(&university).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			// 找不到记录
@@ -220,29 +220,41 @@ func InitInsertUniversities(universities []settings.InitUniversities) error {
 	return nil
 }
 
+// GetUniversityByShortName 只根据 short_name 查询高校，不刷新统计字段
+func GetUniversityByShortName(shortName string) (do.University, error) {
+	var university do.University
+	if err := db.Table("university").Where("short_name = ?", shortName).First(&university).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			zap.L().Error("mysql.GetUniversityByShortName() failed", zap.String("shortName", shortName), zap.Error(err))
+		}
+		return do.University{}, err
+	}
+	return university, nil
+}
+
+// GetUniversityBySlug 只根据 slug 查询高校，不刷新统计字段
+func GetUniversityBySlug(slug string) (do.University, error) {
+	var university do.University
+	if err := db.Table("university").Where("slug = ?", slug).First(&university).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			zap.L().Error("mysql.GetUniversityBySlug() failed", zap.String("slug", slug), zap.Error(err))
+		}
+		return do.University{}, err
+	}
+	return university, nil
+}
+
 func GetUniversityList(req dto.UniversityGetListReq) (universities []do.University, totalCount int64, err error) {
 	page := req.Page
 	pageSize := req.PageSize
-	keyword := req.Keyword
 	sortBy := req.SortBy
 	sortOrder := req.SortOrder
 	// 1. 初始化查询构建器
 	// db.Model(&model.Universities{}) 创建一个基于 Universities 模型的查询事务 (tx)
 	tx := db.Table("university").Model(&do.University{})
 
-	/*
-		// 只查询 is_deleted = 0 的记录
-		目前没有这个字段
-		tx = tx.Where("is_deleted = ?", 0)*/
-
-	// 2. 处理关键字搜索 (Keyword)
-	// 根据图片中 'keyword' 的说明 "模糊查询"
-	if keyword != "" {
-		query := "%" + keyword + "%"
-		// 模糊查询：查找 'title' 字段中包含 keyword 的记录
-		// GORM 的 Where 方法用于构建 WHERE 子句
-		tx = tx.Where("(title LIKE ?) or (short_name LIKE ?)", query, query)
-	}
+	// 2. 处理停用状态 (默认只查 is_deleted = 0)、关键字搜索 (Keyword) 和结构化筛选（地区、矢量、资源数量、时间范围等）
+	tx = applyUniversityFilters(tx, req, facetNone)
 
 	// 3. 统计总记录数 (Total Count)
 	// 在应用分页 (Limit/Offset) 之前，先统计符合搜索条件的总数
@@ -254,8 +266,17 @@ func GetUniversityList(req dto.UniversityGetListReq) (universities []do.Universi
 	// 4. 处理排序 (SortBy & SortOrder)
 	// GORM 的 Order 方法用于构建 ORDER BY 子句
 	// 排序字段 (sortBy) 和排序方向 (sortOrder) 拼接成 "字段 方向" 的形式
-	orderClause := sortBy + " " + sortOrder
-	tx = tx.Order(orderClause)
+	// sortBy 为 relevance 时按关键字相关度排序，没有关键字时退回按 title 排序
+	if sortBy == "relevance" {
+		if req.Keyword != "" {
+			tx = tx.Order(universityRelevanceOrder(req.Keyword))
+		} else {
+			tx = tx.Order("title asc")
+		}
+	} else {
+		orderClause := sortBy + " " + sortOrder
+		tx = tx.Order(orderClause)
+	}
 
 	// 5. 处理分页 (Pagination)
 	// 计算偏移量 Offset = (page - 1) * pageSize
@@ -277,10 +298,12 @@ func GetUniversityList(req dto.UniversityGetListReq) (universities []do.Universi
 }
 
 // UpdateUniversities 根据传入的 model.Universities 数组，更新 universities 表
-func UpdateUniversities(dtoUniversities []dto.UniversityUpdateReq) error {
+// 涉及 short_name 修改时，在同一事务中创建 COS 目录迁移任务，返回新建任务的 id，由调用方负责执行
+func UpdateUniversities(dtoUniversities []dto.UniversityUpdateReq) ([]int, error) {
+	var jobIDs []int
 	if len(dtoUniversities) == 0 {
 		zap.L().Info("mysql.UpdateUniversities() failed: req has no universities")
-		return nil
+		return jobIDs, nil
 	}
 
 	// start
@@ -289,7 +312,7 @@ func UpdateUniversities(dtoUniversities []dto.UniversityUpdateReq) error {
 		var oldUniversity do.University // 原信息
 		if err := db.Table("university").Where("slug = ?", u.Slug).First(&oldUniversity).Error; err != nil {
 			zap.L().Error("mysql.UpdateUniversities() failed to find university", zap.String("slug", u.Slug))
-			return err // 或者 return err
+			return jobIDs, err
 		}
 		// 2. 预判逻辑：如果 short_name 变了，检查新 short_name 是否已被占用
 		if oldUniversity.ShortName != u.ShortName {
@@ -300,11 +323,12 @@ func UpdateUniversities(dtoUniversities []dto.UniversityUpdateReq) error {
 				zap.L().Error("mysql.UpdateUniversities() failed: short_name already exists",
 					zap.String("new_short_name", u.ShortName))
 				// TODO: 应该返回 model/enum.go 里的枚举Error类，方便接口返回信息。
-				return fmt.Errorf("short_name '%s' has been taken by another university", u.ShortName)
+				return jobIDs, fmt.Errorf("short_name '%s' has been taken by another university", u.ShortName)
 			}
 		}
 
 		oldShortName := oldUniversity.ShortName
+		jobID := 0
 		// 3. 在事务中更新数据库
 		err := db.Transaction(func(tx *gorm.DB) error {
 			if u.Slug == "nil" {
@@ -315,6 +339,7 @@ func UpdateUniversities(dtoUniversities []dto.UniversityUpdateReq) error {
 
 			oldUniversity.ShortName = u.ShortName
 			oldUniversity.Title = u.Title
+			oldUniversity.Pinyin, oldUniversity.Initials = util.TitlePinyin(u.Title)
 			oldUniversity.Vis = u.Vis
 			oldUniversity.Website = u.Website
 			oldUniversity.FullNameEn = u.FullNameEn
@@ -323,7 +348,7 @@ func UpdateUniversities(dtoUniversities []dto.UniversityUpdateReq) error {
 			oldUniversity.City = u.City
 			oldUniversity.Story = u.Story
 			result := tx.Table("university").
-				Omit("HasVector MainVectorFormat ResourceCount ComputationID CreatedTime UpdatedTime").
+				Omit("HasVector MainVectorFormat ResourceCount ComputationID IsDeleted DeletedTime Version CreatedTime UpdatedTime").
 				Where("slug = ?", u.Slug).
 				Updates(&oldUniversity)
 			if err := result.Error; err != nil {
@@ -334,34 +359,35 @@ func UpdateUniversities(dtoUniversities []dto.UniversityUpdateReq) error {
 				// 这里仅记录日志，不中断事务。
 				zap.L().Warn("No record found to update", zap.String("slug", u.Slug))
 			}
+			// 整条更新同样递增编辑版本号，使基于旧版本的 PATCH 请求失败
+			if err := tx.Table("university").Where("slug = ?", u.Slug).UpdateColumn("version", gorm.Expr("version + 1")).Error; err != nil {
+				zap.L().Error("mysql.UpdateUniversities() bump version failed", zap.String("slug", u.Slug), zap.Error(err))
+				return err
+			}
 			// 更新后刷新 HasVector MainVectorFormat ResourceCount ComputationID 四个字段
 			if err := RefreshUniversityStats(tx, oldUniversity.ShortName); err != nil {
 				zap.L().Error("mysql.RefreshUniversityStats() failed", zap.String("ShortName", oldUniversity.ShortName), zap.Error(err))
 				return err
 			}
 			// 注意：开启了 ON UPDATE CASCADE，tx 更新 short_name 后，resource 表会自动同步
+			// 4. 如果涉及 shortName 字段的修改，在同一事务中记录 COS 目录迁移任务，保证数据库与迁移任务同时提交
+			if oldShortName != u.ShortName {
+				id, err := createRenameJob(tx, u.Slug, oldShortName, u.ShortName)
+				if err != nil {
+					return err
+				}
+				jobID = id
+			}
 			return nil
 		})
 
 		if err != nil {
 			zap.L().Error("mysql.UpdateUniversities() Error", zap.String("Title", oldUniversity.Title), zap.Error(err))
-			return err
+			return jobIDs, err
 		}
-
-		// 4. 如果涉及 shortName 字段的修改，在事务成功提交后，同步修改腾讯云COS文件夹名称
-		if oldShortName != u.ShortName {
-			client, err := util.NewClient(settings.Config.CosConfig)
-			if err != nil {
-				zap.L().Error("Failed to init COS client", zap.Error(err))
-				return err // 此时数据已更新但文件没改，需要做补偿逻辑或补偿日志
-			}
-			// 调用重命名文件夹逻辑
-			if err = client.RenameFolder(context.Background(), oldShortName, u.ShortName); err != nil {
-				zap.L().Error("COS Rename failed! Manual intervention required",
-					zap.String("from", oldShortName), zap.String("to", u.ShortName))
-				return err
-			}
+		if jobID != 0 {
+			jobIDs = append(jobIDs, jobID)
 		}
 	}
-	return nil
+	return jobIDs, nil
 }
@@ -0,0 +1,102 @@
+package mysql
+
+import (
+	"errors"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	do "logo_api/model/university/do"
+)
+
+// activeAliasSlugs 当前有效的别名对应的高校 slug 子查询，参数为别名
+const activeAliasSlugs = `SELECT slug FROM university_alias WHERE alias = ?
+	AND (valid_from IS NULL OR valid_from <= CURDATE()) AND (valid_to IS NULL OR valid_to >= CURDATE())`
+
+// activeAliasLikeSlugs 与 activeAliasSlugs 相同，别名按 LIKE 匹配
+const activeAliasLikeSlugs = `SELECT slug FROM university_alias WHERE alias LIKE ?
+	AND (valid_from IS NULL OR valid_from <= CURDATE()) AND (valid_to IS NULL OR valid_to >= CURDATE())`
+
+// GetUniversityAliases 查询别名列表，slug 为空表示全部高校
+func GetUniversityAliases(slug string) ([]do.UniversityAlias, error) {
+	var aliases []do.UniversityAlias
+	tx := db.Table("university_alias")
+	if slug != "" {
+		tx = tx.Where("slug = ?", slug)
+	}
+	if err := tx.Order("slug, id").Find(&aliases).Error; err != nil {
+		zap.L().Error("mysql.GetUniversityAliases() failed", zap.String("slug", slug), zap.Error(err))
+		return nil, err
+	}
+	return aliases, nil
+}
+
+// GetUniversityAlias 根据 id 查询别名
+func GetUniversityAlias(id int) (do.UniversityAlias, error) {
+	var alias do.UniversityAlias
+	if err := db.Table("university_alias").Where("id = ?", id).First(&alias).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			zap.L().Error("mysql.GetUniversityAlias() failed", zap.Int("id", id), zap.Error(err))
+		}
+		return do.UniversityAlias{}, err
+	}
+	return alias, nil
+}
+
+// IsUniversityNameTaken 检查名称是否已被其他用途占用：其他别名（排除 excludeAliasID），
+// 或除 slug 以外高校的中文名、简称
+func IsUniversityNameTaken(name, slug string, excludeAliasID int) (bool, error) {
+	var count int64
+	if err := db.Table("university_alias").Where("alias = ? AND id <> ?", name, excludeAliasID).Count(&count).Error; err != nil {
+		zap.L().Error("mysql.IsUniversityNameTaken() failed", zap.String("name", name), zap.Error(err))
+		return false, err
+	}
+	if count > 0 {
+		return true, nil
+	}
+	if err := db.Table("university").Where("(title = ? OR short_name = ?) AND slug <> ?", name, name, slug).Count(&count).Error; err != nil {
+		zap.L().Error("mysql.IsUniversityNameTaken() failed", zap.String("name", name), zap.Error(err))
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// InsertUniversityAlias 新增别名
+func InsertUniversityAlias(alias *do.UniversityAlias) error {
+	if err := db.Table("university_alias").Create(alias).Error; err != nil {
+		zap.L().Error("mysql.InsertUniversityAlias() failed", zap.Any("alias", alias), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// UpdateUniversityAlias 修改别名，日期字段允许改回 NULL
+func UpdateUniversityAlias(alias *do.UniversityAlias) error {
+	if err := db.Table("university_alias").Where("id = ?", alias.ID).
+		Select("slug", "alias", "alias_type", "valid_from", "valid_to").
+		Updates(alias).Error; err != nil {
+		zap.L().Error("mysql.UpdateUniversityAlias() failed", zap.Any("alias", alias), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// DeleteUniversityAliases 删除别名，返回实际删除的数量
+func DeleteUniversityAliases(ids []int) (int64, error) {
+	result := db.Table("university_alias").Where("id IN ?", ids).Delete(&do.UniversityAlias{})
+	if result.Error != nil {
+		zap.L().Error("mysql.DeleteUniversityAliases() failed", zap.Ints("ids", ids), zap.Error(result.Error))
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// GetActiveUniversityAliases 查询当前有效的全部别名
+func GetActiveUniversityAliases() ([]do.UniversityAlias, error) {
+	var aliases []do.UniversityAlias
+	if err := db.Table("university_alias").
+		Where("(valid_from IS NULL OR valid_from <= CURDATE()) AND (valid_to IS NULL OR valid_to >= CURDATE())").
+		Find(&aliases).Error; err != nil {
+		zap.L().Error("mysql.GetActiveUniversityAliases() failed", zap.Error(err))
+		return nil, err
+	}
+	return aliases, nil
+}
@@ -0,0 +1,340 @@
+package mysql
+
+import (
+	"errors"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"logo_api/model"
+	do "logo_api/model/university/do"
+	dto "logo_api/model/university/dto"
+	"logo_api/settings"
+	"strings"
+)
+
+// errBatchRollback 全部成功才提交模式下有行失败，用于回滚整个事务
+var errBatchRollback = errors.New("batch rolled back")
+
+// SaveUniversities 批量新增（upsert 为 true 时更新已存在的 slug）高校，返回与 universities 一一对应的处理结果
+// allOrNothing 为 true 时所有行在同一事务中写入，任意一行不成功则整批回滚，成功的行标记为 skipped；
+// 否则每行单独提交。committed 表示是否有数据被提交，jobIDs 为已提交的 COS 目录迁移任务
+func SaveUniversities(universities []settings.Universities, upsert, allOrNothing bool) (results []dto.UniversityRowResult, jobIDs []int, committed bool, err error) {
+	results = make([]dto.UniversityRowResult, len(universities))
+	if !allOrNothing {
+		for i, u := range universities {
+			var jobID int
+			txErr := db.Transaction(func(tx *gorm.DB) error {
+				var rowErr error
+				results[i], jobID, rowErr = saveUniversityRow(tx, u, upsert)
+				if rowErr == nil && !universityRowSucceeded(results[i].Status) {
+					return errBatchRollback
+				}
+				return rowErr
+			})
+			if txErr != nil && !errors.Is(txErr, errBatchRollback) {
+				zap.L().Error("mysql.SaveUniversities() row failed", zap.String("slug", u.Slug), zap.Error(txErr))
+				results[i] = dto.UniversityRowResult{Slug: u.Slug, Status: model.UniversityRowFailed, Message: txErr.Error()}
+				continue
+			}
+			if universityRowSucceeded(results[i].Status) {
+				committed = true
+				if jobID != 0 {
+					jobIDs = append(jobIDs, jobID)
+				}
+			}
+		}
+		return results, jobIDs, committed, nil
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		ids, ok, err := saveUniversityRows(tx, universities, upsert, results)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errBatchRollback
+		}
+		jobIDs = ids
+		return nil
+	})
+	if err != nil {
+		jobIDs = nil
+		for i := range results {
+			if results[i].Status == "" || universityRowSucceeded(results[i].Status) {
+				results[i] = dto.UniversityRowResult{Slug: universities[i].Slug, Status: model.UniversityRowSkipped,
+					Message: "rolled back because another row failed"}
+			}
+		}
+		if errors.Is(err, errBatchRollback) {
+			err = nil
+		}
+		return results, nil, false, err
+	}
+	return results, jobIDs, true, nil
+}
+
+// PreviewUniversities 按 allOrNothing 模式的 SaveUniversities 的判断规则推演每行将会得到的处理结果，只读不写、不加锁
+// 同批内前面成功的行相当于已经写入：它们的新简称和中文名记在 claimed 中，数据库中这些高校的旧名称视为已释放，
+// 所以前面的行改名后空出的名称可以被后面的行使用；与实际写入一样，互换名称的两行中先执行的一行仍然冲突
+// 预览中不会产生迁移任务，结果里的 renameJob 为 0
+func PreviewUniversities(universities []settings.Universities, upsert bool) ([]dto.UniversityRowResult, error) {
+	results := make([]dto.UniversityRowResult, len(universities))
+	claimed := make(map[string]string)
+	written := make(map[string]bool)
+	for i, u := range universities {
+		result, err := previewUniversityRow(u, upsert, claimed, written)
+		if err != nil {
+			zap.L().Error("mysql.PreviewUniversities() row failed", zap.String("slug", u.Slug), zap.Error(err))
+			return nil, err
+		}
+		results[i] = result
+		if universityRowSucceeded(result.Status) {
+			claimed[strings.ToLower(u.ShortName)] = u.Slug
+			claimed[strings.ToLower(u.Title)] = u.Slug
+			written[u.Slug] = true
+		}
+	}
+	return results, nil
+}
+
+// previewUniversityRow 与 saveUniversityRow 的判断顺序一致；claimed 为同批内前面的行占用的名称（小写）及其 slug，
+// written 为这些行的 slug，它们在数据库中的旧名称不再参与冲突判断
+func previewUniversityRow(u settings.Universities, upsert bool, claimed map[string]string, written map[string]bool) (dto.UniversityRowResult, error) {
+	result := dto.UniversityRowResult{Slug: u.Slug}
+	var existing do.University
+	found := true
+	if err := db.Table("university").Where("slug = ?", u.Slug).First(&existing).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return result, err
+		}
+		found = false
+	}
+	if found && !upsert {
+		result.Status, result.ConflictKey, result.ConflictWith = model.UniversityRowConflict, model.UniversityKeySlug, u.Slug
+		return result, nil
+	}
+
+	key, with, err := universityKeyConflictExcept(db, u.Slug, u.ShortName, u.Title, written)
+	if err != nil {
+		return result, err
+	}
+	if key == "" {
+		for _, k := range []struct{ key, value string }{{model.UniversityKeyShortName, u.ShortName}, {model.UniversityKeyTitle, u.Title}} {
+			if slug, ok := claimed[strings.ToLower(k.value)]; ok && slug != u.Slug {
+				key, with = k.key, slug
+				break
+			}
+		}
+	}
+	if key != "" {
+		result.Status, result.ConflictKey, result.ConflictWith = model.UniversityRowConflict, key, with
+		return result, nil
+	}
+
+	if !found {
+		result.Status = model.UniversityRowCreated
+		return result, nil
+	}
+	updates := universityUpsertChanges(existing, u)
+	if len(updates) == 0 {
+		result.Status = model.UniversityRowUnchanged
+		return result, nil
+	}
+	if _, ok := updates["short_name"]; ok {
+		unfinished, err := hasUnfinishedRenameJob(db, u.Slug)
+		if err != nil {
+			return result, err
+		}
+		if unfinished {
+			result.Status, result.Message = model.UniversityRowFailed, ErrRenameJobInProgress.Error()
+			return result, nil
+		}
+	}
+	result.Status, result.Changes = model.UniversityRowUpdated, universityFieldChanges(existing, updates)
+	return result, nil
+}
+
+// saveUniversityRows 在同一事务中逐行写入，结果写入 results；ok 表示所有行都写入成功
+// 数据库错误后事务不再可靠，立即返回 error，调用方应回滚
+func saveUniversityRows(tx *gorm.DB, universities []settings.Universities, upsert bool, results []dto.UniversityRowResult) (jobIDs []int, ok bool, err error) {
+	ok = true
+	for i, u := range universities {
+		result, jobID, rowErr := saveUniversityRow(tx, u, upsert)
+		if rowErr != nil {
+			zap.L().Error("mysql.saveUniversityRows() row failed", zap.String("slug", u.Slug), zap.Error(rowErr))
+			results[i] = dto.UniversityRowResult{Slug: u.Slug, Status: model.UniversityRowFailed, Message: rowErr.Error()}
+			return nil, false, rowErr
+		}
+		results[i] = result
+		if !universityRowSucceeded(result.Status) {
+			ok = false
+		} else if jobID != 0 {
+			jobIDs = append(jobIDs, jobID)
+		}
+	}
+	return jobIDs, ok, nil
+}
+
+// universityRowSucceeded 该行是否写入成功（含未发生变化）
+func universityRowSucceeded(status string) bool {
+	return status == model.UniversityRowCreated || status == model.UniversityRowUpdated || status == model.UniversityRowUnchanged
+}
+
+// saveUniversityRow 在事务中新增或更新一所高校；唯一键冲突体现在结果中，只有数据库错误才返回 error
+func saveUniversityRow(tx *gorm.DB, u settings.Universities, upsert bool) (dto.UniversityRowResult, int, error) {
+	result := dto.UniversityRowResult{Slug: u.Slug}
+	var existing do.University
+	found := true
+	if err := tx.Table("university").Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("slug = ?", u.Slug).First(&existing).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return result, 0, err
+		}
+		found = false
+	}
+	if found && !upsert {
+		result.Status, result.ConflictKey, result.ConflictWith = model.UniversityRowConflict, model.UniversityKeySlug, u.Slug
+		return result, 0, nil
+	}
+
+	// 简称、中文名不能被其他高校或别名占用
+	key, with, err := universityKeyConflict(tx, u.Slug, u.ShortName, u.Title)
+	if err != nil {
+		return result, 0, err
+	}
+	if key != "" {
+		result.Status, result.ConflictKey, result.ConflictWith = model.UniversityRowConflict, key, with
+		return result, 0, nil
+	}
+
+	if !found {
+		u.Vis, u.Story = nilIfEmpty(u.Vis), nilIfEmpty(u.Story)
+		if err = tx.Table("university").Omit("CreatedTime", "UpdatedTime").Create(&u).Error; err != nil {
+			return result, 0, err
+		}
+		result.Status = model.UniversityRowCreated
+		return result, 0, nil
+	}
+
+	updates := universityUpsertChanges(existing, u)
+	if len(updates) == 0 {
+		result.Status = model.UniversityRowUnchanged
+		return result, 0, nil
+	}
+	result.Changes = universityFieldChanges(existing, updates)
+	jobID, err := applyUniversityChanges(tx, existing, updates)
+	if err != nil {
+		if errors.Is(err, ErrRenameJobInProgress) {
+			result.Status, result.Message = model.UniversityRowFailed, err.Error()
+			return result, 0, nil
+		}
+		return result, 0, err
+	}
+	result.Status, result.RenameJob = model.UniversityRowUpdated, jobID
+	return result, jobID, nil
+}
+
+// universityUpsertChanges upsert 时需要更新的字段：必填字段整条替换；vis、story 未传入（nil）时保持不变，传入空串时清空
+func universityUpsertChanges(existing do.University, u settings.Universities) map[string]interface{} {
+	return universityChanges(existing, dto.UniversityPatchReq{
+		ShortName:  &u.ShortName,
+		Title:      &u.Title,
+		Vis:        u.Vis,
+		Website:    &u.Website,
+		FullNameEn: &u.FullNameEn,
+		Region:     &u.Region,
+		Province:   &u.Province,
+		City:       &u.City,
+		Story:      u.Story,
+	})
+}
+
+// universityKeyConflict 检查简称、中文名是否被 slug 以外的高校（简称或中文名）或其他高校的别名（不论是否在有效期内）占用，
+// 返回冲突的键和占用者的 slug；按名称查询时简称和中文名不区分，所以交叉相同也算冲突。批量写入、导入和 PATCH 共用这一规则
+func universityKeyConflict(tx *gorm.DB, slug, shortName, title string) (key, with string, err error) {
+	return universityKeyConflictExcept(tx, slug, shortName, title, nil)
+}
+
+// universityKeyConflictExcept 同 universityKeyConflict，但忽略 except 中高校在数据库里的简称和中文名（预览时这些高校已被同批前面的行改写）
+func universityKeyConflictExcept(tx *gorm.DB, slug, shortName, title string, except map[string]bool) (key, with string, err error) {
+	names := []string{shortName, title}
+	var found []do.University
+	if err = tx.Table("university").Select("slug, short_name, title").
+		Where("(short_name IN ? OR title IN ?) AND slug <> ?", names, names, slug).
+		Find(&found).Error; err != nil {
+		return "", "", err
+	}
+	others := found[:0]
+	for _, o := range found {
+		if !except[o.Slug] {
+			others = append(others, o)
+		}
+	}
+	for _, o := range others {
+		if strings.EqualFold(o.ShortName, shortName) || strings.EqualFold(o.Title, shortName) {
+			return model.UniversityKeyShortName, o.Slug, nil
+		}
+	}
+	if len(others) > 0 {
+		return model.UniversityKeyTitle, others[0].Slug, nil
+	}
+	var aliases []do.UniversityAlias
+	if err = tx.Table("university_alias").Select("slug").
+		Where("alias IN ? AND slug <> ?", []string{shortName, title}, slug).
+		Limit(1).Find(&aliases).Error; err != nil {
+		return "", "", err
+	}
+	if len(aliases) > 0 {
+		return model.UniversityKeyAlias, aliases[0].Slug, nil
+	}
+	return "", "", nil
+}
+
+// nilIfEmpty 新增高校时空串的可空字段写入 NULL
+func nilIfEmpty(v *string) *string {
+	if v != nil && *v == "" {
+		return nil
+	}
+	return v
+}
+
+// universityColumns 可编辑的列及其对外的字段名，决定 diff 中字段的顺序
+var universityColumns = []struct{ column, field string }{
+	{"short_name", "shortName"},
+	{"title", "title"},
+	{"vis", "vis"},
+	{"website", "website"},
+	{"full_name_en", "fullNameEn"},
+	{"region", "region"},
+	{"province", "province"},
+	{"city", "city"},
+	{"story", "story"},
+}
+
+// universityFieldChanges 把 universityChanges 收集的字段转换成带旧值的 diff
+func universityFieldChanges(current do.University, updates map[string]interface{}) []dto.FieldChange {
+	old := map[string]*string{
+		"short_name":   &current.ShortName,
+		"title":        &current.Title,
+		"vis":          current.Vis,
+		"website":      &current.Website,
+		"full_name_en": &current.FullNameEn,
+		"region":       &current.Region,
+		"province":     &current.Province,
+		"city":         &current.City,
+		"story":        current.Story,
+	}
+	var changes []dto.FieldChange
+	for _, c := range universityColumns {
+		value, ok := updates[c.column]
+		if !ok {
+			continue
+		}
+		change := dto.FieldChange{Field: c.field, Old: old[c.column]}
+		if v, ok := value.(string); ok {
+			change.New = &v
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
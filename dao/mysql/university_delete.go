@@ -0,0 +1,113 @@
+package mysql
+
+import (
+	"errors"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"logo_api/model"
+	resdo "logo_api/model/resource/do"
+	do "logo_api/model/university/do"
+	"time"
+)
+
+// ErrUniversityNotDeleted 彻底删除前高校必须先停用（软删除）
+var ErrUniversityNotDeleted = errors.New("university must be soft deleted before hard delete")
+
+// setUniversitiesDeleted 把 slugs 中处于 from 状态的高校改为另一状态，返回实际修改的高校
+func setUniversitiesDeleted(slugs []string, from, to int, deletedTime *time.Time) ([]do.University, error) {
+	var universities []do.University
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Table("university").Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("slug IN ? AND is_deleted = ?", slugs, from).
+			Find(&universities).Error; err != nil {
+			return err
+		}
+		if len(universities) == 0 {
+			return nil
+		}
+		changed := make([]string, 0, len(universities))
+		for _, u := range universities {
+			changed = append(changed, u.Slug)
+		}
+		return tx.Table("university").Where("slug IN ?", changed).
+			Updates(map[string]interface{}{"is_deleted": to, "deleted_time": deletedTime}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return universities, nil
+}
+
+// SoftDeleteUniversities 停用高校：名称解析、列表和取图都不再返回它，资源记录和 COS 文件保留以便恢复
+// 已停用或不存在的 slug 会被忽略，返回本次实际停用的高校
+func SoftDeleteUniversities(slugs []string) ([]do.University, error) {
+	now := time.Now()
+	universities, err := setUniversitiesDeleted(slugs, model.UniversityIsActive, model.UniversityIsDeleted, &now)
+	if err != nil {
+		zap.L().Error("mysql.SoftDeleteUniversities() failed", zap.Strings("slugs", slugs), zap.Error(err))
+		return nil, err
+	}
+	zap.L().Info("mysql.SoftDeleteUniversities() success", zap.Strings("slugs", slugs), zap.Int("count", len(universities)))
+	return universities, nil
+}
+
+// RestoreUniversities 恢复已停用的高校，未停用或不存在的 slug 会被忽略，返回本次实际恢复的高校
+func RestoreUniversities(slugs []string) ([]do.University, error) {
+	universities, err := setUniversitiesDeleted(slugs, model.UniversityIsDeleted, model.UniversityIsActive, nil)
+	if err != nil {
+		zap.L().Error("mysql.RestoreUniversities() failed", zap.Strings("slugs", slugs), zap.Error(err))
+		return nil, err
+	}
+	zap.L().Info("mysql.RestoreUniversities() success", zap.Strings("slugs", slugs), zap.Int("count", len(universities)))
+	return universities, nil
+}
+
+// HardDeleteUniversity 在一个事务中彻底删除已停用的高校及其全部资源记录（含回收站和历史版本）、
+// 别名（外键级联）和重命名任务，返回被删除的高校和资源，COS 文件由调用方清理
+// 高校未停用时返回 ErrUniversityNotDeleted，还有未完成的重命名任务时返回 ErrRenameJobInProgress
+func HardDeleteUniversity(slug string) (do.University, []resdo.Resource, error) {
+	var (
+		university do.University
+		resources  []resdo.Resource
+	)
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Table("university").Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("slug = ?", slug).First(&university).Error; err != nil {
+			return err
+		}
+		if university.IsDeleted != model.UniversityIsDeleted {
+			return ErrUniversityNotDeleted
+		}
+		var running int64
+		if err := tx.Table("university_rename_job").
+			Where("slug = ? AND status <> ?", slug, model.RenameJobDone).
+			Count(&running).Error; err != nil {
+			return err
+		}
+		if running > 0 {
+			// 迁移中的对象分散在新旧两个目录，等任务完成后再删除
+			return ErrRenameJobInProgress
+		}
+		if err := tx.Table("resource").Where("short_name = ?", university.ShortName).Find(&resources).Error; err != nil {
+			return err
+		}
+		// resource.short_name 外键没有级联删除，先删资源再删高校
+		if err := tx.Table("resource").Where("short_name = ?", university.ShortName).Delete(&resdo.Resource{}).Error; err != nil {
+			return err
+		}
+		// 重命名对象进度随任务级联删除
+		if err := tx.Table("university_rename_job").Where("slug = ?", slug).Delete(&do.RenameJob{}).Error; err != nil {
+			return err
+		}
+		return tx.Table("university").Where("slug = ?", slug).Delete(&do.University{}).Error
+	})
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) && !errors.Is(err, ErrUniversityNotDeleted) && !errors.Is(err, ErrRenameJobInProgress) {
+			zap.L().Error("mysql.HardDeleteUniversity() failed", zap.String("slug", slug), zap.Error(err))
+		}
+		return do.University{}, nil, err
+	}
+	zap.L().Info("mysql.HardDeleteUniversity() success", zap.String("slug", slug), zap.String("shortName", university.ShortName), zap.Int("resources", len(resources)))
+	return university, resources, nil
+}
@@ -0,0 +1,115 @@
+package mysql
+
+import (
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"logo_api/model"
+	do "logo_api/model/university/do"
+	dto "logo_api/model/university/dto"
+)
+
+// 分面维度，applyUniversityFilters 据此跳过该维度自身的筛选条件
+const (
+	facetNone             = ""
+	facetRegion           = "region"
+	facetProvince         = "province"
+	facetCity             = "city"
+	facetHasVector        = "has_vector"
+	facetMainVectorFormat = "main_vector_format"
+	facetResourceCount    = "resource_count"
+)
+
+// resourceCountBucketExpr 资源数量分段，与 UniversityFacets.ResourceCount 的说明一致
+const resourceCountBucketExpr = `CASE
+	WHEN resource_count <= 0 THEN '0'
+	WHEN resource_count <= 5 THEN '1-5'
+	WHEN resource_count <= 20 THEN '6-20'
+	ELSE '21+' END`
+
+// applyUniversityFilters 把列表请求中的关键字和结构化筛选条件加到查询上，skip 指定的维度不加
+// 未指定 isDeleted 时只查有效（未停用）的高校
+func applyUniversityFilters(tx *gorm.DB, req dto.UniversityGetListReq, skip string) *gorm.DB {
+	isDeleted := model.UniversityIsActive
+	if req.IsDeleted != nil {
+		isDeleted = *req.IsDeleted
+	}
+	tx = tx.Where("is_deleted = ?", isDeleted)
+	if req.Keyword != "" {
+		tx = universityKeywordWhere(tx, req.Keyword)
+	}
+	if len(req.Regions) > 0 && skip != facetRegion {
+		tx = tx.Where("region IN ?", req.Regions)
+	}
+	if len(req.Provinces) > 0 && skip != facetProvince {
+		tx = tx.Where("province IN ?", req.Provinces)
+	}
+	if len(req.Cities) > 0 && skip != facetCity {
+		tx = tx.Where("city IN ?", req.Cities)
+	}
+	if req.HasVector != nil && skip != facetHasVector {
+		tx = tx.Where("has_vector = ?", *req.HasVector)
+	}
+	if len(req.MainVectorFormats) > 0 && skip != facetMainVectorFormat {
+		tx = tx.Where("main_vector_format IN ?", req.MainVectorFormats)
+	}
+	if skip != facetResourceCount {
+		if req.MinResourceCount != nil {
+			tx = tx.Where("resource_count >= ?", *req.MinResourceCount)
+		}
+		if req.MaxResourceCount != nil {
+			tx = tx.Where("resource_count <= ?", *req.MaxResourceCount)
+		}
+	}
+	if req.CreatedAfter != nil {
+		tx = tx.Where("created_time >= ?", *req.CreatedAfter)
+	}
+	if req.CreatedBefore != nil {
+		tx = tx.Where("created_time <= ?", *req.CreatedBefore)
+	}
+	if req.UpdatedAfter != nil {
+		tx = tx.Where("updated_time >= ?", *req.UpdatedAfter)
+	}
+	if req.UpdatedBefore != nil {
+		tx = tx.Where("updated_time <= ?", *req.UpdatedBefore)
+	}
+	return tx
+}
+
+// GetUniversityFacets 统计 /university/list 各筛选维度的取值分布
+func GetUniversityFacets(req dto.UniversityGetListReq) (dto.UniversityFacets, error) {
+	var (
+		facets dto.UniversityFacets
+		err    error
+	)
+	dimensions := []struct {
+		facet  string
+		expr   string
+		target *[]dto.FacetCount
+	}{
+		{facetRegion, "region", &facets.Region},
+		{facetProvince, "province", &facets.Province},
+		{facetCity, "city", &facets.City},
+		{facetHasVector, "CAST(has_vector AS CHAR)", &facets.HasVector},
+		{facetMainVectorFormat, "main_vector_format", &facets.MainVectorFormat},
+		{facetResourceCount, resourceCountBucketExpr, &facets.ResourceCount},
+	}
+	for _, d := range dimensions {
+		if *d.target, err = countUniversityFacet(req, d.facet, d.expr); err != nil {
+			zap.L().Error("mysql.GetUniversityFacets() failed", zap.String("facet", d.facet), zap.Error(err))
+			return dto.UniversityFacets{}, err
+		}
+	}
+	return facets, nil
+}
+
+// countUniversityFacet 按 expr 分组计数，空值不计入
+func countUniversityFacet(req dto.UniversityGetListReq, facet, expr string) ([]dto.FacetCount, error) {
+	counts := make([]dto.FacetCount, 0)
+	tx := applyUniversityFilters(db.Table("university").Model(&do.University{}), req, facet)
+	err := tx.Select(expr + " AS value, COUNT(*) AS count").
+		Where(expr + " IS NOT NULL AND " + expr + " <> ''").
+		Group("value").
+		Order("count DESC, value ASC").
+		Scan(&counts).Error
+	return counts, err
+}
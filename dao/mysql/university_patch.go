@@ -0,0 +1,138 @@
+package mysql
+
+import (
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	do "logo_api/model/university/do"
+	dto "logo_api/model/university/dto"
+	"logo_api/util"
+)
+
+var (
+	// ErrUniversityVersionChanged If-Match 中的版本号与当前版本不一致，高校已被其他请求修改
+	ErrUniversityVersionChanged = errors.New("university has been modified by another request, reload and retry")
+	// ErrUniversityNameTaken 新的简称或中文名已被其他高校或别名占用
+	ErrUniversityNameTaken = errors.New("short name or title has been taken")
+)
+
+// PatchUniversity 以 version 为前置条件，只更新 req 中传入的字段，成功后版本号加 1
+// 与 UpdateUniversities 相同：更新后刷新统计字段，short_name 变化时在同一事务中创建 COS 目录迁移任务
+// 版本不一致时返回当前数据和 ErrUniversityVersionChanged；没有字段发生变化时不修改版本号，jobID 为 0 表示没有迁移任务
+func PatchUniversity(slug string, version int, req dto.UniversityPatchReq) (university do.University, jobID int, err error) {
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// 1. 锁定当前记录并核对版本号，避免并发修改互相覆盖
+		if err := tx.Table("university").Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("slug = ?", slug).First(&university).Error; err != nil {
+			return err
+		}
+		if university.Version != version {
+			return ErrUniversityVersionChanged
+		}
+
+		// 2. 只收集与当前值不同的字段
+		updates := universityChanges(university, req)
+		if len(updates) == 0 {
+			return nil
+		}
+
+		// 3. 简称、中文名不能与其他高校或别名重复，判断规则与批量 upsert 相同
+		_, shortNameChanged := updates["short_name"]
+		_, titleChanged := updates["title"]
+		if shortNameChanged || titleChanged {
+			shortName, title := university.ShortName, university.Title
+			if req.ShortName != nil {
+				shortName = *req.ShortName
+			}
+			if req.Title != nil {
+				title = *req.Title
+			}
+			key, with, err := universityKeyConflict(tx, slug, shortName, title)
+			if err != nil {
+				return err
+			}
+			if key != "" {
+				return fmt.Errorf("%w: %s is used by %s", ErrUniversityNameTaken, key, with)
+			}
+		}
+
+		// 4. 更新并刷新统计字段，short_name 变化时创建迁移任务
+		id, err := applyUniversityChanges(tx, university, updates)
+		if err != nil {
+			return err
+		}
+		jobID = id
+		return tx.Table("university").Where("slug = ?", slug).First(&university).Error
+	})
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) && !errors.Is(err, ErrUniversityVersionChanged) &&
+			!errors.Is(err, ErrUniversityNameTaken) && !errors.Is(err, ErrRenameJobInProgress) {
+			zap.L().Error("mysql.PatchUniversity() failed", zap.String("slug", slug), zap.Int("version", version), zap.Error(err))
+		}
+		if errors.Is(err, ErrUniversityVersionChanged) {
+			return university, 0, err
+		}
+		return do.University{}, 0, err
+	}
+	zap.L().Info("mysql.PatchUniversity() success", zap.String("slug", slug), zap.Int("version", university.Version), zap.Int("renameJob", jobID))
+	return university, jobID, nil
+}
+
+// universityChanges 收集 req 中与 current 不同的字段（列名 -> 新值），req 中为 nil 的字段保持不变
+// vis、story 传入空串表示清空为 NULL
+func universityChanges(current do.University, req dto.UniversityPatchReq) map[string]interface{} {
+	updates := make(map[string]interface{})
+	setString := func(column string, value *string, current string) {
+		if value != nil && *value != current {
+			updates[column] = *value
+		}
+	}
+	setNullable := func(column string, value *string, current *string) {
+		if value == nil {
+			return
+		}
+		if *value == "" {
+			if current != nil {
+				updates[column] = nil
+			}
+		} else if current == nil || *current != *value {
+			updates[column] = *value
+		}
+	}
+	setString("short_name", req.ShortName, current.ShortName)
+	setString("title", req.Title, current.Title)
+	setString("website", req.Website, current.Website)
+	setString("full_name_en", req.FullNameEn, current.FullNameEn)
+	setString("region", req.Region, current.Region)
+	setString("province", req.Province, current.Province)
+	setString("city", req.City, current.City)
+	setNullable("vis", req.Vis, current.Vis)
+	setNullable("story", req.Story, current.Story)
+	return updates
+}
+
+// applyUniversityChanges 在事务中写入 universityChanges 收集的字段并把版本号加 1：
+// 中文名变化时同步全拼和首字母，更新后刷新统计字段，short_name 变化时创建 COS 目录迁移任务并返回任务 id
+// 注意：开启了 ON UPDATE CASCADE，更新 short_name 后 resource 表会自动同步
+func applyUniversityChanges(tx *gorm.DB, current do.University, updates map[string]interface{}) (jobID int, err error) {
+	if title, ok := updates["title"].(string); ok {
+		updates["pinyin"], updates["initials"] = util.TitlePinyin(title)
+	}
+	updates["version"] = gorm.Expr("version + 1")
+	if err = tx.Table("university").Where("slug = ? AND version = ?", current.Slug, current.Version).Updates(updates).Error; err != nil {
+		return 0, err
+	}
+	newShortName := current.ShortName
+	if shortName, ok := updates["short_name"].(string); ok {
+		newShortName = shortName
+	}
+	if err = RefreshUniversityStats(tx, newShortName); err != nil {
+		return 0, err
+	}
+	if newShortName != current.ShortName {
+		return createRenameJob(tx, current.Slug, current.ShortName, newShortName)
+	}
+	return 0, nil
+}
@@ -0,0 +1,167 @@
+package mysql
+
+import (
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"logo_api/model"
+	do "logo_api/model/university/do"
+	dto "logo_api/model/university/dto"
+	"logo_api/util"
+	"strings"
+)
+
+// universityKeywordWhere 关键字检索条件：中文名、英文简称、英文全称、识别码、有效别名按包含匹配；
+// 关键字是纯字母数字时，再按全拼包含、首字母前缀匹配
+func universityKeywordWhere(tx *gorm.DB, keyword string) *gorm.DB {
+	like := "%" + keyword + "%"
+	py := util.NormalizePinyinQuery(keyword)
+	if py == "" {
+		return tx.Where("(title LIKE ? OR short_name LIKE ? OR full_name_en LIKE ? OR slug LIKE ? OR slug IN ("+activeAliasLikeSlugs+"))",
+			like, like, like, like, like)
+	}
+	return tx.Where("(title LIKE ? OR short_name LIKE ? OR full_name_en LIKE ? OR slug LIKE ? OR slug IN ("+activeAliasLikeSlugs+") OR pinyin LIKE ? OR initials LIKE ?)",
+		like, like, like, like, like, "%"+py+"%", py+"%")
+}
+
+// universityRelevanceOrder 关键字检索的相关度排序，数值越小越相关：
+// 0 名称/简称/识别码完全相同；1 有效别名、全拼或首字母完全相同；2 名称、简称、全拼、首字母前缀匹配；
+// 3 名称或简称包含；4 全拼包含；5 其他（英文全称、识别码包含）
+func universityRelevanceOrder(keyword string) clause.OrderBy {
+	py := util.NormalizePinyinQuery(keyword)
+	prefix, like := keyword+"%", "%"+keyword+"%"
+	return clause.OrderBy{Expression: clause.Expr{
+		SQL: `CASE
+	WHEN title = ? OR short_name = ? OR slug = ? THEN 0
+	WHEN slug IN (` + activeAliasSlugs + `) OR (? <> '' AND (pinyin = ? OR initials = ?)) THEN 1
+	WHEN title LIKE ? OR short_name LIKE ? OR (? <> '' AND (pinyin LIKE ? OR initials LIKE ?)) THEN 2
+	WHEN title LIKE ? OR short_name LIKE ? THEN 3
+	WHEN ? <> '' AND pinyin LIKE ? THEN 4
+	ELSE 5 END, title ASC`,
+		Vars: []interface{}{
+			keyword, keyword, keyword,
+			keyword, py, py, py,
+			prefix, prefix, py, py + "%", py + "%",
+			like, like,
+			py, "%" + py + "%",
+		},
+		WithoutParentheses: true,
+	}}
+}
+
+// ResolveUniversity 把用户输入的高校标识解析为唯一的高校，并报告命中的标识类型
+// 依次尝试：简称/中文名/识别码完全相同、英文全称（不区分大小写）、官网域名、有效别名（曾用名、简称、英文别名）、
+// 全拼完全相同、首字母完全相同；同一优先级有多个时取资源最多的。已停用的高校不参与解析，找不到时返回 gorm.ErrRecordNotFound
+func ResolveUniversity(name string) (dto.UniversityMatch, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return dto.UniversityMatch{}, gorm.ErrRecordNotFound
+	}
+	py := util.NormalizePinyinQuery(name)
+	var rows []struct {
+		Slug      string
+		ShortName string
+		Title     string
+		MatchRank int
+	}
+	err := db.Table("university").
+		Select(`slug, short_name, title, CASE
+	WHEN short_name = ? OR title = ? OR slug = ? THEN 0
+	WHEN full_name_en = ? THEN 1
+	WHEN slug IN (`+activeAliasSlugs+`) THEN 2
+	WHEN pinyin = ? THEN 3
+	ELSE 4 END AS match_rank`, name, name, name, name, name, py).
+		Where("is_deleted = ?", model.UniversityIsActive).
+		Where("short_name = ? OR title = ? OR slug = ? OR full_name_en = ? OR slug IN ("+activeAliasSlugs+") OR (? <> '' AND (pinyin = ? OR initials = ?))",
+			name, name, name, name, name, py, py, py).
+		Order("match_rank, resource_count DESC").
+		Limit(1).
+		Scan(&rows).Error
+	if err != nil {
+		zap.L().Error("mysql.ResolveUniversity() failed", zap.String("name", name), zap.Error(err))
+		return dto.UniversityMatch{}, err
+	}
+	// 简称、中文名、识别码、英文全称优先于官网域名
+	if len(rows) > 0 && rows[0].MatchRank <= 1 {
+		r := rows[0]
+		return dto.UniversityMatch{Slug: r.Slug, ShortName: r.ShortName, Title: r.Title, MatchedBy: exactMatchField(name, r.ShortName, r.Title, r.Slug, r.MatchRank)}, nil
+	}
+	if host := util.HostFromURL(name); host != "" {
+		u, found, err := matchUniversityByHost(host)
+		if err != nil {
+			return dto.UniversityMatch{}, err
+		}
+		if found {
+			return dto.UniversityMatch{Slug: u.Slug, ShortName: u.ShortName, Title: u.Title, MatchedBy: model.MatchByWebsite}, nil
+		}
+	}
+	if len(rows) == 0 {
+		return dto.UniversityMatch{}, gorm.ErrRecordNotFound
+	}
+	r := rows[0]
+	matchedBy := map[int]string{2: model.MatchByAlias, 3: model.MatchByPinyin, 4: model.MatchByInitials}[r.MatchRank]
+	return dto.UniversityMatch{Slug: r.Slug, ShortName: r.ShortName, Title: r.Title, MatchedBy: matchedBy}, nil
+}
+
+// exactMatchField 判断完全匹配命中的是哪个字段
+func exactMatchField(name, shortName, title, slug string, rank int) string {
+	switch {
+	case rank == 1:
+		return model.MatchByFullNameEn
+	case strings.EqualFold(name, shortName):
+		return model.MatchByShortName
+	case name == slug:
+		return model.MatchBySlug
+	default:
+		return model.MatchByTitle
+	}
+}
+
+// matchUniversityByHost 按官网域名匹配：输入的主机名与 website 的主机名相同或是其子域名（如 lib.pku.edu.cn）
+// 从完整主机名开始逐级去掉子域名查找，同一域名有多所高校时取资源最多的
+func matchUniversityByHost(host string) (do.University, bool, error) {
+	labels := strings.Split(host, ".")
+	for i := 0; i+2 <= len(labels); i++ {
+		suffix := strings.Join(labels[i:], ".")
+		var candidates []do.University
+		if err := db.Table("university").
+			Select("slug, short_name, title, website, resource_count").
+			Where("is_deleted = ?", model.UniversityIsActive).
+			Where("website LIKE ? OR website LIKE ? OR website LIKE ? OR website LIKE ?",
+				"%//"+suffix+"%", "%//www."+suffix+"%", suffix+"%", "www."+suffix+"%").
+			Order("resource_count DESC").
+			Find(&candidates).Error; err != nil {
+			zap.L().Error("mysql.matchUniversityByHost() failed", zap.String("host", host), zap.Error(err))
+			return do.University{}, false, err
+		}
+		for _, c := range candidates {
+			if util.HostFromURL(c.Website) == suffix && util.HostMatches(host, suffix) {
+				return c, true, nil
+			}
+		}
+	}
+	return do.University{}, false, nil
+}
+
+// GetUniversityNames 查询全部有效高校可用于名称建议的字段
+func GetUniversityNames() ([]do.University, error) {
+	var universities []do.University
+	if err := db.Table("university").
+		Select("slug, short_name, title, full_name_en, pinyin, initials, resource_count").
+		Where("is_deleted = ?", model.UniversityIsActive).
+		Find(&universities).Error; err != nil {
+		zap.L().Error("mysql.GetUniversityNames() failed", zap.Error(err))
+		return nil, err
+	}
+	return universities, nil
+}
+
+// UpdateUniversityPinyin 更新高校的全拼和首字母
+func UpdateUniversityPinyin(slug, pinyin, initials string) error {
+	if err := db.Table("university").Where("slug = ?", slug).
+		UpdateColumns(map[string]interface{}{"pinyin": pinyin, "initials": initials}).Error; err != nil {
+		zap.L().Error("mysql.UpdateUniversityPinyin() failed", zap.String("slug", slug), zap.Error(err))
+		return err
+	}
+	return nil
+}
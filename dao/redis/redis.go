@@ -30,8 +30,15 @@ const (
 	CacheKeyPrefix    = "logo_cache:"        // key1: hash -> cosPath
 	ReverseKeyPrefix  = "logo_cos_to_key:"   // key2: cosPath -> hash
 	PendingDeleteZSET = "cos_pending_delete" // ZSET: cosPath -> expireTime
+	CacheSizeHash     = "logo_cache_size"    // HASH: cosPath -> 文件字节数 (用于缓存统计)
 )
 
+// PendingDeleteEntry 待删除集合中的一个成员
+type PendingDeleteEntry struct {
+	EncodedPath string    // ZSET 中存储的 ENCODED 路径
+	ExpireAt    time.Time // 过期时间 (score)
+}
+
 func Init(config *settings.RedisConfig) (err error) {
 	rdb = redis.NewClient(&redis.Options{
 		Addr:     fmt.Sprintf("%s:%d", config.Host, config.Port),
@@ -101,13 +108,17 @@ func AddPendingDelete(ctx context.Context, cosPath string, expireAt time.Time) e
 	}).Err()
 }
 
-// GetExpiredPendingDeletePaths 返回所有已经过期的待删除路径（修改：返回 ENCODED 路径）
-func GetExpiredPendingDeletePaths(ctx context.Context, now time.Time) ([]string, error) {
+// GetExpiredPendingDeletePaths 返回已经过期的待删除路径（修改：返回 ENCODED 路径），按过期时间升序，limit <= 0 表示不限制数量
+func GetExpiredPendingDeletePaths(ctx context.Context, now time.Time, limit int64) ([]string, error) {
 	score := float64(now.Unix())
-	encodedPaths, err := rdb.ZRangeByScore(ctx, PendingDeleteZSET, &redis.ZRangeBy{
+	opt := &redis.ZRangeBy{
 		Min: "-inf",
 		Max: strconv.FormatFloat(score, 'f', 0, 64),
-	}).Result()
+	}
+	if limit > 0 {
+		opt.Count = limit
+	}
+	encodedPaths, err := rdb.ZRangeByScore(ctx, PendingDeleteZSET, opt).Result()
 	if err != nil {
 		zap.L().Error("rdb.ZRangeByScore() failed", zap.Error(err))
 		return nil, err
@@ -129,6 +140,238 @@ func RemovePendingDeletePaths(ctx context.Context, paths ...string) error {
 	return rdb.ZRem(ctx, PendingDeleteZSET, interfaceSlice...).Err()
 }
 
+// ListPendingDelete 返回待删除集合中的全部成员及其过期时间，按过期时间升序
+func ListPendingDelete(ctx context.Context) ([]PendingDeleteEntry, error) {
+	zs, err := rdb.ZRangeWithScores(ctx, PendingDeleteZSET, 0, -1).Result()
+	if err != nil {
+		zap.L().Error("rdb.ZRangeWithScores() failed", zap.Error(err))
+		return nil, err
+	}
+	entries := make([]PendingDeleteEntry, 0, len(zs))
+	for _, z := range zs {
+		member, ok := z.Member.(string)
+		if !ok {
+			continue
+		}
+		entries = append(entries, PendingDeleteEntry{
+			EncodedPath: member,
+			ExpireAt:    time.Unix(int64(z.Score), 0),
+		})
+	}
+	return entries, nil
+}
+
+// GetReverseMappings 批量查找 COS 路径对应的 hash (Key 2)，未命中的路径不会出现在结果中
+func GetReverseMappings(ctx context.Context, cosPaths []string) (map[string]string, error) {
+	result := make(map[string]string, len(cosPaths))
+	if len(cosPaths) == 0 {
+		return result, nil
+	}
+	keys := make([]string, len(cosPaths))
+	for i, p := range cosPaths {
+		keys[i] = ReverseKeyPrefix + url.QueryEscape(p)
+	}
+	values, err := rdb.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for i, v := range values {
+		if cacheKey, ok := v.(string); ok {
+			result[cosPaths[i]] = cacheKey
+		}
+	}
+	return result, nil
+}
+
+// RemoveCacheEntries 使用 Pipeline 一次性删除一批缓存的 Key 1、Key 2、ZSET 成员以及大小记录
+// cacheKeys 为 Key 1 的 hash，encodedPaths 为 ZSET 中存储的 ENCODED 路径
+func RemoveCacheEntries(ctx context.Context, cacheKeys []string, encodedPaths []string) error {
+	if len(cacheKeys) == 0 && len(encodedPaths) == 0 {
+		return nil
+	}
+	pipe := rdb.Pipeline()
+	for _, cacheKey := range cacheKeys {
+		pipe.Del(ctx, CacheKeyPrefix+cacheKey)
+	}
+	if len(encodedPaths) > 0 {
+		members := make([]interface{}, len(encodedPaths))
+		for i, p := range encodedPaths {
+			pipe.Del(ctx, ReverseKeyPrefix+p)
+			members[i] = p
+		}
+		pipe.ZRem(ctx, PendingDeleteZSET, members...)
+		pipe.HDel(ctx, CacheSizeHash, encodedPaths...)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Cache Size: COS 路径 -> 文件字节数
+
+// SetCacheSize 记录缓存文件的大小
+func SetCacheSize(ctx context.Context, cosPath string, size int64) error {
+	return rdb.HSet(ctx, CacheSizeHash, url.QueryEscape(cosPath), size).Err()
+}
+
+// GetCacheSizes 返回全部缓存文件的大小，key 为 ENCODED 路径
+func GetCacheSizes(ctx context.Context) (map[string]int64, error) {
+	raw, err := rdb.HGetAll(ctx, CacheSizeHash).Result()
+	if err != nil {
+		return nil, err
+	}
+	sizes := make(map[string]int64, len(raw))
+	for encodedPath, v := range raw {
+		size, parseErr := strconv.ParseInt(v, 10, 64)
+		if parseErr != nil {
+			continue
+		}
+		sizes[encodedPath] = size
+	}
+	return sizes, nil
+}
+
+// DeleteCacheSizes 删除缓存文件的大小记录（接收 ENCODED 路径）
+func DeleteCacheSizes(ctx context.Context, encodedPaths ...string) error {
+	if len(encodedPaths) == 0 {
+		return nil
+	}
+	return rdb.HDel(ctx, CacheSizeHash, encodedPaths...).Err()
+}
+
+// RenameCachePath 对象从 oldPath 迁移到 newPath 后，改写 Key 1 的值、Key 2、ZSET 成员以及大小记录
+// 旧路径没有缓存记录时不做任何修改
+func RenameCachePath(ctx context.Context, oldPath, newPath string) error {
+	oldEncoded, newEncoded := url.QueryEscape(oldPath), url.QueryEscape(newPath)
+	cacheKey, err := rdb.Get(ctx, ReverseKeyPrefix+oldEncoded).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+	score, err := rdb.ZScore(ctx, PendingDeleteZSET, oldEncoded).Result()
+	hasScore := err == nil
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+	size, err := rdb.HGet(ctx, CacheSizeHash, oldEncoded).Result()
+	hasSize := err == nil
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+	if cacheKey == "" && !hasScore && !hasSize {
+		return nil
+	}
+
+	pipe := rdb.TxPipeline()
+	if cacheKey != "" {
+		pipe.Set(ctx, CacheKeyPrefix+cacheKey, newPath, 0)
+		pipe.Set(ctx, ReverseKeyPrefix+newEncoded, cacheKey, 0)
+		pipe.Del(ctx, ReverseKeyPrefix+oldEncoded)
+	}
+	if hasScore {
+		pipe.ZAdd(ctx, PendingDeleteZSET, redis.Z{Score: score, Member: newEncoded})
+		pipe.ZRem(ctx, PendingDeleteZSET, oldEncoded)
+	}
+	if hasSize {
+		pipe.HSet(ctx, CacheSizeHash, newEncoded, size)
+		pipe.HDel(ctx, CacheSizeHash, oldEncoded)
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// 分布式锁：保证同一时刻只有一个清理/对账/重命名任务在运行
+
+const (
+	CleanerLockKey    = "lock:cos_cleaner"
+	ReconcileLockKey  = "lock:reconcile"
+	TrashPurgeLockKey = "lock:trash_purge"
+	// UploadCleanupLockKey 清理被放弃的直传暂存对象和分块上传
+	UploadCleanupLockKey = "lock:upload_cleanup"
+	// RenameJobLockPrefix 重命名任务锁，完整 key 为 lock:rename_job:<id>
+	RenameJobLockPrefix = "lock:rename_job:"
+	// ChunkUploadLockPrefix 分块上传会话锁，保证同一会话的分块串行写入，完整 key 为 lock:chunk_upload:<id>
+	ChunkUploadLockPrefix = "lock:chunk_upload:"
+	// PresignUploadLockPrefix 预签名直传完成回调锁，防止重复登记，完整 key 为 lock:presign_upload:<id>
+	PresignUploadLockPrefix = "lock:presign_upload:"
+	// ResourceNameLockPrefix 资源名称占用，从上传开始到写库结束期间防止同名上传覆盖 COS 对象，完整 key 为 lock:resource_name:<shortName>/<name>
+	ResourceNameLockPrefix = "lock:resource_name:"
+)
+
+// releaseLockScript 仅当锁的持有者是自己时才删除，防止误删他人在锁过期后重新获取的锁
+var releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// extendLockScript 仅当锁的持有者是自己时才延长过期时间
+var extendLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+// AcquireLock 尝试获取锁 (SET NX PX)，token 用于标识持有者
+func AcquireLock(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	return rdb.SetNX(ctx, key, token, ttl).Result()
+}
+
+// ReleaseLock 释放自己持有的锁
+func ReleaseLock(ctx context.Context, key, token string) error {
+	return releaseLockScript.Run(ctx, rdb, []string{key}, token).Err()
+}
+
+// LockHeld 锁当前是否被任意持有者持有
+func LockHeld(ctx context.Context, key string) (bool, error) {
+	n, err := rdb.Exists(ctx, key).Result()
+	return n > 0, err
+}
+
+// ExtendLock 延长自己持有的锁，返回 false 表示锁已过期或被他人持有
+func ExtendLock(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	n, err := extendLockScript.Run(ctx, rdb, []string{key}, token, ttl.Milliseconds()).Int()
+	return n == 1, err
+}
+
+// 分块上传会话：进度和 md5 中间状态以 JSON 保存，过期后视为放弃
+
+// ChunkUploadKeyPrefix 分块上传会话，完整 key 为 chunk_upload:<id>
+const ChunkUploadKeyPrefix = "chunk_upload:"
+
+// SetChunkUpload 保存分块上传会话，每次写入都会刷新过期时间
+func SetChunkUpload(ctx context.Context, id string, data []byte, ttl time.Duration) error {
+	return rdb.Set(ctx, ChunkUploadKeyPrefix+id, data, ttl).Err()
+}
+
+// GetChunkUpload 读取分块上传会话，不存在时返回 redis.Nil
+func GetChunkUpload(ctx context.Context, id string) ([]byte, error) {
+	return rdb.Get(ctx, ChunkUploadKeyPrefix+id).Bytes()
+}
+
+// DeleteChunkUpload 删除分块上传会话
+func DeleteChunkUpload(ctx context.Context, id string) error {
+	return rdb.Del(ctx, ChunkUploadKeyPrefix+id).Err()
+}
+
+// 预签名直传：签发上传地址时保存资源元数据，完成回调时取出登记
+
+// PresignUploadKeyPrefix 预签名直传凭据，完整 key 为 presign_upload:<id>
+const PresignUploadKeyPrefix = "presign_upload:"
+
+// SetPresignUpload 保存预签名直传凭据
+func SetPresignUpload(ctx context.Context, id string, data []byte, ttl time.Duration) error {
+	return rdb.Set(ctx, PresignUploadKeyPrefix+id, data, ttl).Err()
+}
+
+// GetPresignUpload 读取预签名直传凭据，不存在时返回 redis.Nil
+func GetPresignUpload(ctx context.Context, id string) ([]byte, error) {
+	return rdb.Get(ctx, PresignUploadKeyPrefix+id).Bytes()
+}
+
+// DeletePresignUpload 删除预签名直传凭据
+func DeletePresignUpload(ctx context.Context, id string) error {
+	return rdb.Del(ctx, PresignUploadKeyPrefix+id).Err()
+}
+
 // 为用户Token黑名单新增方法
 
 const (
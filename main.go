@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/gin-gonic/gin"
-	"github.com/spf13/viper"
 	"github.com/tencentyun/scf-go-lib/cloudfunction"
 	"github.com/tencentyun/scf-go-lib/events" // 这里才有 APIGatewayRequest
 	"go.uber.org/zap"
@@ -13,11 +12,11 @@ import (
 	"logo_api/dao/mysql"
 	"logo_api/dao/redis"
 	"logo_api/logger"
+	"logo_api/model/cos/dto"
 	"logo_api/routes"
 	"logo_api/service"
 	"logo_api/settings"
 	"logo_api/util"
-	"net/http"
 	"os"
 	"strings"
 	"time"
@@ -81,11 +80,63 @@ func init() {
 		}()*/
 }
 
-// Handler 是云函数的入口, 它就是个空壳，Web 函数只走 Gin 路由，Handler 只是占位 + Timer 清理
+// Handler 是云函数的入口。Web 函数只走 Gin 路由，Handler 负责分发定时触发器事件：
+// Timer 事件先继续未完成的重命名任务、清理回收站中过期的资源和被放弃的上传，再直接调用 ResourceService.CleanExpiredCOSObjects，不再绕一圈 HTTP 请求 /clearCache
 func Handler(ctx context.Context, evt json.RawMessage) (interface{}, error) {
+	var timerEvent events.TimerEvent
+	if err := json.Unmarshal(evt, &timerEvent); err == nil && timerEvent.Type == "Timer" {
+		zap.L().Info("Received timer event", zap.String("trigger", timerEvent.TriggerName), zap.String("time", timerEvent.Time))
+		svc.ResumeRenameJobs(ctx)
+		runTrashPurge(ctx)
+		runUploadCleanup(ctx)
+		return runCacheCleaner(ctx)
+	}
 	return events.APIGatewayResponse{}, nil
 }
 
+// runCacheCleaner 执行一次过期缓存清理，供 SCF 定时触发器和本地定时任务共用
+func runCacheCleaner(ctx context.Context) (*dto.CleanResultDTO, error) {
+	result, err := svc.CleanExpiredCOSObjects(ctx)
+	if err != nil {
+		zap.L().Error("CleanExpiredCOSObjects failed", zap.Error(err))
+		return nil, err
+	}
+	zap.L().Info("CleanExpiredCOSObjects finished",
+		zap.Bool("skipped", result.Skipped),
+		zap.Int("total", result.Total),
+		zap.Int("success", result.SuccessCount),
+		zap.Int("fail", result.FailCount))
+	return result, nil
+}
+
+// runTrashPurge 彻底删除回收站中超过保留期的资源，失败只记录日志，不影响后续的缓存清理
+func runTrashPurge(ctx context.Context) {
+	result, err := svc.PurgeExpiredTrash(ctx)
+	if err != nil {
+		zap.L().Error("PurgeExpiredTrash failed", zap.Error(err))
+		return
+	}
+	zap.L().Info("PurgeExpiredTrash finished",
+		zap.Bool("skipped", result.Skipped),
+		zap.Int("total", result.Total),
+		zap.Int("deleted", result.Deleted),
+		zap.Int("failed", result.Failed))
+}
+
+// runUploadCleanup 清理被放弃的直传暂存对象和分块上传，失败只记录日志，不影响后续的缓存清理
+func runUploadCleanup(ctx context.Context) {
+	result, err := svc.CleanAbandonedUploads(ctx)
+	if err != nil {
+		zap.L().Error("CleanAbandonedUploads failed", zap.Error(err))
+		return
+	}
+	zap.L().Info("CleanAbandonedUploads finished",
+		zap.Bool("skipped", result.Skipped),
+		zap.Int("stagingDeleted", result.StagingDeleted),
+		zap.Int("uploadsAborted", result.UploadsAborted),
+		zap.Int("errors", len(result.Errors)))
+}
+
 func main() {
 	runMode := strings.ToLower(os.Getenv("RUN_MODE"))
 	// 临时调试代码
@@ -101,30 +152,27 @@ func main() {
 		zap.L().Info("starting server")
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
-		// 定时任务 Goroutine
+		// 定时任务 Goroutine：直接调用清理逻辑，分布式锁保证不会与其他实例重复清理
 		go func() {
-			// 确保服务器已经启动并监听，使用 settings 中的配置或默认值
-			// 等待 2 秒，确保下面的服务器先跑起来
-			time.Sleep(2 * time.Second)
-			host := viper.GetString("app.host") // 假设 host 在 settings 中配置
-			if host == "" {
-				host = "localhost"
-			}
-
-			clearCacheURL := fmt.Sprintf("http://%s:%s/clearCache", host, port)
 			ticker := time.NewTicker(1 * time.Hour) // 调试，每1h删除一次缓存
 			//ticker := time.NewTicker(1 * time.Minute) // 调试，每1min删除一次缓存
 			defer ticker.Stop()
 
-			// 立即执行一次清理（可选）
-			zap.L().Info("Starting initial cache cleanup request.")
-			triggerClearCache(clearCacheURL)
+			// 启动时继续上次未完成的重命名任务，并立即执行一次清理（可选）
+			svc.ResumeRenameJobs(ctx)
+			runTrashPurge(ctx)
+			runUploadCleanup(ctx)
+			zap.L().Info("Starting initial cache cleanup.")
+			_, _ = runCacheCleaner(ctx)
 
 			for {
 				select {
 				case <-ticker.C:
-					zap.L().Info("Sending request to clearCache route.")
-					triggerClearCache(clearCacheURL)
+					svc.ResumeRenameJobs(ctx)
+					runTrashPurge(ctx)
+					runUploadCleanup(ctx)
+					zap.L().Info("Starting scheduled cache cleanup.")
+					_, _ = runCacheCleaner(ctx)
 				case <-ctx.Done():
 					return
 				}
@@ -164,35 +212,3 @@ func main() {
 		cloudfunction.Start(Handler)
 	}
 }
-
-// 辅助函数：发送 HTTP POST 请求到 /clearCache
-func triggerClearCache(url string) {
-	// 创建一个新的 POST 请求
-	req, err := http.NewRequest(http.MethodPost, url, nil)
-	if err != nil {
-		zap.L().Error("Failed to create clearCache request", zap.Error(err))
-		return
-	}
-
-	// 设置超时客户端
-	client := http.Client{
-		Timeout: 1 * time.Minute, // 同样给 1 分钟的超时时间
-	}
-
-	// 发送请求
-	resp, err := client.Do(req)
-	if err != nil {
-		zap.L().Error("Failed to send clearCache request", zap.String("url", url), zap.Error(err))
-		return
-	}
-	defer resp.Body.Close()
-
-	// 检查响应状态码
-	if resp.StatusCode != http.StatusOK {
-		zap.L().Error("clearCache route returned non-200 status",
-			zap.String("url", url),
-			zap.Int("status", resp.StatusCode))
-	} else {
-		zap.L().Info("Successfully triggered clearCache route", zap.String("url", url))
-	}
-}
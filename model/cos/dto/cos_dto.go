@@ -1,9 +1,82 @@
 package dto
 
+import "time"
+
 // CleanResultDTO Clean 任务的返回结果
 type CleanResultDTO struct {
 	Total        int      `json:"total"`
 	SuccessCount int      `json:"success_count"`
 	FailCount    int      `json:"fail_count"`
 	FailedPaths  []string `json:"failed_paths"`
+	Skipped      bool     `json:"skipped"`   // 其他清理任务正在运行，本次跳过
+	Truncated    bool     `json:"truncated"` // 达到单次上限或超时，剩余部分留到下一次清理
+}
+
+// UploadCleanResultDTO 清理被放弃的上传的结果
+type UploadCleanResultDTO struct {
+	StagingDeleted int      `json:"stagingDeleted"` // 删除的直传暂存对象数
+	UploadsAborted int      `json:"uploadsAborted"` // 放弃的分块上传数
+	Skipped        bool     `json:"skipped"`        // 其他清理任务正在运行，本次跳过
+	Errors         []string `json:"errors"`
+}
+
+// CacheEntryDTO 一个已缓存的转换结果（位图变体）
+type CacheEntryDTO struct {
+	CosPath   string    `json:"cosPath"`
+	ShortName string    `json:"shortName"`
+	Name      string    `json:"name"`
+	Format    string    `json:"format"`
+	CacheKey  string    `json:"cacheKey"` // Key 1 的 hash，反向映射缺失时为空
+	Size      int64     `json:"size"`     // 字节数，未记录时为 0
+	ExpireAt  time.Time `json:"expireAt"`
+	Expired   bool      `json:"expired"` // 已过期，等待下一次清理
+}
+
+// CacheListReq /admin/cache/list 请求参数，字段均为可选过滤条件
+type CacheListReq struct {
+	ShortName string `json:"shortName"`
+	Format    string `json:"format"`
+}
+
+// CachePurgeReq /admin/cache/purge 请求参数，三者至少指定一个
+type CachePurgeReq struct {
+	ShortName string `json:"shortName"`
+	Format    string `json:"format"`
+	All       bool   `json:"all"`
+}
+
+// ReconcileReq /admin/reconcile 请求参数，Fix* 均为 false 时只生成报告（dry run）
+type ReconcileReq struct {
+	ShortName        string `json:"shortName"`        // 仅检查指定高校，为空表示全部
+	FixOrphans       bool   `json:"fixOrphans"`       // 删除没有被资源表和缓存引用的 COS 对象
+	FixMissing       bool   `json:"fixMissing"`       // 将文件丢失的资源标记为已删除
+	FixMd5           bool   `json:"fixMd5"`           // 以 COS 中的实际文件修正资源的 md5/size
+	FixDanglingCache bool   `json:"fixDanglingCache"` // 清理指向不存在对象的缓存记录
+}
+
+// ReconcileObjectDTO 一个未被引用的 COS 对象
+type ReconcileObjectDTO struct {
+	CosPath string `json:"cosPath"`
+	Size    int64  `json:"size"`
+	ETag    string `json:"etag"`
+}
+
+// ReconcileResourceDTO 一个与 COS 不一致的资源记录
+type ReconcileResourceDTO struct {
+	ID         int    `json:"id"`
+	ShortName  string `json:"shortName"`
+	Name       string `json:"name"`
+	IsDeleted  int    `json:"isDeleted"`
+	Md5        string `json:"md5"`
+	Size       int    `json:"size"`
+	ActualMd5  string `json:"actualMd5,omitempty"`
+	ActualSize int64  `json:"actualSize,omitempty"`
+}
+
+// ReconcileFixedDTO 本次修复的数量
+type ReconcileFixedDTO struct {
+	Orphans       int `json:"orphans"`
+	Missing       int `json:"missing"`
+	Md5Mismatches int `json:"md5Mismatches"`
+	DanglingCache int `json:"danglingCache"`
 }
@@ -0,0 +1,46 @@
+package vo
+
+import (
+	"logo_api/model/cos/dto"
+	"logo_api/util"
+	"time"
+)
+
+// CacheListResp /admin/cache/list 响应
+type CacheListResp struct {
+	List       []dto.CacheEntryDTO `json:"list"`
+	TotalCount int                 `json:"totalCount"`
+	TotalBytes int64               `json:"totalBytes"`
+}
+
+// CacheUniversityStat 单个高校的缓存占用
+type CacheUniversityStat struct {
+	ShortName    string     `json:"shortName"`
+	Count        int        `json:"count"`
+	Bytes        int64      `json:"bytes"`
+	NextExpireAt *time.Time `json:"nextExpireAt"`
+}
+
+// CacheStatsResp /admin/cache/stats 响应
+type CacheStatsResp struct {
+	TotalCount   int                   `json:"totalCount"`
+	TotalBytes   int64                 `json:"totalBytes"`
+	ExpiredCount int                   `json:"expiredCount"` // 已过期、等待清理的数量
+	NextExpireAt *time.Time            `json:"nextExpireAt"` // 最近一个尚未过期条目的过期时间
+	Universities []CacheUniversityStat `json:"universities"`
+	L1           util.ByteCacheStats   `json:"l1"`
+}
+
+// ReconcileResp /admin/reconcile 响应
+type ReconcileResp struct {
+	CheckedObjects   int                        `json:"checkedObjects"`
+	CheckedResources int                        `json:"checkedResources"`
+	CheckedCache     int                        `json:"checkedCache"`
+	Orphans          []dto.ReconcileObjectDTO   `json:"orphans"`       // COS 中存在但没有任何引用
+	MissingFiles     []dto.ReconcileResourceDTO `json:"missingFiles"`  // 资源表中存在但 COS 中没有文件
+	Md5Mismatches    []dto.ReconcileResourceDTO `json:"md5Mismatches"` // COS 文件与资源表记录的 md5/size 不一致
+	DanglingCache    []string                   `json:"danglingCache"` // 缓存记录指向不存在的 COS 对象
+	Fixed            dto.ReconcileFixedDTO      `json:"fixed"`
+	Errors           []string                   `json:"errors"`
+	Warnings         []string                   `json:"warnings"` // 未检查的部分及原因，如正在迁移的高校目录
+}
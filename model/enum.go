@@ -14,8 +14,85 @@ const (
 
 // 资源删除码
 const (
-	ResourceIsActive  int = 0
-	ResourceIsDeleted int = 1
+	ResourceIsActive   int = 0
+	ResourceIsDeleted  int = 1
+	ResourceIsReplaced int = 2 // 已被同一版本链中的新版本替换（历史版本，可回滚）
+)
+
+// 高校删除码
+const (
+	UniversityIsActive  int = 0
+	UniversityIsDeleted int = 1 // 已停用（软删除），可恢复
+)
+
+// 高校 short_name 重命名任务状态
+const (
+	RenameJobPending = "pending" // 已创建，尚未列出需要迁移的对象
+	RenameJobRunning = "running" // 迁移中
+	RenameJobFailed  = "failed"  // 本轮有对象迁移失败，等待重试
+	RenameJobDone    = "done"    // 全部迁移完成
+)
+
+// 重命名任务中单个对象的状态
+const (
+	RenameObjectPending = "pending" // 尚未复制
+	RenameObjectCopied  = "copied"  // 已复制到新路径，旧对象尚未删除
+	RenameObjectDone    = "done"    // 旧对象已删除，缓存记录已改写
+)
+
+// 高校别名类型
+const (
+	AliasFormerName   = "former_name"   // 曾用名（合并、更名前的校名）
+	AliasAbbreviation = "abbreviation"  // 简称，如 北大
+	AliasEnglish      = "english_alias" // 英文别名或缩写，如 PKU
+)
+
+// 高校名称解析命中的标识类型
+const (
+	MatchByShortName  = "shortName"
+	MatchByTitle      = "title"
+	MatchBySlug       = "slug"
+	MatchByFullNameEn = "fullNameEn"
+	MatchByWebsite    = "website"
+	MatchByAlias      = "alias"
+	MatchByPinyin     = "pinyin"
+	MatchByInitials   = "initials"
+)
+
+// 批量上传中单个文件的处理结果
+const (
+	BatchItemInserted  = "inserted"  // 已插入
+	BatchItemDuplicate = "duplicate" // 与已有资源或同批文件重复，跳过
+	BatchItemRejected  = "rejected"  // 校验未通过
+	BatchItemFailed    = "failed"    // 上传或写库失败
+)
+
+// 高校批量写入中单行的处理结果
+const (
+	UniversityRowCreated   = "created"   // 已新增
+	UniversityRowUpdated   = "updated"   // 已更新（仅 upsert 模式）
+	UniversityRowUnchanged = "unchanged" // 与现有数据相同，未修改（仅 upsert 模式）
+	UniversityRowConflict  = "conflict"  // 与已有高校、别名或同批数据的唯一键冲突
+	UniversityRowInvalid   = "invalid"   // 校验未通过
+	UniversityRowFailed    = "failed"    // 写库失败
+	UniversityRowSkipped   = "skipped"   // 全部成功才提交模式下其他行失败，本行已回滚
+)
+
+// 资源元数据导入中单行的处理结果
+const (
+	ResourceRowUpdated   = "updated"   // 已更新
+	ResourceRowUnchanged = "unchanged" // 与现有数据相同，未修改
+	ResourceRowInvalid   = "invalid"   // 校验未通过
+	ResourceRowFailed    = "failed"    // 写库失败
+	ResourceRowSkipped   = "skipped"   // 其他行失败，本行已回滚
+)
+
+// 高校批量写入的唯一键冲突类型
+const (
+	UniversityKeySlug      = "slug"
+	UniversityKeyShortName = "shortName"
+	UniversityKeyTitle     = "title"
+	UniversityKeyAlias     = "alias" // 简称或中文名与已有别名相同
 )
 
 // 自定义业务状态码
@@ -23,9 +100,11 @@ const (
 	CodeSuccess         = 200 // 成功
 	CodeInvalidParam    = 400 // 参数错误
 	CodeUnauthorized    = 401 // 未登录
+	CodeForbidden       = 403 // 无权限
 	CodeNotFound        = 404 // 资源不存在
 	CodeUserExist       = 409 // 用户已存在
 	CodeUniversityExist = 410 // 高校已存在
+	CodeConflict        = 411 // 资源状态冲突（任务运行中、数据已被修改等）
 	CodeServerErr       = 500 // 服务器内部错误
 )
 
@@ -33,9 +112,11 @@ const (
 	CodeSuccessStr         string = "Success"
 	CodeInvalidParamStr    string = "Invalid Param"
 	CodeUnauthorizedStr    string = "Unauthorized"
+	CodeForbiddenStr       string = "Forbidden"
 	CodeNotFoundStr        string = "Resource Not Found"
 	CodeUserExistStr       string = "User Already Exists"
 	CodeUniversityExistStr string = "University Already Exists"
+	CodeConflictStr        string = "Conflict"
 	CodeServerErrStr       string = "Internal Server Error"
 )
 
@@ -44,9 +125,11 @@ var codeMsg = map[int]string{
 	CodeSuccess:         CodeSuccessStr,
 	CodeInvalidParam:    CodeInvalidParamStr,
 	CodeUnauthorized:    CodeUnauthorizedStr,
+	CodeForbidden:       CodeForbiddenStr,
 	CodeNotFound:        CodeNotFoundStr,
 	CodeUserExist:       CodeUserExistStr,
 	CodeUniversityExist: CodeUniversityExistStr,
+	CodeConflict:        CodeConflictStr,
 	CodeServerErr:       CodeServerErrStr,
 	StatusActive:        StatusActiveStr,
 	StatusDeleted:       StatusDeletedStr,
@@ -1,8 +1,12 @@
 package model
 
 import (
+	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
 	"net/http"
+	"strings"
 )
 
 // Response 统一响应结构
@@ -52,3 +56,71 @@ func Error(c *gin.Context, code int, customMsg ...string) {
 		Data:    nil,
 	})
 }
+
+// ErrorWithData 错误返回，同时携带帮助调用方处理错误的数据（如找不到时的候选项）
+func ErrorWithData[T any](c *gin.Context, code int, data T, customMsg ...string) {
+	msg := GetMsg(code)
+	if len(customMsg) > 0 {
+		msg = customMsg[0]
+	}
+	c.JSON(http.StatusOK, Response[T]{
+		Code:    code,
+		Message: msg,
+		Data:    data,
+	})
+}
+
+// ErrorWithStatus 错误返回，并使用指定的 HTTP 状态码（用于 If-Match 等依赖 HTTP 语义的场景）
+func ErrorWithStatus[T any](c *gin.Context, status, code int, data T, customMsg ...string) {
+	msg := GetMsg(code)
+	if len(customMsg) > 0 {
+		msg = customMsg[0]
+	}
+	c.JSON(status, Response[T]{
+		Code:    code,
+		Message: msg,
+		Data:    data,
+	})
+}
+
+// FieldError 字段级别的校验错误
+type FieldError struct {
+	Field   string `json:"field"`   // 出错的请求字段
+	Code    string `json:"code"`    // 错误类型，如 required、type_mismatch、too_large
+	Message string `json:"message"` // 可读的错误说明
+}
+
+// ValidationErrorData 参数校验失败时返回的 Data
+type ValidationErrorData struct {
+	Errors []FieldError `json:"errors"`
+}
+
+// ValidationError 参数校验失败，返回每个字段的错误明细
+func ValidationError(c *gin.Context, errs []FieldError) {
+	c.JSON(http.StatusOK, Response[ValidationErrorData]{
+		Code:    CodeInvalidParam,
+		Message: GetMsg(CodeInvalidParam),
+		Data:    ValidationErrorData{Errors: errs},
+	})
+}
+
+// BindingFieldErrors 把 gin 绑定/校验错误转换为字段级别的错误，字段名使用 json/form tag 对应的名称
+func BindingFieldErrors(err error) []FieldError {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return []FieldError{{Field: "", Code: "invalid", Message: err.Error()}}
+	}
+	errs := make([]FieldError, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		field := fe.Field()
+		if field != "" {
+			field = strings.ToLower(field[:1]) + field[1:]
+		}
+		message := fmt.Sprintf("%s failed on the '%s' rule", field, fe.Tag())
+		if fe.Param() != "" {
+			message = fmt.Sprintf("%s failed on the '%s=%s' rule", field, fe.Tag(), fe.Param())
+		}
+		errs = append(errs, FieldError{Field: field, Code: fe.Tag(), Message: message})
+	}
+	return errs
+}
@@ -25,4 +25,25 @@ type Resource struct {
 	UsedForEdge     int    `gorm:"column:used_for_edge" json:"usedForEdge"`
 	IsDeleted       int    `gorm:"column:is_deleted" json:"isDeleted"`
 	BackgroundColor string `gorm:"column:background_color" json:"backgroundColor"`
+
+	// DeletedTime 软删除时间，恢复后清空；回收站按它计算保留期
+	DeletedTime *time.Time `gorm:"column:deleted_time" json:"deletedTime"`
+
+	// 版本链：替换上传时新版本继承 RootID，PrevID 指向被替换的版本
+	Version int  `gorm:"column:version;default:1" json:"version"`
+	RootID  *int `gorm:"column:root_id" json:"rootID"`
+	PrevID  *int `gorm:"column:prev_id" json:"prevID"`
+
+	// PHash 感知哈希 (dHash)，位图和 SVG 才有，用于同一高校内的近似重复检测
+	PHash string `gorm:"column:phash" json:"phash"`
+	// Palette 主色及占比 (util.FormatPalette)，用于以图识校
+	Palette string `gorm:"column:palette" json:"palette"`
+}
+
+// ChainID 返回资源所在版本链的 id（第一个版本的资源 id）
+func (r Resource) ChainID() int {
+	if r.RootID != nil {
+		return *r.RootID
+	}
+	return r.ID
 }
@@ -1,8 +1,10 @@
 package dto
 
 import (
+	"go.uber.org/zap"
 	"logo_api/model"
 	"logo_api/model/resource/do"
+	udto "logo_api/model/university/dto"
 	"logo_api/util"
 	"mime/multipart"
 )
@@ -26,6 +28,11 @@ type ResourceInfoDTO struct {
 	IsDeleted       int    `json:"isDeleted"`
 	BackgroundColor string `json:"backgroundColor"`
 	CosURL          string `json:"cosURL"`
+	Version         int    `json:"version"` // 版本号
+	ChainID         int    `json:"chainID"` // 所在版本链 id（第一个版本的资源 id）
+	PrevID          *int   `json:"prevID"`  // 被当前版本替换的上一版本 id
+	PHash           string `json:"phash"`   // 感知哈希，空表示无法计算
+	Palette         string `json:"palette"` // 主色及占比
 }
 type ResourceGetLogoReq struct {
 	Name    string `json:"name" binding:"required"`     // short_name / title sdut or 山东理工大学
@@ -36,10 +43,51 @@ type ResourceGetLogoReq struct {
 	BgColor string `json:"bgColor" binding:"omitempty"` // bg_color
 }
 
+// ResourceGetListReq /resource/list 请求参数，筛选条件为空表示不限
+// cursor 不为空时按游标翻页（忽略 page），游标只能配合生成它时的排序方式使用
 type ResourceGetListReq struct {
-	Name      string `json:"name" binding:"required"` // 模糊匹配 title 或者 short_name
+	Name      string `json:"name"` // 模糊匹配 title 或者 short_name
 	SortBy    string `json:"sortBy" binding:"omitempty,oneof=id name size type lastUpdateTime"`
 	SortOrder string `json:"sortOrder" binding:"omitempty,oneof=asc desc"`
+	Page      int    `json:"page"`
+	PageSize  int    `json:"pageSize"`
+	Cursor    string `json:"cursor"` // 上一页响应中的 nextCursor
+
+	Types           []string `json:"types"`                                     // 资源类型，如 svg、png
+	IsVector        *int     `json:"isVector" binding:"omitempty,oneof=0 1"`    // 是否矢量
+	IsDeleted       *int     `json:"isDeleted" binding:"omitempty,oneof=0 1 2"` // 资源状态，默认只查有效资源
+	UsedForEdge     *int     `json:"usedForEdge" binding:"omitempty,oneof=0 1"` // 是否边缘计算主输入文件
+	MinSize         *int     `json:"minSize" binding:"omitempty,min=0"`         // 文件大小下限(B)，含
+	MaxSize         *int     `json:"maxSize" binding:"omitempty,min=0"`         // 文件大小上限(B)，含
+	BackgroundColor *string  `json:"backgroundColor"`                           // 背景颜色，按归一化后的值精确匹配，空串或 transparent 表示透明
+}
+
+// ResourceExportReq /resource/export 请求参数，筛选条件同 /resource/list，忽略分页、游标和排序（按 id 升序）
+type ResourceExportReq struct {
+	Format string `json:"format" binding:"required,oneof=csv xlsx json"`
+	ResourceGetListReq
+}
+
+// ResourceImportReq /resource/import 请求参数（multipart 表单），按 id 修改已有资源的元数据，不涉及文件本身
+// format、mapping 同 /university/import，可导入的字段为 id（必需）、usedForEdge 和 backgroundColor；
+// dryRun 默认为 true，只返回逐行 diff 预览；为 false 时在一个事务中写入，任意一行不成功则整批回滚
+type ResourceImportReq struct {
+	File    *multipart.FileHeader `form:"file" binding:"required"`
+	Format  string                `form:"format" binding:"omitempty,oneof=csv xlsx json"`
+	Mapping string                `form:"mapping" binding:"omitempty"`
+	DryRun  *bool                 `form:"dryRun"`
+}
+
+// ResourceRowResult 资源元数据导入中单行的处理结果
+type ResourceRowResult struct {
+	Index   int                `json:"index"` // 在数据行中的下标
+	Line    int                `json:"line"`  // 对应源文件中的行号
+	ID      int                `json:"id"`
+	Name    string             `json:"name,omitempty"`
+	Status  string             `json:"status"`           // 见 model.ResourceRow*
+	Errors  []model.FieldError `json:"errors,omitempty"` // 校验错误明细
+	Message string             `json:"message,omitempty"`
+	Changes []udto.FieldChange `json:"changes,omitempty"` // 发生变化的字段
 }
 
 type ResourceGetReq struct {
@@ -77,6 +125,12 @@ func (req ResourceInsertReq) ToEntity() (*do.Resource, error) {
 	// 2. 获取图片信息 (宽高、类型)
 	w, h, isVec, isBit := util.GetImageInfo(req.File)
 
+	// 3. 检索特征（感知哈希、主色），失败（如缺少 rsvg-convert）时留空，不影响上传
+	features, err := util.ComputeImageFeatures(req.File, req.Type)
+	if err != nil {
+		zap.L().Warn("util.ComputeImageFeatures() failed", zap.String("name", req.Name), zap.Error(err))
+	}
+
 	return &do.Resource{
 		Title:           req.Title,
 		ShortName:       req.ShortName,
@@ -91,5 +145,172 @@ func (req ResourceInsertReq) ToEntity() (*do.Resource, error) {
 		UsedForEdge:     req.UsedForEdge,
 		BackgroundColor: req.BackgroundColor,
 		IsDeleted:       model.ResourceIsActive,
+		PHash:           features.PHash,
+		Palette:         features.Palette,
 	}, nil
 }
+
+// ResourceReplaceReq 上传新版本替换现有资源；name 为空时按版本号自动生成，usedForEdge/backgroundColor 为空时沿用旧版本
+type ResourceReplaceReq struct {
+	ID              int                   `form:"id" binding:"required"`   // 被替换的当前有效版本 id
+	File            *multipart.FileHeader `form:"file" binding:"required"` // 文件流
+	Name            string                `form:"name" binding:"omitempty"`
+	Type            string                `form:"type" binding:"required"`
+	UsedForEdge     *int                  `form:"usedForEdge" binding:"omitempty,oneof=0 1"`
+	BackgroundColor string                `form:"backgroundColor" binding:"omitempty"`
+}
+
+// ResourceHistoryReq 查询资源的版本历史，id 为链中任意版本
+type ResourceHistoryReq struct {
+	ID int `json:"id" binding:"required"`
+}
+
+// ResourceDiffReq 比较同一版本链中两个版本的元数据
+type ResourceDiffReq struct {
+	FromID int `json:"fromID" binding:"required"`
+	ToID   int `json:"toID" binding:"required"`
+}
+
+// ResourceRollbackReq 回滚到版本链中的某个历史版本
+type ResourceRollbackReq struct {
+	ID int `json:"id" binding:"required"`
+}
+
+// ResourceFieldChange 两个版本之间某个字段的差异
+type ResourceFieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// TrashListReq 查询回收站，shortName 为空表示全部高校
+type TrashListReq struct {
+	ShortName string `json:"shortName"`
+}
+
+// TrashDeleteReq 从回收站彻底删除资源（同时删除 COS 对象）
+type TrashDeleteReq struct {
+	IDs []int `json:"ids" binding:"required,min=1"`
+}
+
+// TrashPurgeResultDTO 彻底删除的执行结果
+type TrashPurgeResultDTO struct {
+	Total     int      `json:"total"`
+	Deleted   int      `json:"deleted"`
+	Failed    int      `json:"failed"`
+	Skipped   bool     `json:"skipped"`   // 已有其他实例在执行定时清理
+	Truncated bool     `json:"truncated"` // 达到单次上限，还有过期资源待下次清理
+	Errors    []string `json:"errors"`
+}
+
+// ResourceBatchInsertReq 批量上传：zip 压缩包 + 可选的 manifest（JSON 字符串，也可以放在压缩包根目录的 manifest.json 中）
+// shortName/title 为整批文件的默认值，manifest 中的配置优先
+type ResourceBatchInsertReq struct {
+	Archive   *multipart.FileHeader `form:"archive" binding:"required"`
+	Manifest  string                `form:"manifest" binding:"omitempty"`
+	ShortName string                `form:"shortName" binding:"omitempty"`
+	Title     string                `form:"title" binding:"omitempty"`
+}
+
+// BatchManifest 批量上传清单
+type BatchManifest struct {
+	ShortName string               `json:"shortName"` // 默认高校英文简称
+	Title     string               `json:"title"`     // 默认高校中文全称
+	Files     []BatchManifestEntry `json:"files"`
+}
+
+// BatchManifestEntry 清单中单个文件的元数据，未填写的字段使用默认值或由文件推断
+type BatchManifestEntry struct {
+	Path            string `json:"path"` // 压缩包内的路径
+	Name            string `json:"name"` // 资源全称，默认为文件名
+	ShortName       string `json:"shortName"`
+	Title           string `json:"title"`
+	Type            string `json:"type"` // 默认为文件后缀
+	UsedForEdge     int    `json:"usedForEdge"`
+	BackgroundColor string `json:"backgroundColor"`
+}
+
+// ChunkUploadInitReq 初始化分块上传，元数据与 ResourceInsertReq 相同，size 为完整文件的字节数
+type ChunkUploadInitReq struct {
+	Title           string `json:"title" binding:"required"`
+	ShortName       string `json:"shortName" binding:"required"`
+	Name            string `json:"name" binding:"required"`
+	Type            string `json:"type" binding:"required"`
+	UsedForEdge     int    `json:"usedForEdge" binding:"omitempty,oneof=0 1"`
+	BackgroundColor string `json:"backgroundColor" binding:"omitempty"`
+	Size            int64  `json:"size" binding:"required,gt=0"`
+}
+
+// ChunkUploadPartReq 上传一个分块，分块必须按 partNumber 顺序上传（用于增量计算 md5），重传已上传的分块会被忽略
+type ChunkUploadPartReq struct {
+	UploadID   string                `form:"uploadId" binding:"required"`
+	PartNumber int                   `form:"partNumber" binding:"required,min=1"`
+	File       *multipart.FileHeader `form:"file" binding:"required"`
+}
+
+// ChunkUploadIDReq 查询进度、完成或放弃分块上传
+type ChunkUploadIDReq struct {
+	UploadID string `json:"uploadId" binding:"required"`
+}
+
+// PresignUploadReq 申请预签名直传地址，size 和 md5 (hex) 在完成回调时与 COS 中的对象比对
+type PresignUploadReq struct {
+	Title           string `json:"title" binding:"required"`
+	ShortName       string `json:"shortName" binding:"required"`
+	Name            string `json:"name" binding:"required"`
+	Type            string `json:"type" binding:"required"`
+	UsedForEdge     int    `json:"usedForEdge" binding:"omitempty,oneof=0 1"`
+	BackgroundColor string `json:"backgroundColor" binding:"omitempty"`
+	Size            int64  `json:"size" binding:"required,gt=0"`
+	Md5             string `json:"md5" binding:"required,len=32,hexadecimal"`
+}
+
+// PresignCompleteReq 直传完成回调
+type PresignCompleteReq struct {
+	UploadID string `json:"uploadId" binding:"required"`
+}
+
+// PresignDownloadReq 申请资源的预签名下载地址
+type PresignDownloadReq struct {
+	ID int `json:"id" binding:"required"`
+}
+
+// SimilarResource 与目标感知哈希相近的资源，distance 为汉明距离（0 表示几乎相同）
+type SimilarResource struct {
+	ID        int    `json:"id"`
+	ShortName string `json:"shortName"`
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+	Distance  int    `json:"distance"`
+}
+
+// SimilarResourceReq 查询与某个资源相似的资源；threshold 为 0 时使用配置值，allUniversities 为 true 时跨高校查询
+type SimilarResourceReq struct {
+	ID              int  `json:"id" binding:"required"`
+	Threshold       int  `json:"threshold" binding:"omitempty,min=0,max=64"`
+	AllUniversities bool `json:"allUniversities"`
+}
+
+// FeatureBackfillReq 为存量资源补算检索特征（感知哈希、主色），limit 为单次处理的最大数量
+// afterId 为游标，只处理 id 大于它的资源；传入上次返回的 lastId 可跳过已处理（包括补算失败）的资源继续向后
+type FeatureBackfillReq struct {
+	Limit   int `json:"limit" binding:"omitempty,min=1,max=1000"`
+	AfterID int `json:"afterId" binding:"omitempty,min=0"`
+}
+
+// FeatureBackfillResultDTO 补算结果，lastId 为本次扫描到的最后一个资源 id，没有扫描到资源时等于请求的 afterId
+type FeatureBackfillResultDTO struct {
+	Scanned int `json:"scanned"`
+	Updated int `json:"updated"`
+	Failed  int `json:"failed"`
+	LastID  int `json:"lastId"`
+}
+
+// ReverseLookupReq 以图识校：上传一张 logo 图片，type 为空时根据文件内容判断
+type ReverseLookupReq struct {
+	File  *multipart.FileHeader `form:"file" binding:"required"`
+	Type  string                `form:"type" binding:"omitempty"`
+	Limit int                   `form:"limit" binding:"omitempty,min=1,max=20"`
+}
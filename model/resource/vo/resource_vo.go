@@ -1,10 +1,165 @@
 package vo
 
 import (
+	"logo_api/model"
 	"logo_api/model/resource/dto"
+	udto "logo_api/model/university/dto"
 )
 
 type ResourceResp struct {
 	List       []dto.ResourceInfoDTO `json:"list"`
 	TotalCount int                   `json:"totalCount"`
 }
+
+// ResourceListResp /resource/list 响应，nextCursor 为空表示没有下一页
+type ResourceListResp struct {
+	List       []dto.ResourceInfoDTO `json:"list"`
+	TotalCount int                   `json:"totalCount"` // 所有符合筛选条件的资源数量
+	Page       int                   `json:"page"`       // 游标翻页时为 0
+	PageSize   int                   `json:"pageSize"`
+	NextCursor string                `json:"nextCursor"`
+}
+
+// ResourceHistoryResp 版本历史，List 按版本号从新到旧排列
+type ResourceHistoryResp struct {
+	ChainID    int                   `json:"chainID"`
+	ActiveID   int                   `json:"activeID"` // 当前有效版本 id，整条链都已删除时为 0
+	List       []dto.ResourceInfoDTO `json:"list"`
+	TotalCount int                   `json:"totalCount"`
+}
+
+// ResourceDiffResp 两个版本的元数据差异
+type ResourceDiffResp struct {
+	From    dto.ResourceInfoDTO       `json:"from"`
+	To      dto.ResourceInfoDTO       `json:"to"`
+	Changes []dto.ResourceFieldChange `json:"changes"`
+}
+
+// TrashItem 回收站中的资源
+type TrashItem struct {
+	dto.ResourceInfoDTO
+	DeletedTime string `json:"deletedTime"`
+	ExpireAt    string `json:"expireAt"` // 超过该时间会被定时任务彻底删除，未开启自动清理时为空
+}
+
+// TrashListResp 回收站列表
+type TrashListResp struct {
+	List          []TrashItem `json:"list"`
+	TotalCount    int         `json:"totalCount"`
+	RetentionDays int         `json:"retentionDays"` // 保留天数，负数表示不自动清理
+}
+
+// BatchUploadItem 批量上传中单个文件的处理结果
+type BatchUploadItem struct {
+	Path      string                `json:"path"`
+	ShortName string                `json:"shortName"`
+	Name      string                `json:"name"`
+	Status    string                `json:"status"` // inserted / duplicate / rejected / failed
+	ID        int                   `json:"id,omitempty"`
+	Errors    []model.FieldError    `json:"errors,omitempty"`
+	Similar   []dto.SimilarResource `json:"similar,omitempty"` // 近似重复提示
+	Message   string                `json:"message,omitempty"`
+}
+
+// BatchUploadResp 批量上传报告
+type BatchUploadResp struct {
+	Total      int               `json:"total"`
+	Inserted   int               `json:"inserted"`
+	Duplicates int               `json:"duplicates"`
+	Rejected   int               `json:"rejected"`
+	Failed     int               `json:"failed"`
+	Items      []BatchUploadItem `json:"items"`
+}
+
+// ChunkUploadStatusResp 分块上传进度，客户端断线重连后从 nextPart 继续上传
+type ChunkUploadStatusResp struct {
+	UploadID      string `json:"uploadId"`
+	ShortName     string `json:"shortName"`
+	Name          string `json:"name"`
+	Size          int64  `json:"size"`
+	PartSize      int64  `json:"partSize"`
+	TotalParts    int    `json:"totalParts"`
+	ReceivedParts int    `json:"receivedParts"`
+	ReceivedBytes int64  `json:"receivedBytes"`
+	NextPart      int    `json:"nextPart"` // 全部上传完毕时为 0
+	ExpireAt      string `json:"expireAt"`
+}
+
+// UploadCompleteResp 分块上传或预签名直传完成后的结果，status 为 inserted 或 duplicate
+// similar 为同一高校内的近似重复资源（策略为 warn 时仍然写入）
+type UploadCompleteResp struct {
+	Status   string                `json:"status"`
+	Resource dto.ResourceInfoDTO   `json:"resource"`
+	Similar  []dto.SimilarResource `json:"similar"`
+}
+
+// PresignUploadResp 预签名直传地址，客户端需使用 method 并携带 headers 中的全部请求头上传
+type PresignUploadResp struct {
+	UploadID string            `json:"uploadId"`
+	URL      string            `json:"url"`
+	Method   string            `json:"method"`
+	Headers  map[string]string `json:"headers"`
+	ExpireAt string            `json:"expireAt"`
+}
+
+// PresignDownloadResp 预签名下载地址
+type PresignDownloadResp struct {
+	URL      string `json:"url"`
+	ExpireAt string `json:"expireAt"`
+}
+
+// ResourceInsertResp 单文件上传结果，similar 为同一高校内的近似重复资源（策略为 warn 时仍然插入）
+type ResourceInsertResp struct {
+	Similar []dto.SimilarResource `json:"similar"`
+}
+
+// SimilarResourceResp 相似资源查询结果，按距离从近到远排列
+type SimilarResourceResp struct {
+	ID        int                   `json:"id"`
+	PHash     string                `json:"phash"`
+	Threshold int                   `json:"threshold"`
+	List      []dto.SimilarResource `json:"list"`
+}
+
+// ReverseLookupCandidate 以图识校的候选高校，confidence 为 0~1 的置信度
+type ReverseLookupCandidate struct {
+	Slug               string  `json:"slug"`
+	ShortName          string  `json:"shortName"`
+	Title              string  `json:"title"`
+	Confidence         float64 `json:"confidence"`
+	ResourceID         int     `json:"resourceId"` // 最相似的资源
+	ResourceName       string  `json:"resourceName"`
+	HashDistance       int     `json:"hashDistance"` // 感知哈希汉明距离
+	ColorSimilarity    float64 `json:"colorSimilarity"`
+	OfficialResourceID *int    `json:"officialResourceId"` // 高校的主计算文件（官方矢量版本）
+	CosURL             string  `json:"cosURL"`             // 最相似资源的下载地址
+}
+
+// ReverseLookupResp 以图识校结果，按置信度从高到低排列
+type ReverseLookupResp struct {
+	PHash      string                   `json:"phash"`
+	Palette    []string                 `json:"palette"`
+	Candidates []ReverseLookupCandidate `json:"candidates"`
+}
+
+// LogoNotFoundResp getLogo 找不到资源时的响应数据，名称无法解析为任何高校时附带"你是不是要找"的建议
+type LogoNotFoundResp struct {
+	Name        string                      `json:"name"`
+	Suggestions []udto.UniversitySuggestion `json:"suggestions"`
+}
+
+// ResourceImportResp 资源元数据导入报告；committed 为 false 表示未写入（预览或整批已回滚）
+type ResourceImportResp struct {
+	Format    string                  `json:"format"`
+	Columns   []udto.ColumnMapping    `json:"columns"`
+	Ignored   []string                `json:"ignored"`
+	Total     int                     `json:"total"`
+	Updated   int                     `json:"updated"`
+	Unchanged int                     `json:"unchanged"`
+	Invalid   int                     `json:"invalid"`
+	Failed    int                     `json:"failed"`
+	Skipped   int                     `json:"skipped"`
+	Committed bool                    `json:"committed"`
+	DryRun    bool                    `json:"dryRun"`
+	Items     []dto.ResourceRowResult `json:"items"`
+}
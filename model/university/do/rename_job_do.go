@@ -0,0 +1,30 @@
+package do
+
+import "time"
+
+// RenameJob university_rename_job 表的映射：一次 short_name 重命名对应的 COS 目录迁移任务
+type RenameJob struct {
+	ID           int        `gorm:"primaryKey;column:id;autoIncrement" json:"id"`
+	Slug         string     `gorm:"column:slug" json:"slug"`
+	OldShortName string     `gorm:"column:old_short_name" json:"oldShortName"`
+	NewShortName string     `gorm:"column:new_short_name" json:"newShortName"`
+	Status       string     `gorm:"column:status" json:"status"`
+	Total        int        `gorm:"column:total" json:"total"`
+	DoneCount    int        `gorm:"column:done_count" json:"doneCount"`
+	Attempts     int        `gorm:"column:attempts" json:"attempts"`
+	LastError    *string    `gorm:"column:last_error" json:"lastError"`
+	CreatedTime  *time.Time `gorm:"column:created_time;autoCreateTime" json:"createdTime"`
+	UpdatedTime  *time.Time `gorm:"column:updated_time;autoUpdateTime" json:"updatedTime"`
+}
+
+// RenameObject university_rename_object 表的映射：迁移任务中单个 COS 对象的进度
+type RenameObject struct {
+	ID          int        `gorm:"primaryKey;column:id;autoIncrement" json:"id"`
+	JobID       int        `gorm:"column:job_id" json:"jobID"`
+	OldKey      string     `gorm:"column:old_key" json:"oldKey"`
+	NewKey      string     `gorm:"column:new_key" json:"newKey"`
+	Status      string     `gorm:"column:status" json:"status"`
+	Attempts    int        `gorm:"column:attempts" json:"attempts"`
+	LastError   *string    `gorm:"column:last_error" json:"lastError"`
+	UpdatedTime *time.Time `gorm:"column:updated_time;autoUpdateTime" json:"updatedTime"`
+}
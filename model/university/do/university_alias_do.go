@@ -0,0 +1,16 @@
+package do
+
+import "time"
+
+// UniversityAlias university_alias 表的映射：高校的曾用名、简称和英文别名
+// 别名只在 [ValidFrom, ValidTo] 期间参与名称解析，两端为 NULL 表示不限
+type UniversityAlias struct {
+	ID          int        `gorm:"primaryKey;column:id;autoIncrement" json:"id"`
+	Slug        string     `gorm:"column:slug" json:"slug"`
+	Alias       string     `gorm:"column:alias" json:"alias"`
+	AliasType   string     `gorm:"column:alias_type" json:"aliasType"`
+	ValidFrom   *time.Time `gorm:"column:valid_from;type:date" json:"validFrom"`
+	ValidTo     *time.Time `gorm:"column:valid_to;type:date" json:"validTo"`
+	CreatedTime *time.Time `gorm:"column:created_time;autoCreateTime" json:"createdTime"`
+	UpdatedTime *time.Time `gorm:"column:updated_time;autoUpdateTime" json:"updatedTime"`
+}
@@ -6,6 +6,8 @@ type University struct {
 	Slug      string `gorm:"column:slug;primaryKey" json:"slug"`
 	ShortName string `gorm:"column:short_name" json:"shortName"`
 	Title     string `gorm:"column:title" json:"title"`
+	Pinyin    string `gorm:"column:pinyin" json:"pinyin"`     // 中文全称的全拼
+	Initials  string `gorm:"column:initials" json:"initials"` // 中文全称的拼音首字母
 	// 使用 *string 处理 NULL 字段
 	Vis        *string `gorm:"column:vis" json:"vis"`
 	Website    string  `gorm:"column:website" json:"website"`
@@ -19,6 +21,11 @@ type University struct {
 	MainVectorFormat *string `gorm:"column:main_vector_format" json:"mainVectorFormat"`
 	ResourceCount    int     `gorm:"column:resource_count" json:"resourceCount"`
 	ComputationID    *int    `gorm:"column:computation_id" json:"computationID"`
+	Palette          string  `gorm:"column:palette" json:"-"` // 品牌主色，格式同 resource.palette，对外以 UniversityResp.Palette 输出
+	IsDeleted        int     `gorm:"column:is_deleted" json:"isDeleted"`
+	Version          int     `gorm:"column:version;default:1" json:"version"` // 编辑版本号，修改高校信息时加 1
+	// DeletedTime 停用时间，恢复后清空
+	DeletedTime *time.Time `gorm:"column:deleted_time" json:"deletedTime"`
 	// autoCreateTime 告诉 GORM 在插入时忽略此字段，让数据库生成或由 GORM 生成时间
 	CreatedTime *time.Time `gorm:"column:created_time;autoCreateTime" json:"createdTime"`
 	// autoUpdateTime 告诉 GORM 在创建和更新时都自动处理
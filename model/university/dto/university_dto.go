@@ -1,5 +1,11 @@
 package dto
 
+import (
+	"logo_api/model"
+	"mime/multipart"
+	"time"
+)
+
 // UniversityInsertReq 接收 /insert 路由的请求参数
 type UniversityInsertReq struct {
 	Slug      string `gorm:"column:slug;primaryKey" json:"slug"`
@@ -20,8 +26,39 @@ type UniversityGetListReq struct {
 	Page      int    `json:"page"`
 	PageSize  int    `json:"pageSize"`
 	Keyword   string `json:"keyword"`
-	SortBy    string `json:"sortBy" binding:"omitempty,oneof=slug title createTime updateTime"`
+	SortBy    string `json:"sortBy" binding:"omitempty,oneof=slug title createTime updateTime relevance"` // relevance 按关键字相关度排序，有关键字时默认
 	SortOrder string `json:"sortOrder" binding:"omitempty,oneof=asc desc"`
+
+	// 结构化筛选：同一维度内多个值为 OR，不同维度之间为 AND
+	Regions           []string   `json:"regions"`
+	Provinces         []string   `json:"provinces"`
+	Cities            []string   `json:"cities"`
+	HasVector         *int       `json:"hasVector" binding:"omitempty,oneof=0 1"`
+	MinResourceCount  *int       `json:"minResourceCount" binding:"omitempty,min=0"`
+	MaxResourceCount  *int       `json:"maxResourceCount" binding:"omitempty,min=0"`
+	MainVectorFormats []string   `json:"mainVectorFormats"`
+	CreatedAfter      *time.Time `json:"createdAfter"` // RFC3339，含边界
+	CreatedBefore     *time.Time `json:"createdBefore"`
+	UpdatedAfter      *time.Time `json:"updatedAfter"`
+	UpdatedBefore     *time.Time `json:"updatedBefore"`
+	IsDeleted         *int       `json:"isDeleted" binding:"omitempty,oneof=0 1"` // 默认只查有效高校，1 查已停用的高校
+}
+
+// FacetCount 某个筛选维度上一个取值及其命中的高校数量
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// UniversityFacets /university/list 各筛选维度的分面统计
+// 每个维度的计数应用了除该维度自身以外的全部筛选条件，便于前端在侧栏中多选
+type UniversityFacets struct {
+	Region           []FacetCount `json:"region"`
+	Province         []FacetCount `json:"province"`
+	City             []FacetCount `json:"city"`
+	HasVector        []FacetCount `json:"hasVector"`
+	MainVectorFormat []FacetCount `json:"mainVectorFormat"`
+	ResourceCount    []FacetCount `json:"resourceCount"` // 按 0、1-5、6-20、21+ 分段
 }
 
 type UniversityUpdateReq struct {
@@ -37,3 +74,144 @@ type UniversityUpdateReq struct {
 	City       string  `json:"city" binding:"required"`
 	Story      *string `json:"story" binding:"omitempty"`
 }
+
+// UniversityPatchReq PATCH /university/:slug 请求参数，只更新传入（非 null）的字段
+// vis 和 story 可为空，传入空串表示清空；其余字段不能为空串
+type UniversityPatchReq struct {
+	ShortName  *string `json:"shortName"`
+	Title      *string `json:"title"`
+	Vis        *string `json:"vis"`
+	Website    *string `json:"website"`
+	FullNameEn *string `json:"fullNameEn"`
+	Region     *string `json:"region"`
+	Province   *string `json:"province"`
+	City       *string `json:"city"`
+	Story      *string `json:"story"`
+}
+
+// RenameJobListReq 查询重命名任务列表，status 为空表示全部
+type RenameJobListReq struct {
+	Status string `json:"status" binding:"omitempty,oneof=pending running failed done"`
+}
+
+// UniversityAliasListReq 查询别名，slug 为空表示全部高校
+type UniversityAliasListReq struct {
+	Slug string `json:"slug"`
+}
+
+// UniversityAliasInsertReq 新增别名，日期格式为 2006-01-02，留空表示不限
+type UniversityAliasInsertReq struct {
+	Slug      string `json:"slug" binding:"required"`
+	Alias     string `json:"alias" binding:"required,max=255"`
+	AliasType string `json:"aliasType" binding:"required,oneof=former_name abbreviation english_alias"`
+	ValidFrom string `json:"validFrom" binding:"omitempty,datetime=2006-01-02"`
+	ValidTo   string `json:"validTo" binding:"omitempty,datetime=2006-01-02"`
+}
+
+// UniversityAliasUpdateReq 修改别名，字段含义同 UniversityAliasInsertReq
+type UniversityAliasUpdateReq struct {
+	ID int `json:"id" binding:"required,min=1"`
+	UniversityAliasInsertReq
+}
+
+// UniversityAliasDeleteReq 删除别名
+type UniversityAliasDeleteReq struct {
+	IDs []int `json:"ids" binding:"required,min=1"`
+}
+
+// UniversitySuggestion 名称建议：与输入最相近的高校及命中的名称
+type UniversitySuggestion struct {
+	Slug         string  `json:"slug"`
+	ShortName    string  `json:"shortName"`
+	Title        string  `json:"title"`
+	FullNameEn   string  `json:"fullNameEn"`
+	MatchedField string  `json:"matchedField"` // 命中的字段 title/shortName/fullNameEn/pinyin/initials/alias
+	Matched      string  `json:"matched"`      // 命中字段的值
+	Score        float64 `json:"score"`        // 相似度 0~1
+}
+
+// UniversityAutocompleteReq /university/autocomplete 请求参数
+type UniversityAutocompleteReq struct {
+	Q     string `form:"q" binding:"required"`
+	Limit int    `form:"limit" binding:"omitempty,min=1,max=20"`
+}
+
+// UniversityMatch 高校标识的解析结果
+type UniversityMatch struct {
+	Slug      string `json:"slug"`
+	ShortName string `json:"shortName"`
+	Title     string `json:"title"`
+	MatchedBy string `json:"matchedBy"` // 命中的标识类型，见 model.MatchBy*
+}
+
+// UniversityDeleteReq 停用、恢复或彻底删除高校
+type UniversityDeleteReq struct {
+	Slugs []string `json:"slugs" binding:"required,min=1"`
+}
+
+// UniversityHardDeleteResultDTO 彻底删除高校的执行结果，单个高校失败不影响其他高校
+type UniversityHardDeleteResultDTO struct {
+	Total      int      `json:"total"`
+	Deleted    int      `json:"deleted"`
+	Failed     int      `json:"failed"`
+	Resources  int      `json:"resources"`  // 删除的资源记录数
+	Objects    int      `json:"objects"`    // 删除的 COS 对象数
+	ObjectFail int      `json:"objectFail"` // 删除失败的 COS 对象数，留给对账任务清理
+	Errors     []string `json:"errors"`
+}
+
+// UniversityBatchReq /university/batch 请求参数
+// mode 为 insert 时已存在的 slug 视为冲突，为 upsert 时更新已存在的高校（vis、story 为 null 或未传入时不修改，传入空串时清空）；
+// allOrNothing 为 true 时任意一行失败则整批回滚，否则逐行提交、互不影响
+// dryRun 为 true 时按相同规则只读地判断每一行，不写库，只返回预览结果
+type UniversityBatchReq struct {
+	Mode         string                `json:"mode" binding:"omitempty,oneof=insert upsert"`
+	AllOrNothing bool                  `json:"allOrNothing"`
+	DryRun       bool                  `json:"dryRun"`
+	Items        []UniversityInsertReq `json:"items" binding:"required,min=1,max=1000"`
+}
+
+// UniversityRowResult 批量写入中单行的处理结果
+type UniversityRowResult struct {
+	Index        int                `json:"index"`          // 在请求 items 中的下标
+	Line         int                `json:"line,omitempty"` // 导入时对应源文件中的行号
+	Slug         string             `json:"slug"`
+	Status       string             `json:"status"`                 // 见 model.UniversityRow*
+	ConflictKey  string             `json:"conflictKey,omitempty"`  // 冲突的唯一键，见 model.UniversityKey*
+	ConflictWith string             `json:"conflictWith,omitempty"` // 占用该键的高校 slug
+	Errors       []model.FieldError `json:"errors,omitempty"`       // 校验错误明细
+	Message      string             `json:"message,omitempty"`
+	Changes      []FieldChange      `json:"changes,omitempty"`   // 更新时发生变化的字段
+	RenameJob    int                `json:"renameJob,omitempty"` // short_name 变化时创建的 COS 目录迁移任务
+}
+
+// FieldChange 单个字段的变化，nil 表示 NULL
+type FieldChange struct {
+	Field string  `json:"field"`
+	Old   *string `json:"old"`
+	New   *string `json:"new"`
+}
+
+// UniversityImportReq /university/import 请求参数（multipart 表单）
+// format 为空时按文件扩展名判断；mapping 为 JSON 对象，键为源文件的列名，值为字段名（同 UniversityInsertReq 的 json 名），
+// 值为空串表示忽略该列，未出现在 mapping 中的列按列名自动匹配（忽略大小写、下划线和空格，short_name 与 shortName 等价）
+// dryRun 默认为 true，只返回逐行 diff 预览；为 false 时在一个事务中写入，任意一行不成功则整批回滚
+type UniversityImportReq struct {
+	File    *multipart.FileHeader `form:"file" binding:"required"`
+	Format  string                `form:"format" binding:"omitempty,oneof=csv xlsx json"`
+	Mode    string                `form:"mode" binding:"omitempty,oneof=insert upsert"`
+	Mapping string                `form:"mapping" binding:"omitempty"`
+	DryRun  *bool                 `form:"dryRun"`
+}
+
+// ColumnMapping 源文件中的一列及其对应的字段
+type ColumnMapping struct {
+	Column string `json:"column"`
+	Field  string `json:"field"`
+}
+
+// UniversityExportReq /university/export 请求参数，筛选条件同 /university/list，忽略分页和排序（按 slug 升序）
+type UniversityExportReq struct {
+	Format string `json:"format" binding:"required,oneof=csv xlsx json"`
+	UniversityGetListReq
+}
@@ -2,13 +2,15 @@ package vo
 
 import (
 	"logo_api/model/university/do"
+	"logo_api/model/university/dto"
 	"time"
 )
 
 // UniversityListResp /list 响应
 type UniversityListResp struct {
-	List       []do.University `json:"list"`
-	TotalCount int             `json:"totalCount"` // 所有符合条件的 university 数量
+	List       []do.University      `json:"list"`
+	TotalCount int                  `json:"totalCount"` // 所有符合条件的 university 数量
+	Facets     dto.UniversityFacets `json:"facets"`
 }
 
 type UniversityResp struct {
@@ -27,6 +29,56 @@ type UniversityResp struct {
 	MainVectorFormat *string    `json:"mainVectorFormat"`
 	ResourceCount    int        `json:"resourceCount"`
 	ComputationID    *int       `json:"computationID"`
+	Palette          []string   `json:"palette"`             // 品牌主色，#RRGGBB，按占比从高到低
+	MatchedBy        string     `json:"matchedBy,omitempty"` // 按名称查询时命中的标识类型，见 model.MatchBy*
+	Version          int        `json:"version"`             // 编辑版本号，PATCH 时通过 If-Match 回传
 	CreatedTime      *time.Time `json:"createdTime"`
 	UpdatedTime      *time.Time `json:"updatedTime"`
 }
+
+// UniversityPatchResp PATCH /university/:slug 响应，renameJob 为 short_name 变化时创建的 COS 目录迁移任务
+type UniversityPatchResp struct {
+	UniversityResp
+	RenameJob int `json:"renameJob,omitempty"`
+}
+
+// UniversityBatchResp 批量写入报告；committed 为 false 表示全部成功才提交模式下整批已回滚，预览时总是 false
+type UniversityBatchResp struct {
+	Total     int                       `json:"total"`
+	Created   int                       `json:"created"`
+	Updated   int                       `json:"updated"`
+	Unchanged int                       `json:"unchanged"`
+	Conflicts int                       `json:"conflicts"`
+	Invalid   int                       `json:"invalid"`
+	Failed    int                       `json:"failed"`
+	Skipped   int                       `json:"skipped"`
+	Committed bool                      `json:"committed"`
+	DryRun    bool                      `json:"dryRun"`
+	Items     []dto.UniversityRowResult `json:"items"`
+}
+
+// UniversityImportResp 导入报告：格式、实际使用的列映射、被忽略的列以及逐行结果（含 line 行号和 diff）
+type UniversityImportResp struct {
+	Format  string              `json:"format"`
+	Columns []dto.ColumnMapping `json:"columns"`
+	Ignored []string            `json:"ignored"`
+	UniversityBatchResp
+}
+
+// RenameJobResp 重命名任务详情：任务进度及每个对象的迁移状态
+type RenameJobResp struct {
+	Job     do.RenameJob      `json:"job"`
+	Objects []do.RenameObject `json:"objects"`
+}
+
+// RenameJobListResp 重命名任务列表
+type RenameJobListResp struct {
+	List       []do.RenameJob `json:"list"`
+	TotalCount int            `json:"totalCount"`
+}
+
+// UniversityAutocompleteResp 名称自动补全结果，按相似度从高到低
+type UniversityAutocompleteResp struct {
+	Query string                     `json:"query"`
+	List  []dto.UniversitySuggestion `json:"list"`
+}
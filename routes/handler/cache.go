@@ -1 +1,68 @@
 package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"logo_api/model"
+	"logo_api/model/cos/dto"
+	"logo_api/service"
+)
+
+// GetCacheStats 查看缓存总量、占用字节数、最近过期时间以及 L1 命中统计
+func GetCacheStats(svc *service.ResourceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stats, err := svc.GetCacheStats(c.Request.Context())
+		if err != nil {
+			zap.L().Error("svc.GetCacheStats() failed", zap.Error(err))
+			model.Error(c, model.CodeServerErr)
+			return
+		}
+		model.Success(c, stats)
+	}
+}
+
+// ListCachedVariants 按高校、格式列出已缓存的转换结果
+func ListCachedVariants() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.CacheListReq
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				zap.L().Error("handler.ListCachedVariants() ShouldBindJSON failed", zap.Error(err))
+				model.Error(c, model.CodeInvalidParam)
+				return
+			}
+		}
+		resp, err := service.ListCachedVariants(c.Request.Context(), req)
+		if err != nil {
+			zap.L().Error("service.ListCachedVariants() failed", zap.Any("req", req), zap.Error(err))
+			model.Error(c, model.CodeServerErr)
+			return
+		}
+		model.Success(c, resp)
+	}
+}
+
+// PurgeCache 按高校、格式或全部清理缓存
+func PurgeCache(svc *service.ResourceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.CachePurgeReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			zap.L().Error("handler.PurgeCache() ShouldBindJSON failed", zap.Error(err))
+			model.Error(c, model.CodeInvalidParam)
+			return
+		}
+		result, err := svc.PurgeCache(c.Request.Context(), req)
+		if err != nil {
+			if errors.Is(err, service.ErrEmptyPurgeCondition) {
+				model.Error(c, model.CodeInvalidParam, err.Error())
+				return
+			}
+			zap.L().Error("svc.PurgeCache() failed", zap.Any("req", req), zap.Error(err))
+			model.Error(c, model.CodeServerErr)
+			return
+		}
+		zap.L().Info("handler.PurgeCache() success", zap.Any("req", req))
+		model.Success(c, result)
+	}
+}
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"logo_api/model"
+	"logo_api/model/resource/dto"
+	"logo_api/service"
+	"logo_api/util"
+)
+
+// respondChunkUploadError 把分块上传的错误转换为业务状态码
+func respondChunkUploadError(c *gin.Context, err error) {
+	var validationErr *service.UploadValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		model.ValidationError(c, validationErr.Errors)
+	case errors.Is(err, service.ErrChunkUploadNotFound):
+		model.Error(c, model.CodeNotFound, err.Error())
+	case errors.Is(err, service.ErrChunkPartOutOfOrder),
+		errors.Is(err, service.ErrChunkPartSize),
+		errors.Is(err, service.ErrChunkUploadIncomplete):
+		model.Error(c, model.CodeInvalidParam, err.Error())
+	case errors.Is(err, service.ErrChunkUploadBusy),
+		errors.Is(err, service.ErrChunkPartMismatch),
+		errors.Is(err, service.ErrResourceNameTaken):
+		model.Error(c, model.CodeConflict, err.Error())
+	default:
+		model.Error(c, model.CodeServerErr)
+	}
+}
+
+// InitChunkUpload 初始化分块上传，返回 uploadId 和分块大小
+func InitChunkUpload(svc *service.ResourceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.ChunkUploadInitReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			zap.L().Error("handler.InitChunkUpload() ShouldBindJSON failed", zap.Error(err))
+			model.ValidationError(c, model.BindingFieldErrors(err))
+			return
+		}
+		req.BackgroundColor = util.NormalizeColor(req.BackgroundColor)
+		resp, err := svc.InitChunkUpload(c.Request.Context(), req)
+		if err != nil {
+			zap.L().Error("svc.InitChunkUpload() failed", zap.Any("req", req), zap.Error(err))
+			respondChunkUploadError(c, err)
+			return
+		}
+		model.Success(c, resp)
+	}
+}
+
+// UploadChunkPart 上传一个分块
+func UploadChunkPart(svc *service.ResourceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.ChunkUploadPartReq
+		if err := c.ShouldBind(&req); err != nil {
+			zap.L().Error("handler.UploadChunkPart() ShouldBind failed", zap.Error(err))
+			model.ValidationError(c, model.BindingFieldErrors(err))
+			return
+		}
+		resp, err := svc.UploadChunkPart(c.Request.Context(), req)
+		if err != nil {
+			zap.L().Error("svc.UploadChunkPart() failed", zap.String("uploadId", req.UploadID), zap.Int("partNumber", req.PartNumber), zap.Error(err))
+			respondChunkUploadError(c, err)
+			return
+		}
+		model.Success(c, resp)
+	}
+}
+
+// GetChunkUploadStatus 查询分块上传进度，断线后据此续传
+func GetChunkUploadStatus() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.ChunkUploadIDReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			zap.L().Error("handler.GetChunkUploadStatus() ShouldBindJSON failed", zap.Error(err))
+			model.Error(c, model.CodeInvalidParam)
+			return
+		}
+		resp, err := service.GetChunkUploadStatus(c.Request.Context(), req.UploadID)
+		if err != nil {
+			zap.L().Error("service.GetChunkUploadStatus() failed", zap.String("uploadId", req.UploadID), zap.Error(err))
+			respondChunkUploadError(c, err)
+			return
+		}
+		model.Success(c, resp)
+	}
+}
+
+// CompleteChunkUpload 合并分块并写入资源
+func CompleteChunkUpload(svc *service.ResourceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.ChunkUploadIDReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			zap.L().Error("handler.CompleteChunkUpload() ShouldBindJSON failed", zap.Error(err))
+			model.Error(c, model.CodeInvalidParam)
+			return
+		}
+		resp, err := svc.CompleteChunkUpload(c.Request.Context(), req.UploadID)
+		if err != nil {
+			zap.L().Error("svc.CompleteChunkUpload() failed", zap.String("uploadId", req.UploadID), zap.Error(err))
+			respondChunkUploadError(c, err)
+			return
+		}
+		zap.L().Info("handler.CompleteChunkUpload() success", zap.String("uploadId", req.UploadID), zap.String("status", resp.Status))
+		model.Success(c, resp)
+	}
+}
+
+// AbortChunkUpload 放弃分块上传
+func AbortChunkUpload(svc *service.ResourceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.ChunkUploadIDReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			zap.L().Error("handler.AbortChunkUpload() ShouldBindJSON failed", zap.Error(err))
+			model.Error(c, model.CodeInvalidParam)
+			return
+		}
+		if err := svc.AbortChunkUpload(c.Request.Context(), req.UploadID); err != nil {
+			zap.L().Error("svc.AbortChunkUpload() failed", zap.String("uploadId", req.UploadID), zap.Error(err))
+			respondChunkUploadError(c, err)
+			return
+		}
+		model.SuccessEmpty(c, "success")
+	}
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"logo_api/model"
+	resdto "logo_api/model/resource/dto"
+	"logo_api/model/university/dto"
+	"logo_api/service"
+	"logo_api/util"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ExportUniversities 按 /university/list 的筛选条件导出高校目录（CSV/XLSX/JSON），CSV/XLSX 可修改后通过 /university/import 导回
+func ExportUniversities() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.UniversityExportReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			zap.L().Error("handler.ExportUniversities() ShouldBindJSON failed", zap.Error(err))
+			model.ValidationError(c, model.BindingFieldErrors(err))
+			return
+		}
+		// 筛选条件清洗同 /university/list
+		req.Keyword = strings.ReplaceAll(strings.ReplaceAll(req.Keyword, "(", "（"), ")", "）")
+		req.Regions = cleanFilterValues(req.Regions, nil)
+		req.Provinces = cleanFilterValues(req.Provinces, nil)
+		req.Cities = cleanFilterValues(req.Cities, nil)
+		req.MainVectorFormats = cleanFilterValues(req.MainVectorFormats, util.NormalizeFileType)
+		data, err := service.ExportUniversities(req)
+		if err != nil {
+			zap.L().Error("service.ExportUniversities() failed", zap.String("format", req.Format), zap.Error(err))
+			model.Error(c, model.CodeServerErr)
+			return
+		}
+		writeExportFile(c, "universities", req.Format, data)
+	}
+}
+
+// ExportResources 按 /resource/list 的筛选条件导出资源元数据（CSV/XLSX/JSON），不含文件本身；可修改 usedForEdge、backgroundColor 后通过 /resource/import 导回
+func ExportResources() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req resdto.ResourceExportReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			zap.L().Error("handler.ExportResources() ShouldBindJSON failed", zap.Error(err))
+			model.ValidationError(c, model.BindingFieldErrors(err))
+			return
+		}
+		// 筛选条件清洗同 /resource/list
+		req.Name = strings.TrimSpace(req.Name)
+		req.Types = cleanFilterValues(req.Types, util.NormalizeFileType)
+		if req.BackgroundColor != nil {
+			color := util.NormalizeColor(*req.BackgroundColor)
+			req.BackgroundColor = &color
+		}
+		data, err := service.ExportResources(req)
+		if err != nil {
+			zap.L().Error("service.ExportResources() failed", zap.String("format", req.Format), zap.Error(err))
+			model.Error(c, model.CodeServerErr)
+			return
+		}
+		writeExportFile(c, "resources", req.Format, data)
+	}
+}
+
+// ImportResources 从 CSV/XLSX/JSON 文件按 id 修改资源元数据（usedForEdge、backgroundColor），默认只预览（逐行 diff）
+// 文件或列映射不合法时返回 400；正式导入被整批回滚时，有行校验未通过返回 400，否则返回 411，data 中仍带逐行结果
+func ImportResources(svc *service.ResourceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req resdto.ResourceImportReq
+		if err := c.ShouldBind(&req); err != nil {
+			zap.L().Error("handler.ImportResources() ShouldBind failed", zap.Error(err))
+			model.ValidationError(c, model.BindingFieldErrors(err))
+			return
+		}
+		resp, err := svc.ImportResources(c.Request.Context(), req)
+		if err != nil {
+			if errors.Is(err, util.ErrTableInvalid) || errors.Is(err, util.ErrTableFormatUnsupported) ||
+				errors.Is(err, service.ErrImportMappingInvalid) || errors.Is(err, service.ErrImportTooManyRows) ||
+				errors.Is(err, service.ErrImportFileTooLarge) {
+				model.Error(c, model.CodeInvalidParam, err.Error())
+				return
+			}
+			zap.L().Error("svc.ImportResources() failed", zap.String("filename", req.File.Filename), zap.Error(err))
+			model.Error(c, model.CodeServerErr)
+			return
+		}
+		if !resp.DryRun && !resp.Committed {
+			if resp.Invalid > 0 {
+				model.ErrorWithData(c, model.CodeInvalidParam, resp, "Import rolled back, some rows failed validation.")
+			} else {
+				model.ErrorWithData(c, model.CodeConflict, resp, "Import rolled back, some rows could not be saved.")
+			}
+			return
+		}
+		zap.L().Info("handler.ImportResources() success", zap.String("filename", req.File.Filename), zap.Bool("dryRun", resp.DryRun), zap.Int("total", resp.Total))
+		model.Success(c, resp)
+	}
+}
+
+// writeExportFile 以附件形式返回导出文件，文件名带导出时间
+func writeExportFile(c *gin.Context, name, format string, data []byte) {
+	filename := fmt.Sprintf("%s-%s.%s", name, time.Now().Format("20060102150405"), format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, util.TableContentType(format), data)
+}
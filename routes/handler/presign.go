@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"logo_api/model"
+	"logo_api/model/resource/dto"
+	"logo_api/service"
+	"logo_api/util"
+)
+
+// respondPresignError 把预签名直传的错误转换为业务状态码
+func respondPresignError(c *gin.Context, err error) {
+	var validationErr *service.UploadValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		model.ValidationError(c, validationErr.Errors)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		model.Error(c, model.CodeNotFound)
+	case errors.Is(err, service.ErrPresignUploadNotFound):
+		model.Error(c, model.CodeNotFound, err.Error())
+	case errors.Is(err, service.ErrPresignObjectMissing),
+		errors.Is(err, service.ErrPresignObjectMismatch):
+		model.Error(c, model.CodeInvalidParam, err.Error())
+	case errors.Is(err, service.ErrPresignUploadBusy),
+		errors.Is(err, service.ErrResourceNameTaken):
+		model.Error(c, model.CodeConflict, err.Error())
+	default:
+		model.Error(c, model.CodeServerErr)
+	}
+}
+
+// PresignUpload 签发直传 COS 的上传地址
+func PresignUpload(svc *service.ResourceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.PresignUploadReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			zap.L().Error("handler.PresignUpload() ShouldBindJSON failed", zap.Error(err))
+			model.ValidationError(c, model.BindingFieldErrors(err))
+			return
+		}
+		req.BackgroundColor = util.NormalizeColor(req.BackgroundColor)
+		resp, err := svc.PresignUpload(c.Request.Context(), req)
+		if err != nil {
+			zap.L().Error("svc.PresignUpload() failed", zap.Any("req", req), zap.Error(err))
+			respondPresignError(c, err)
+			return
+		}
+		model.Success(c, resp)
+	}
+}
+
+// CompletePresignUpload 直传完成回调，校验通过后登记资源
+func CompletePresignUpload(svc *service.ResourceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.PresignCompleteReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			zap.L().Error("handler.CompletePresignUpload() ShouldBindJSON failed", zap.Error(err))
+			model.Error(c, model.CodeInvalidParam)
+			return
+		}
+		resp, err := svc.CompletePresignUpload(c.Request.Context(), req.UploadID)
+		if err != nil {
+			zap.L().Error("svc.CompletePresignUpload() failed", zap.String("uploadId", req.UploadID), zap.Error(err))
+			respondPresignError(c, err)
+			return
+		}
+		zap.L().Info("handler.CompletePresignUpload() success", zap.String("uploadId", req.UploadID), zap.String("status", resp.Status))
+		model.Success(c, resp)
+	}
+}
+
+// PresignDownload 签发资源的限时下载地址
+func PresignDownload(svc *service.ResourceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.PresignDownloadReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			zap.L().Error("handler.PresignDownload() ShouldBindJSON failed", zap.Error(err))
+			model.Error(c, model.CodeInvalidParam)
+			return
+		}
+		resp, err := svc.PresignDownload(c.Request.Context(), req.ID)
+		if err != nil {
+			zap.L().Error("svc.PresignDownload() failed", zap.Int("id", req.ID), zap.Error(err))
+			respondPresignError(c, err)
+			return
+		}
+		model.Success(c, resp)
+	}
+}
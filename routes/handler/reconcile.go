@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"logo_api/model"
+	"logo_api/model/cos/dto"
+	"logo_api/service"
+)
+
+// Reconcile 比对 COS 对象、资源表与 Redis 缓存，返回不一致报告；请求中指定 fix* 时执行修复
+func Reconcile(svc *service.ResourceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.ReconcileReq
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				zap.L().Error("handler.Reconcile() ShouldBindJSON failed", zap.Error(err))
+				model.Error(c, model.CodeInvalidParam)
+				return
+			}
+		}
+		resp, err := svc.Reconcile(c.Request.Context(), req)
+		if err != nil {
+			if errors.Is(err, service.ErrReconcileRunning) {
+				model.Error(c, model.CodeConflict, err.Error())
+				return
+			}
+			zap.L().Error("svc.Reconcile() failed", zap.Any("req", req), zap.Error(err))
+			model.Error(c, model.CodeServerErr)
+			return
+		}
+		model.Success(c, resp)
+	}
+}
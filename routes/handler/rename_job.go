@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"logo_api/model"
+	"logo_api/model/university/dto"
+	"logo_api/service"
+	"strconv"
+)
+
+// GetRenameJobList 查询 short_name 重命名任务列表，可按状态过滤
+func GetRenameJobList() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.RenameJobListReq
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				zap.L().Error("handler.GetRenameJobList() ShouldBindJSON failed", zap.Error(err))
+				model.Error(c, model.CodeInvalidParam)
+				return
+			}
+		}
+		resp, err := service.GetRenameJobList(req.Status)
+		if err != nil {
+			zap.L().Error("service.GetRenameJobList() failed", zap.Any("req", req), zap.Error(err))
+			model.Error(c, model.CodeServerErr)
+			return
+		}
+		model.Success(c, resp)
+	}
+}
+
+// GetRenameJob 查询单个重命名任务的进度以及每个对象的迁移状态
+func GetRenameJob() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			model.Error(c, model.CodeInvalidParam)
+			return
+		}
+		resp, err := service.GetRenameJob(id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				model.Error(c, model.CodeNotFound)
+				return
+			}
+			zap.L().Error("service.GetRenameJob() failed", zap.Int("id", id), zap.Error(err))
+			model.Error(c, model.CodeServerErr)
+			return
+		}
+		model.Success(c, resp)
+	}
+}
+
+// RetryRenameJob 立即重试一个未完成的重命名任务，同步执行并返回执行后的任务状态
+func RetryRenameJob(svc *service.ResourceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			model.Error(c, model.CodeInvalidParam)
+			return
+		}
+		// 迁移过程不随客户端断开而中断，避免留下只复制未删除的对象
+		job, err := svc.RunRenameJob(context.Background(), id)
+		if err != nil {
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				model.Error(c, model.CodeNotFound)
+			case errors.Is(err, service.ErrRenameJobRunning):
+				model.Error(c, model.CodeConflict, err.Error())
+			default:
+				// 部分对象迁移失败：任务已记录为 failed，返回当前进度
+				zap.L().Error("svc.RunRenameJob() failed", zap.Int("id", id), zap.Error(err))
+				model.Success(c, job, err.Error())
+			}
+			return
+		}
+		zap.L().Info("handler.RetryRenameJob() success", zap.Int("id", id))
+		model.Success(c, job)
+	}
+}
@@ -3,8 +3,10 @@ package handler
 import (
 	"database/sql"
 	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+	"logo_api/dao/mysql"
 	"logo_api/model"
 	"logo_api/model/resource/dto"
 	"logo_api/model/resource/vo"
@@ -14,6 +16,13 @@ import (
 	"strings"
 )
 
+const (
+	// defaultResourcePageSize /resource/list 默认每页数量
+	defaultResourcePageSize = 20
+	// maxResourcePageSize /resource/list 每页数量上限
+	maxResourcePageSize = 100
+)
+
 func GetResources() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req []dto.ResourceGetReq
@@ -38,7 +47,7 @@ func GetResources() gin.HandlerFunc {
 	}
 }
 
-// GetResourceList 参数 name sortBy sortOrder
+// GetResourceList 参数 name sortBy sortOrder page pageSize cursor 及筛选条件
 func GetResourceList() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req dto.ResourceGetListReq
@@ -48,16 +57,43 @@ func GetResourceList() gin.HandlerFunc {
 			return
 		}
 		zap.L().Info("success get req param", zap.Any("req", req))
+		// 设置默认值
+		if req.Page <= 0 {
+			req.Page = 1
+		}
+		if req.PageSize <= 0 {
+			req.PageSize = defaultResourcePageSize
+		}
+		if req.PageSize > maxResourcePageSize {
+			model.Error(c, model.CodeInvalidParam, fmt.Sprintf("pageSize must not be greater than %d.", maxResourcePageSize))
+			return
+		}
+		// 筛选条件清洗及范围检查
+		req.Name = strings.TrimSpace(req.Name)
+		req.Cursor = strings.TrimSpace(req.Cursor)
+		req.Types = cleanFilterValues(req.Types, util.NormalizeFileType)
+		if req.BackgroundColor != nil {
+			bgColor := util.NormalizeColor(*req.BackgroundColor)
+			req.BackgroundColor = &bgColor
+		}
+		if req.MinSize != nil && req.MaxSize != nil && *req.MinSize > *req.MaxSize {
+			model.Error(c, model.CodeInvalidParam, "minSize must not be greater than maxSize.")
+			return
+		}
 		var (
-			resourceList vo.ResourceResp
+			resourceList vo.ResourceListResp
 			err          error
 		)
 		if resourceList, err = service.GetResourceList(req); err != nil {
+			if errors.Is(err, mysql.ErrInvalidCursor) {
+				model.Error(c, model.CodeInvalidParam, "Invalid cursor, it must come from a previous response with the same sortBy and sortOrder.")
+				return
+			}
 			zap.L().Error("GetResourceList() failed", zap.Error(err))
 			model.Error(c, http.StatusInternalServerError)
 			return
 		}
-		zap.L().Info("GetResourceList() success", zap.Int("success count", resourceList.TotalCount))
+		zap.L().Info("GetResourceList() success", zap.Int("success count", len(resourceList.List)), zap.Int("totalCount", resourceList.TotalCount))
 		model.Success(c, resourceList)
 	}
 }
@@ -75,6 +111,12 @@ func GetLogoFromNameHandler(svc *service.ResourceService) gin.HandlerFunc {
 			zap.Any("req params", req))
 		data, ext, _, err := svc.GetLogo(req) // 调用service层中的方法，对参数进行处理，具体的逻辑在 GetLogo 中的方法
 		if err != nil {
+			var notFound *service.LogoNotFoundError
+			if errors.As(err, &notFound) { // 没查到，附带名称建议
+				zap.L().Info("GetLogoFromNameHandler() resource not found", zap.String("name", req.Name), zap.Int("suggestions", len(notFound.Suggestions)))
+				model.ErrorWithData(c, model.CodeNotFound, vo.LogoNotFoundResp{Name: notFound.Name, Suggestions: notFound.Suggestions}, notFound.Error())
+				return
+			}
 			if errors.Is(err, sql.ErrNoRows) { // 没查到
 				zap.L().Error("GetLogoFromNameHandler() err, resource not found ", zap.Error(err))
 				model.Error(c, http.StatusNotFound)
@@ -86,6 +128,11 @@ func GetLogoFromNameHandler(svc *service.ResourceService) gin.HandlerFunc {
 			}
 		}
 
+		// 报告名称命中的高校及标识类型（解析结果有进程内缓存）
+		if match, err := service.ResolveUniversity(req.Name); err == nil {
+			c.Header("X-University-Short-Name", match.ShortName)
+			c.Header("X-University-Matched-By", match.MatchedBy)
+		}
 		contentType := getContentType(ext)
 		c.Header("Content-Disposition", "inline")
 		c.Data(200, contentType, data)
@@ -97,17 +144,27 @@ func InsertResource() gin.HandlerFunc {
 		var req dto.ResourceInsertReq
 		if err := c.ShouldBind(&req); err != nil {
 			zap.L().Error("handler.InsertResource() ShouldBind failed", zap.Any("req", req), zap.Error(err))
-			model.Error(c, http.StatusBadRequest)
+			model.ValidationError(c, model.BindingFieldErrors(err))
 			return
 		}
 		req.BackgroundColor = util.NormalizeColor(req.BackgroundColor)
-		if err := service.InsertResource(c.Request.Context(), req); err != nil {
+		similar, err := service.InsertResource(c.Request.Context(), req)
+		if err != nil {
+			var validationErr *service.UploadValidationError
+			if errors.As(err, &validationErr) {
+				model.ValidationError(c, validationErr.Errors)
+				return
+			}
+			if errors.Is(err, service.ErrResourceNameTaken) {
+				model.Error(c, model.CodeConflict, err.Error())
+				return
+			}
 			zap.L().Error("service.InsertResource() failed", zap.Any("req", req), zap.Error(err))
 			model.Error(c, http.StatusInternalServerError)
 			return
 		}
-		zap.L().Info("handler.InsertResource() success", zap.Any("req", req))
-		model.SuccessEmpty(c, "success")
+		zap.L().Info("handler.InsertResource() success", zap.Any("req", req), zap.Int("similar", len(similar)))
+		model.Success(c, vo.ResourceInsertResp{Similar: similar})
 	}
 }
 
@@ -163,3 +220,30 @@ func getContentType(ext string) string {
 		return "application/octet-stream"
 	}
 }
+
+// BatchInsertResource 从 zip 压缩包批量上传资源，返回每个文件的处理结果
+func BatchInsertResource(svc *service.ResourceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.ResourceBatchInsertReq
+		if err := c.ShouldBind(&req); err != nil {
+			zap.L().Error("handler.BatchInsertResource() ShouldBind failed", zap.Error(err))
+			model.ValidationError(c, model.BindingFieldErrors(err))
+			return
+		}
+		resp, err := svc.BatchInsertResources(c.Request.Context(), req)
+		if err != nil {
+			zap.L().Error("svc.BatchInsertResources() failed", zap.String("archive", req.Archive.Filename), zap.Error(err))
+			if errors.Is(err, service.ErrBatchArchiveInvalid) ||
+				errors.Is(err, service.ErrBatchManifestInvalid) ||
+				errors.Is(err, service.ErrBatchTooManyEntries) ||
+				errors.Is(err, service.ErrBatchTooLarge) {
+				model.Error(c, model.CodeInvalidParam, err.Error())
+				return
+			}
+			model.Error(c, model.CodeServerErr)
+			return
+		}
+		zap.L().Info("handler.BatchInsertResource() success", zap.String("archive", req.Archive.Filename), zap.Int("inserted", resp.Inserted))
+		model.Success(c, resp)
+	}
+}
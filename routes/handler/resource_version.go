@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"logo_api/dao/mysql"
+	"logo_api/model"
+	"logo_api/model/resource/dto"
+	"logo_api/service"
+	"logo_api/util"
+)
+
+// respondVersionError 把版本相关的错误转换为业务状态码
+func respondVersionError(c *gin.Context, err error) {
+	var validationErr *service.UploadValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		model.ValidationError(c, validationErr.Errors)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		model.Error(c, model.CodeNotFound)
+	case errors.Is(err, service.ErrResourceUnchanged),
+		errors.Is(err, service.ErrResourceDifferentChain):
+		model.Error(c, model.CodeInvalidParam, err.Error())
+	case errors.Is(err, service.ErrResourceNotActive),
+		errors.Is(err, service.ErrResourceNotReplaced),
+		errors.Is(err, service.ErrResourceNameTaken),
+		errors.Is(err, mysql.ErrResourceVersionChanged):
+		model.Error(c, model.CodeConflict, err.Error())
+	default:
+		model.Error(c, model.CodeServerErr)
+	}
+}
+
+// ReplaceResource 上传新文件作为资源的新版本，旧版本保留在历史中
+func ReplaceResource(svc *service.ResourceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.ResourceReplaceReq
+		if err := c.ShouldBind(&req); err != nil {
+			zap.L().Error("handler.ReplaceResource() ShouldBind failed", zap.Error(err))
+			model.ValidationError(c, model.BindingFieldErrors(err))
+			return
+		}
+		req.BackgroundColor = util.NormalizeColor(req.BackgroundColor)
+		resource, err := svc.ReplaceResource(c.Request.Context(), req)
+		if err != nil {
+			zap.L().Error("svc.ReplaceResource() failed", zap.Int("id", req.ID), zap.Error(err))
+			respondVersionError(c, err)
+			return
+		}
+		zap.L().Info("handler.ReplaceResource() success", zap.Int("from", req.ID), zap.Int("to", resource.ID))
+		model.Success(c, resource)
+	}
+}
+
+// GetResourceHistory 查询资源的全部版本
+func GetResourceHistory() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.ResourceHistoryReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			zap.L().Error("handler.GetResourceHistory() ShouldBindJSON failed", zap.Error(err))
+			model.Error(c, model.CodeInvalidParam)
+			return
+		}
+		resp, err := service.GetResourceHistory(req.ID)
+		if err != nil {
+			zap.L().Error("service.GetResourceHistory() failed", zap.Int("id", req.ID), zap.Error(err))
+			respondVersionError(c, err)
+			return
+		}
+		model.Success(c, resp)
+	}
+}
+
+// DiffResourceVersions 比较同一资源两个版本的元数据
+func DiffResourceVersions() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.ResourceDiffReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			zap.L().Error("handler.DiffResourceVersions() ShouldBindJSON failed", zap.Error(err))
+			model.Error(c, model.CodeInvalidParam)
+			return
+		}
+		resp, err := service.DiffResourceVersions(req.FromID, req.ToID)
+		if err != nil {
+			zap.L().Error("service.DiffResourceVersions() failed", zap.Any("req", req), zap.Error(err))
+			respondVersionError(c, err)
+			return
+		}
+		model.Success(c, resp)
+	}
+}
+
+// RollbackResource 回滚到资源的某个历史版本
+func RollbackResource(svc *service.ResourceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.ResourceRollbackReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			zap.L().Error("handler.RollbackResource() ShouldBindJSON failed", zap.Error(err))
+			model.Error(c, model.CodeInvalidParam)
+			return
+		}
+		resource, err := svc.RollbackResource(c.Request.Context(), req.ID)
+		if err != nil {
+			zap.L().Error("svc.RollbackResource() failed", zap.Int("id", req.ID), zap.Error(err))
+			respondVersionError(c, err)
+			return
+		}
+		zap.L().Info("handler.RollbackResource() success", zap.Int("id", req.ID))
+		model.Success(c, resource)
+	}
+}
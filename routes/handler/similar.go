@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"logo_api/model"
+	"logo_api/model/resource/dto"
+	"logo_api/service"
+)
+
+// GetSimilarResources 查询与指定资源感知哈希相近的资源
+func GetSimilarResources() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.SimilarResourceReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			zap.L().Error("handler.GetSimilarResources() ShouldBindJSON failed", zap.Error(err))
+			model.ValidationError(c, model.BindingFieldErrors(err))
+			return
+		}
+		resp, err := service.GetSimilarResources(req)
+		if err != nil {
+			zap.L().Error("service.GetSimilarResources() failed", zap.Any("req", req), zap.Error(err))
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				model.Error(c, model.CodeNotFound)
+				return
+			}
+			model.Error(c, model.CodeServerErr)
+			return
+		}
+		model.Success(c, resp)
+	}
+}
+
+// BackfillImageFeatures 为存量资源补算检索特征（感知哈希、主色）
+func BackfillImageFeatures(svc *service.ResourceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.FeatureBackfillReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			zap.L().Error("handler.BackfillImageFeatures() ShouldBindJSON failed", zap.Error(err))
+			model.Error(c, model.CodeInvalidParam)
+			return
+		}
+		result, err := svc.BackfillImageFeatures(c.Request.Context(), req)
+		if err != nil {
+			zap.L().Error("svc.BackfillImageFeatures() failed", zap.Error(err))
+			model.Error(c, model.CodeServerErr)
+			return
+		}
+		model.Success(c, result)
+	}
+}
+
+// ReverseLookup 以图识校：上传 logo 图片，返回可能所属的高校及置信度
+func ReverseLookup() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.ReverseLookupReq
+		if err := c.ShouldBind(&req); err != nil {
+			zap.L().Error("handler.ReverseLookup() ShouldBind failed", zap.Error(err))
+			model.ValidationError(c, model.BindingFieldErrors(err))
+			return
+		}
+		resp, err := service.ReverseLookup(req)
+		if err != nil {
+			var validationErr *service.UploadValidationError
+			if errors.As(err, &validationErr) {
+				model.ValidationError(c, validationErr.Errors)
+				return
+			}
+			zap.L().Error("service.ReverseLookup() failed", zap.String("filename", req.File.Filename), zap.Error(err))
+			model.Error(c, model.CodeServerErr)
+			return
+		}
+		model.Success(c, resp)
+	}
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"logo_api/model"
+	"logo_api/model/resource/dto"
+	"logo_api/service"
+)
+
+// GetTrashList 查询回收站中的资源及其过期时间
+func GetTrashList() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.TrashListReq
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				zap.L().Error("handler.GetTrashList() ShouldBindJSON failed", zap.Error(err))
+				model.Error(c, model.CodeInvalidParam)
+				return
+			}
+		}
+		resp, err := service.GetTrashList(req.ShortName)
+		if err != nil {
+			zap.L().Error("service.GetTrashList() failed", zap.Any("req", req), zap.Error(err))
+			model.Error(c, model.CodeServerErr)
+			return
+		}
+		model.Success(c, resp)
+	}
+}
+
+// DeleteFromTrash 从回收站彻底删除资源，同时删除 COS 中的文件
+func DeleteFromTrash(svc *service.ResourceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.TrashDeleteReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			zap.L().Error("handler.DeleteFromTrash() ShouldBindJSON failed", zap.Error(err))
+			model.Error(c, model.CodeInvalidParam)
+			return
+		}
+		result := svc.DeleteFromTrash(c.Request.Context(), req.IDs)
+		zap.L().Info("handler.DeleteFromTrash() finished", zap.Any("req", req), zap.Int("deleted", result.Deleted))
+		model.Success(c, result)
+	}
+}
+
+// PurgeExpiredTrash 立即执行一次回收站过期清理（与定时任务相同）
+func PurgeExpiredTrash(svc *service.ResourceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		result, err := svc.PurgeExpiredTrash(c.Request.Context())
+		if err != nil {
+			zap.L().Error("svc.PurgeExpiredTrash() failed", zap.Error(err))
+			model.Error(c, model.CodeServerErr)
+			return
+		}
+		model.Success(c, result)
+	}
+}
@@ -1,14 +1,18 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 	"logo_api/dao/mysql"
 	"logo_api/model"
 	"logo_api/model/university/dto"
 	"logo_api/model/university/vo"
 	"logo_api/service"
+	"logo_api/util"
+	"net/http"
 	"strconv"
 	"strings"
 )
@@ -26,7 +30,8 @@ func GetUniversityFromName() gin.HandlerFunc {
 			model.Error(c, model.CodeNotFound)
 			return
 		}
-		// 找到资源
+		// 找到资源，ETag 供 PATCH 时作为 If-Match 使用
+		c.Header("ETag", universityETag(university.Version))
 		zap.L().Info("getUniversityFromName() success", zap.String("name", name))
 		// c.JSON() 自动完成结构体到 JSON 的序列化
 		model.Success(c, university)
@@ -62,6 +67,24 @@ func InsertUniversity() gin.HandlerFunc {
 	}
 }
 
+// cleanFilterValues 去掉筛选值两端空白、空值和重复值，normalize 不为空时先做归一化
+func cleanFilterValues(values []string, normalize func(string) string) []string {
+	cleaned := make([]string, 0, len(values))
+	seen := make(map[string]bool, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if normalize != nil {
+			v = normalize(v)
+		}
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		cleaned = append(cleaned, v)
+	}
+	return cleaned
+}
+
 func GetUniversityList() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req dto.UniversityGetListReq
@@ -84,6 +107,9 @@ func GetUniversityList() gin.HandlerFunc {
 		}
 		if req.SortBy == "" {
 			req.SortBy = "title"
+			if strings.TrimSpace(req.Keyword) != "" {
+				req.SortBy = "relevance"
+			}
 		}
 		if req.SortOrder == "" {
 			req.SortOrder = "asc"
@@ -98,12 +124,32 @@ func GetUniversityList() gin.HandlerFunc {
 			model.Error(c, model.CodeInvalidParam, "Invalid page parameter, page and pageSize must be greater than 0.")
 			return
 		}
+		// 筛选条件清洗及范围检查
+		req.Regions = cleanFilterValues(req.Regions, nil)
+		req.Provinces = cleanFilterValues(req.Provinces, nil)
+		req.Cities = cleanFilterValues(req.Cities, nil)
+		req.MainVectorFormats = cleanFilterValues(req.MainVectorFormats, util.NormalizeFileType)
+		if req.MinResourceCount != nil && req.MaxResourceCount != nil && *req.MinResourceCount > *req.MaxResourceCount {
+			model.Error(c, model.CodeInvalidParam, "minResourceCount must not be greater than maxResourceCount.")
+			return
+		}
+		if (req.CreatedAfter != nil && req.CreatedBefore != nil && req.CreatedAfter.After(*req.CreatedBefore)) ||
+			(req.UpdatedAfter != nil && req.UpdatedBefore != nil && req.UpdatedAfter.After(*req.UpdatedBefore)) {
+			model.Error(c, model.CodeInvalidParam, "Invalid time range, the start must not be later than the end.")
+			return
+		}
 		universities, totalCount, err := mysql.GetUniversityList(req)
 		if err != nil {
 			zap.L().Error("svc.GetUniversityList() failed", zap.Error(err), zap.Int("page", req.Page), zap.Int("pageSize", req.PageSize), zap.String("keyword", req.Keyword))
 			model.Error(c, model.CodeServerErr)
 			return
 		}
+		facets, err := mysql.GetUniversityFacets(req)
+		if err != nil {
+			zap.L().Error("mysql.GetUniversityFacets() failed", zap.Error(err), zap.Any("req", req))
+			model.Error(c, model.CodeServerErr)
+			return
+		}
 
 		// 根据 totalCount 进行判断
 		if totalCount == 0 && req.Keyword != "" {
@@ -116,6 +162,7 @@ func GetUniversityList() gin.HandlerFunc {
 			var resp vo.UniversityListResp
 			resp.List = universities
 			resp.TotalCount = int(totalCount)
+			resp.Facets = facets
 			model.Success(c, resp, message)
 			return
 		}
@@ -124,11 +171,12 @@ func GetUniversityList() gin.HandlerFunc {
 		var resp vo.UniversityListResp
 		resp.List = universities
 		resp.TotalCount = int(totalCount)
+		resp.Facets = facets
 		model.Success(c, resp)
 	}
 }
 
-func UpdateUniversities() gin.HandlerFunc {
+func UpdateUniversities(svc *service.ResourceService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var reqs []dto.UniversityUpdateReq
 		if err := c.ShouldBindJSON(&reqs); err != nil {
@@ -136,12 +184,250 @@ func UpdateUniversities() gin.HandlerFunc {
 			model.Error(c, model.CodeInvalidParam)
 			return
 		}
-		if err := service.UpdateUniversities(reqs); err != nil {
+		jobIDs, err := svc.UpdateUniversities(reqs)
+		if err != nil {
+			if errors.Is(err, mysql.ErrRenameJobInProgress) {
+				model.Error(c, model.CodeConflict, err.Error())
+				return
+			}
 			zap.L().Error("service.UpdateUniversities() update universities error", zap.Error(err))
 			model.Error(c, model.CodeServerErr)
 			return
 		}
 		zap.L().Info("handler.UpdateUniversities() Success", zap.Int("success count", len(reqs)))
+		if len(jobIDs) > 0 {
+			model.Success(c, gin.H{"renameJobs": jobIDs}, "Successfully update "+strconv.Itoa(len(reqs))+" universities, COS folders are being renamed.")
+			return
+		}
 		model.Success(c, "Successfully update "+strconv.Itoa(len(reqs))+" universities.")
 	}
 }
+
+// RebuildUniversityPinyin 重新生成全部高校的全拼和首字母
+func RebuildUniversityPinyin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		updated, err := service.RebuildUniversityPinyin()
+		if err != nil {
+			zap.L().Error("service.RebuildUniversityPinyin() failed", zap.Error(err))
+			model.Error(c, model.CodeServerErr)
+			return
+		}
+		model.Success(c, gin.H{"updated": updated})
+	}
+}
+
+// AutocompleteUniversity 高校名称自动补全，匹配中文名、简称、英文名、拼音和别名，容忍拼写错误
+func AutocompleteUniversity() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.UniversityAutocompleteReq
+		if err := c.ShouldBindQuery(&req); err != nil {
+			zap.L().Error("handler.AutocompleteUniversity() ShouldBindQuery failed", zap.Error(err))
+			model.ValidationError(c, model.BindingFieldErrors(err))
+			return
+		}
+		list, err := service.SuggestUniversities(req.Q, req.Limit)
+		if err != nil {
+			zap.L().Error("service.SuggestUniversities() failed", zap.String("q", req.Q), zap.Error(err))
+			model.Error(c, model.CodeServerErr)
+			return
+		}
+		model.Success(c, vo.UniversityAutocompleteResp{Query: req.Q, List: list})
+	}
+}
+
+// DeleteUniversities 停用（软删除）高校，资源和文件保留，可通过 /university/restore 恢复
+func DeleteUniversities(svc *service.ResourceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.UniversityDeleteReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			zap.L().Error("handler.DeleteUniversities() ShouldBindJSON failed", zap.Error(err))
+			model.Error(c, model.CodeInvalidParam)
+			return
+		}
+		deleted, err := svc.SoftDeleteUniversities(c.Request.Context(), req.Slugs)
+		if err != nil {
+			zap.L().Error("svc.SoftDeleteUniversities() failed", zap.Strings("slugs", req.Slugs), zap.Error(err))
+			model.Error(c, model.CodeServerErr)
+			return
+		}
+		if deleted == 0 {
+			model.Error(c, model.CodeNotFound, "No active university found for the given slugs.")
+			return
+		}
+		model.Success(c, gin.H{"deleted": deleted})
+	}
+}
+
+// RestoreUniversities 恢复已停用的高校
+func RestoreUniversities() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.UniversityDeleteReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			zap.L().Error("handler.RestoreUniversities() ShouldBindJSON failed", zap.Error(err))
+			model.Error(c, model.CodeInvalidParam)
+			return
+		}
+		restored, err := service.RestoreUniversities(req.Slugs)
+		if err != nil {
+			zap.L().Error("service.RestoreUniversities() failed", zap.Strings("slugs", req.Slugs), zap.Error(err))
+			model.Error(c, model.CodeServerErr)
+			return
+		}
+		if restored == 0 {
+			model.Error(c, model.CodeNotFound, "No deleted university found for the given slugs.")
+			return
+		}
+		model.Success(c, gin.H{"restored": restored})
+	}
+}
+
+// HardDeleteUniversities 彻底删除已停用的高校及其全部资源、别名和 COS 文件，不可恢复
+func HardDeleteUniversities(svc *service.ResourceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.UniversityDeleteReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			zap.L().Error("handler.HardDeleteUniversities() ShouldBindJSON failed", zap.Error(err))
+			model.Error(c, model.CodeInvalidParam)
+			return
+		}
+		result := svc.HardDeleteUniversities(c.Request.Context(), req.Slugs)
+		zap.L().Info("handler.HardDeleteUniversities() finished", zap.Any("req", req), zap.Int("deleted", result.Deleted))
+		model.Success(c, result)
+	}
+}
+
+// universityETag 以编辑版本号作为高校的 ETag
+func universityETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// parseIfMatch 解析 If-Match 请求头中的版本号，支持 "3"、W/"3" 和 3 三种写法
+func parseIfMatch(header string) (int, bool) {
+	v := strings.TrimPrefix(strings.TrimSpace(header), "W/")
+	version, err := strconv.Atoi(strings.Trim(v, `"`))
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+	return version, true
+}
+
+// PatchUniversity 部分更新高校信息，请求头 If-Match 必须携带当前版本号（GET /university/:name 响应中的 version 或 ETag）
+// 版本不一致时返回 HTTP 409 和最新数据
+func PatchUniversity(svc *service.ResourceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		slug := c.Param("slug")
+		version, ok := parseIfMatch(c.GetHeader("If-Match"))
+		if !ok {
+			model.ErrorWithStatus[any](c, http.StatusPreconditionRequired, model.CodeInvalidParam, nil,
+				`If-Match header with the current university version is required, e.g. If-Match: "3".`)
+			return
+		}
+		var req dto.UniversityPatchReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			zap.L().Error("handler.PatchUniversity() ShouldBindJSON failed", zap.Error(err))
+			model.ValidationError(c, model.BindingFieldErrors(err))
+			return
+		}
+		if errs := service.ValidateUniversityPatch(&req); len(errs) > 0 {
+			model.ValidationError(c, errs)
+			return
+		}
+		university, jobID, err := svc.PatchUniversity(slug, version, req)
+		if err != nil {
+			switch {
+			case errors.Is(err, mysql.ErrUniversityVersionChanged):
+				c.Header("ETag", universityETag(university.Version))
+				model.ErrorWithStatus(c, http.StatusConflict, model.CodeConflict, university, err.Error())
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				model.Error(c, model.CodeNotFound, "university not found")
+			case errors.Is(err, mysql.ErrUniversityNameTaken):
+				model.Error(c, model.CodeUniversityExist, err.Error())
+			case errors.Is(err, mysql.ErrRenameJobInProgress):
+				model.Error(c, model.CodeConflict, err.Error())
+			default:
+				zap.L().Error("svc.PatchUniversity() failed", zap.String("slug", slug), zap.Error(err))
+				model.Error(c, model.CodeServerErr)
+			}
+			return
+		}
+		c.Header("ETag", universityETag(university.Version))
+		resp := vo.UniversityPatchResp{UniversityResp: university, RenameJob: jobID}
+		if jobID != 0 {
+			model.Success(c, resp, "Successfully updated university, COS folder is being renamed.")
+			return
+		}
+		model.Success(c, resp)
+	}
+}
+
+// BatchSaveUniversities 批量新增或 upsert 高校，返回每一行的处理结果
+// allOrNothing 模式下整批回滚时返回对应的错误码（校验失败 400、唯一键冲突 410、其他 411），data 中仍带逐行结果；预览总是返回成功
+func BatchSaveUniversities(svc *service.ResourceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.UniversityBatchReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			zap.L().Error("handler.BatchSaveUniversities() ShouldBindJSON failed", zap.Error(err))
+			model.ValidationError(c, model.BindingFieldErrors(err))
+			return
+		}
+		if req.Mode == "" {
+			req.Mode = "insert"
+		}
+		resp, err := svc.BatchSaveUniversities(req)
+		if err != nil {
+			zap.L().Error("svc.BatchSaveUniversities() failed", zap.Error(err))
+			model.Error(c, model.CodeServerErr)
+			return
+		}
+		if req.AllOrNothing && !req.DryRun && !resp.Committed {
+			code, msg := batchRollbackCode(resp)
+			model.ErrorWithData(c, code, resp, msg)
+			return
+		}
+		model.Success(c, resp)
+	}
+}
+
+// batchRollbackCode 整批回滚时的错误码：校验失败 400、唯一键冲突 410、其他 411
+func batchRollbackCode(resp vo.UniversityBatchResp) (int, string) {
+	switch {
+	case resp.Invalid > 0:
+		return model.CodeInvalidParam, "Batch rolled back, some items failed validation."
+	case resp.Conflicts > 0:
+		return model.CodeUniversityExist, "Batch rolled back, some items conflict with existing universities."
+	default:
+		return model.CodeConflict, "Batch rolled back, some items could not be saved."
+	}
+}
+
+// ImportUniversities 从 CSV/XLSX/JSON 文件导入高校目录，默认只预览（逐行 diff），dryRun=false 时在一个事务中写入
+// 文件或列映射不合法时返回 400；正式导入被整批回滚时错误码同 /university/batch，data 中仍带逐行结果
+func ImportUniversities(svc *service.ResourceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.UniversityImportReq
+		if err := c.ShouldBind(&req); err != nil {
+			zap.L().Error("handler.ImportUniversities() ShouldBind failed", zap.Error(err))
+			model.ValidationError(c, model.BindingFieldErrors(err))
+			return
+		}
+		resp, err := svc.ImportUniversities(req)
+		if err != nil {
+			if errors.Is(err, util.ErrTableInvalid) || errors.Is(err, util.ErrTableFormatUnsupported) ||
+				errors.Is(err, service.ErrImportMappingInvalid) || errors.Is(err, service.ErrImportTooManyRows) ||
+				errors.Is(err, service.ErrImportFileTooLarge) {
+				model.Error(c, model.CodeInvalidParam, err.Error())
+				return
+			}
+			zap.L().Error("svc.ImportUniversities() failed", zap.String("filename", req.File.Filename), zap.Error(err))
+			model.Error(c, model.CodeServerErr)
+			return
+		}
+		if !resp.DryRun && !resp.Committed {
+			code, msg := batchRollbackCode(resp.UniversityBatchResp)
+			model.ErrorWithData(c, code, resp, msg)
+			return
+		}
+		zap.L().Info("handler.ImportUniversities() success", zap.String("filename", req.File.Filename), zap.Bool("dryRun", resp.DryRun), zap.Int("total", resp.Total))
+		model.Success(c, resp)
+	}
+}
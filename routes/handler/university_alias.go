@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"logo_api/model"
+	"logo_api/model/university/dto"
+	"logo_api/service"
+)
+
+// respondAliasError 把别名管理的错误转换为业务状态码
+func respondAliasError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		model.Error(c, model.CodeNotFound, "university or alias not found")
+	case errors.Is(err, service.ErrAliasInvalid):
+		model.Error(c, model.CodeInvalidParam, err.Error())
+	case errors.Is(err, service.ErrAliasTaken):
+		model.Error(c, model.CodeConflict, err.Error())
+	default:
+		model.Error(c, model.CodeServerErr)
+	}
+}
+
+// GetUniversityAliases 查询高校别名
+func GetUniversityAliases() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.UniversityAliasListReq
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				zap.L().Error("handler.GetUniversityAliases() ShouldBindJSON failed", zap.Error(err))
+				model.Error(c, model.CodeInvalidParam)
+				return
+			}
+		}
+		aliases, err := service.GetUniversityAliases(req.Slug)
+		if err != nil {
+			zap.L().Error("service.GetUniversityAliases() failed", zap.String("slug", req.Slug), zap.Error(err))
+			model.Error(c, model.CodeServerErr)
+			return
+		}
+		model.Success(c, aliases)
+	}
+}
+
+// InsertUniversityAlias 新增高校别名
+func InsertUniversityAlias() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.UniversityAliasInsertReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			zap.L().Error("handler.InsertUniversityAlias() ShouldBindJSON failed", zap.Error(err))
+			model.ValidationError(c, model.BindingFieldErrors(err))
+			return
+		}
+		alias, err := service.InsertUniversityAlias(req)
+		if err != nil {
+			zap.L().Error("service.InsertUniversityAlias() failed", zap.Any("req", req), zap.Error(err))
+			respondAliasError(c, err)
+			return
+		}
+		model.Success(c, alias)
+	}
+}
+
+// UpdateUniversityAlias 修改高校别名
+func UpdateUniversityAlias() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.UniversityAliasUpdateReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			zap.L().Error("handler.UpdateUniversityAlias() ShouldBindJSON failed", zap.Error(err))
+			model.ValidationError(c, model.BindingFieldErrors(err))
+			return
+		}
+		alias, err := service.UpdateUniversityAlias(req)
+		if err != nil {
+			zap.L().Error("service.UpdateUniversityAlias() failed", zap.Any("req", req), zap.Error(err))
+			respondAliasError(c, err)
+			return
+		}
+		model.Success(c, alias)
+	}
+}
+
+// DeleteUniversityAliases 删除高校别名
+func DeleteUniversityAliases() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req dto.UniversityAliasDeleteReq
+		if err := c.ShouldBindJSON(&req); err != nil {
+			zap.L().Error("handler.DeleteUniversityAliases() ShouldBindJSON failed", zap.Error(err))
+			model.Error(c, model.CodeInvalidParam)
+			return
+		}
+		deleted, err := service.DeleteUniversityAliases(req.IDs)
+		if err != nil {
+			zap.L().Error("service.DeleteUniversityAliases() failed", zap.Ints("ids", req.IDs), zap.Error(err))
+			model.Error(c, model.CodeServerErr)
+			return
+		}
+		model.Success(c, gin.H{"deleted": deleted})
+	}
+}
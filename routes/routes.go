@@ -20,7 +20,8 @@ func Setup(svc *service.ResourceService) *gin.Engine {
 		r1.POST("/user/register", handler.RegisterFunc())
 		r1.POST("/user/login", handler.UserLogin())
 
-		r1.POST("/clearCache", clearCache(svc))
+		// 清理缓存：需要共享密钥或管理员登录
+		r1.POST("/clearCache", auth.SecretOrAdminRequired(svc), clearCache(svc))
 	}
 	user := router.Group("/user")
 	user.Use(auth.AuthRequired(svc))
@@ -36,10 +37,21 @@ func Setup(svc *service.ResourceService) *gin.Engine {
 	university.Use(auth.AuthRequired(svc))
 	{
 		university.POST("/list", handler.GetUniversityList())
+		university.GET("/autocomplete", handler.AutocompleteUniversity())
 		// 后台管理路由：增、删、改、查、登录
 		university.GET("/:name", handler.GetUniversityFromName())
 		university.POST("/insert", handler.InsertUniversity())
-		university.POST("/update", handler.UpdateUniversities())
+		// 批量新增或 upsert，逐行返回结果，可选择全部成功才提交
+		university.POST("/batch", handler.BatchSaveUniversities(svc))
+		// 导入（默认只预览 diff）与导出：CSV、XLSX、JSON
+		university.POST("/import", handler.ImportUniversities(svc))
+		university.POST("/export", handler.ExportUniversities())
+		university.POST("/update", handler.UpdateUniversities(svc))
+		// 部分更新：只修改传入的字段，If-Match 携带版本号防止并发覆盖
+		university.PATCH("/:slug", handler.PatchUniversity(svc))
+		// 停用（软删除）与恢复；彻底删除见 /admin/university/hardDelete
+		university.POST("/delete", handler.DeleteUniversities(svc))
+		university.POST("/restore", handler.RestoreUniversities())
 	}
 	resource := router.Group("/resource")
 	resource.Use(auth.AuthRequired(svc))
@@ -47,9 +59,54 @@ func Setup(svc *service.ResourceService) *gin.Engine {
 		resource.GET("/getLogo", handler.GetLogoFromNameHandler(svc))
 		resource.POST("/get", handler.GetResources())
 		resource.POST("/list", handler.GetResourceList())
+		// 元数据导入（默认只预览 diff）与导出：CSV、XLSX、JSON
+		resource.POST("/import", handler.ImportResources(svc))
+		resource.POST("/export", handler.ExportResources())
 		resource.POST("/insert", handler.InsertResource())
+		resource.POST("/batchInsert", handler.BatchInsertResource(svc))
 		resource.POST("/delete", handler.DelResource())
 		resource.POST("/recover", handler.RecoverResource())
+		// 版本管理：替换为新版本、查看历史、比较版本、回滚
+		resource.POST("/replace", handler.ReplaceResource(svc))
+		resource.POST("/history", handler.GetResourceHistory())
+		resource.POST("/diff", handler.DiffResourceVersions())
+		resource.POST("/rollback", handler.RollbackResource(svc))
+		resource.POST("/similar", handler.GetSimilarResources())
+		resource.POST("/reverseLookup", handler.ReverseLookup())
+		// 分块上传（断点续传）：初始化、上传分块、查询进度、完成、放弃
+		resource.POST("/chunk/init", handler.InitChunkUpload(svc))
+		resource.POST("/chunk/part", handler.UploadChunkPart(svc))
+		resource.POST("/chunk/status", handler.GetChunkUploadStatus())
+		resource.POST("/chunk/complete", handler.CompleteChunkUpload(svc))
+		resource.POST("/chunk/abort", handler.AbortChunkUpload(svc))
+		// 预签名直传：签发上传地址、完成回调、签发下载地址
+		resource.POST("/presign/upload", handler.PresignUpload(svc))
+		resource.POST("/presign/complete", handler.CompletePresignUpload(svc))
+		resource.POST("/presign/download", handler.PresignDownload(svc))
+	}
+	// 后台管理路由：需要登录且用户名在管理员列表中
+	admin := router.Group("/admin")
+	admin.Use(auth.AuthRequired(svc), auth.AdminRequired())
+	{
+		admin.GET("/cache/stats", handler.GetCacheStats(svc))
+		admin.POST("/cache/list", handler.ListCachedVariants())
+		admin.POST("/cache/purge", handler.PurgeCache(svc))
+		admin.POST("/reconcile", handler.Reconcile(svc))
+		admin.POST("/renameJob/list", handler.GetRenameJobList())
+		admin.GET("/renameJob/:id", handler.GetRenameJob())
+		admin.POST("/renameJob/retry/:id", handler.RetryRenameJob(svc))
+		admin.POST("/trash/list", handler.GetTrashList())
+		admin.POST("/trash/delete", handler.DeleteFromTrash(svc))
+		admin.POST("/trash/purge", handler.PurgeExpiredTrash(svc))
+		admin.POST("/resource/features/backfill", handler.BackfillImageFeatures(svc))
+		admin.POST("/university/pinyin/rebuild", handler.RebuildUniversityPinyin())
+		// 彻底删除已停用的高校，级联删除资源、别名和 COS 文件
+		admin.POST("/university/hardDelete", handler.HardDeleteUniversities(svc))
+		// 高校别名（曾用名、简称、英文别名）管理
+		admin.POST("/university/alias/list", handler.GetUniversityAliases())
+		admin.POST("/university/alias/insert", handler.InsertUniversityAlias())
+		admin.POST("/university/alias/update", handler.UpdateUniversityAlias())
+		admin.POST("/university/alias/delete", handler.DeleteUniversityAliases())
 	}
 	return router
 }
@@ -73,6 +130,15 @@ func clearCache(svc *service.ResourceService) gin.HandlerFunc {
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
 		result, err := svc.CleanExpiredCOSObjects(ctx)
+		if err == nil && result.Skipped {
+			zap.L().Info("CleanExpiredCOSObjects skipped, another cleaner is running")
+			c.JSON(http.StatusOK, gin.H{
+				"code": 200,
+				"msg":  "another cache cleaner is running, skipped",
+				"data": result,
+			})
+			return
+		}
 		if err != nil {
 			zap.L().Error("CleanExpiredCOSObjects error", zap.Error(err))
 			respondWithError(c, 500, err.Error(), nil, "CleanExpiredCOSObjects")
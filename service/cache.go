@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"logo_api/dao/redis"
+	"logo_api/model/cos/dto"
+	"logo_api/model/cos/vo"
+	"logo_api/util"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrEmptyPurgeCondition 清理缓存时没有指定任何条件
+var ErrEmptyPurgeCondition = errors.New("purge condition is empty, specify shortName, format or all")
+
+// listCacheEntries 读取三层缓存 (ZSET + Key 2 + 大小记录)，组装成缓存条目列表，并按 shortName/format 过滤
+func listCacheEntries(ctx context.Context, shortName, format string) ([]dto.CacheEntryDTO, []string, error) {
+	pending, err := redis.ListPendingDelete(ctx)
+	if err != nil {
+		zap.L().Error("redis.ListPendingDelete() failed", zap.Error(err))
+		return nil, nil, err
+	}
+	sizes, err := redis.GetCacheSizes(ctx)
+	if err != nil {
+		zap.L().Warn("redis.GetCacheSizes() failed, sizes will be reported as 0", zap.Error(err))
+		sizes = map[string]int64{}
+	}
+	now := time.Now()
+	format = strings.ToLower(strings.TrimPrefix(format, "."))
+
+	entries := make([]dto.CacheEntryDTO, 0, len(pending))
+	encodedPaths := make([]string, 0, len(pending))
+	cosPaths := make([]string, 0, len(pending))
+	for _, p := range pending {
+		cosPath, decodeErr := url.QueryUnescape(p.EncodedPath)
+		if decodeErr != nil {
+			zap.L().Warn("Failed to unescape COS path", zap.String("encodedPath", p.EncodedPath), zap.Error(decodeErr))
+			continue
+		}
+		// 路径格式: beacon/downloads/<short_name>/<name>
+		parts := strings.SplitN(strings.TrimPrefix(cosPath, util.CosDownloadsPrefix), "/", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		entry := dto.CacheEntryDTO{
+			CosPath:   cosPath,
+			ShortName: parts[0],
+			Name:      parts[1],
+			Format:    strings.ToLower(strings.TrimPrefix(path.Ext(parts[1]), ".")),
+			Size:      sizes[p.EncodedPath],
+			ExpireAt:  p.ExpireAt,
+			Expired:   !p.ExpireAt.After(now),
+		}
+		if shortName != "" && entry.ShortName != shortName {
+			continue
+		}
+		if format != "" && entry.Format != format {
+			continue
+		}
+		entries = append(entries, entry)
+		encodedPaths = append(encodedPaths, p.EncodedPath)
+		cosPaths = append(cosPaths, cosPath)
+	}
+
+	// 批量补全 Key 2 中的 cacheKey
+	cacheKeys, err := redis.GetReverseMappings(ctx, cosPaths)
+	if err != nil {
+		zap.L().Warn("redis.GetReverseMappings() failed", zap.Error(err))
+	} else {
+		for i := range entries {
+			entries[i].CacheKey = cacheKeys[entries[i].CosPath]
+		}
+	}
+	return entries, encodedPaths, nil
+}
+
+// ListCachedVariants 列出已缓存的转换结果，可按高校和格式过滤
+func ListCachedVariants(ctx context.Context, req dto.CacheListReq) (vo.CacheListResp, error) {
+	entries, _, err := listCacheEntries(ctx, req.ShortName, req.Format)
+	if err != nil {
+		return vo.CacheListResp{}, err
+	}
+	resp := vo.CacheListResp{List: entries, TotalCount: len(entries)}
+	for _, e := range entries {
+		resp.TotalBytes += e.Size
+	}
+	zap.L().Info("ListCachedVariants() success", zap.Any("req", req), zap.Int("count", resp.TotalCount))
+	return resp, nil
+}
+
+// GetCacheStats 汇总缓存总量、占用字节数、过期情况以及 L1 命中统计
+func (svc *ResourceService) GetCacheStats(ctx context.Context) (vo.CacheStatsResp, error) {
+	entries, _, err := listCacheEntries(ctx, "", "")
+	if err != nil {
+		return vo.CacheStatsResp{}, err
+	}
+	resp := vo.CacheStatsResp{
+		TotalCount: len(entries),
+		L1:         svc.L1Stats(),
+	}
+	byUniversity := make(map[string]*vo.CacheUniversityStat)
+	for _, e := range entries {
+		resp.TotalBytes += e.Size
+		stat, ok := byUniversity[e.ShortName]
+		if !ok {
+			stat = &vo.CacheUniversityStat{ShortName: e.ShortName}
+			byUniversity[e.ShortName] = stat
+		}
+		stat.Count++
+		stat.Bytes += e.Size
+		if e.Expired {
+			resp.ExpiredCount++
+			continue
+		}
+		expireAt := e.ExpireAt
+		if resp.NextExpireAt == nil || expireAt.Before(*resp.NextExpireAt) {
+			resp.NextExpireAt = &expireAt
+		}
+		if stat.NextExpireAt == nil || expireAt.Before(*stat.NextExpireAt) {
+			stat.NextExpireAt = &expireAt
+		}
+	}
+	resp.Universities = make([]vo.CacheUniversityStat, 0, len(byUniversity))
+	for _, stat := range byUniversity {
+		resp.Universities = append(resp.Universities, *stat)
+	}
+	sort.Slice(resp.Universities, func(i, j int) bool {
+		return resp.Universities[i].ShortName < resp.Universities[j].ShortName
+	})
+	return resp, nil
+}
+
+// PurgeCache 按高校、格式或全部清理缓存（不论是否过期）
+func (svc *ResourceService) PurgeCache(ctx context.Context, req dto.CachePurgeReq) (*dto.CleanResultDTO, error) {
+	if !req.All && req.ShortName == "" && req.Format == "" {
+		return nil, ErrEmptyPurgeCondition
+	}
+	shortName, format := req.ShortName, req.Format
+	if req.All {
+		shortName, format = "", ""
+	}
+	entries, encodedPaths, err := listCacheEntries(ctx, shortName, format)
+	if err != nil {
+		return nil, err
+	}
+	// L1 中的转换结果先于 COS 删除失效：COS 删除失败的对象留待重试，但不应再从 L1 返回旧的变体
+	if req.All {
+		svc.L1.InvalidatePrefix(l1VariantKeyPrefix)
+	} else {
+		cacheKeys := make([]string, 0, len(entries))
+		for _, e := range entries {
+			cacheKeys = append(cacheKeys, e.CacheKey)
+		}
+		svc.InvalidateVariant(cacheKeys...)
+	}
+	if len(encodedPaths) == 0 {
+		zap.L().Info("PurgeCache(): nothing to purge", zap.Any("req", req))
+		return &dto.CleanResultDTO{}, nil
+	}
+	result := svc.purgeCachedPaths(ctx, encodedPaths)
+	zap.L().Info("PurgeCache() finished", zap.Any("req", req), zap.Int("success", result.SuccessCount), zap.Int("fail", result.FailCount))
+	return result, nil
+}
@@ -0,0 +1,440 @@
+package service
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	goredis "github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"io"
+	"logo_api/dao/mysql"
+	"logo_api/dao/redis"
+	"logo_api/model"
+	"logo_api/model/resource/do"
+	"logo_api/model/resource/dto"
+	"logo_api/model/resource/vo"
+	"logo_api/settings"
+	"logo_api/util"
+	"time"
+)
+
+const (
+	defaultChunkMaxSizeBytes  int64 = 2 << 30 // 默认分块上传文件上限 2GB
+	defaultChunkPartSizeBytes int64 = 8 << 20 // 默认分块大小 8MB
+	minChunkPartSizeBytes     int64 = 1 << 20 // COS 要求除最后一块外每块至少 1MB
+	defaultChunkSessionHours        = 24
+	chunkMaxParts                   = 10000 // COS 单次分块上传最多 10000 块
+	chunkUploadLockTTL              = 10 * time.Minute
+)
+
+var (
+	// ErrChunkUploadNotFound 会话不存在或已过期
+	ErrChunkUploadNotFound = errors.New("chunked upload not found or expired")
+	// ErrChunkUploadBusy 同一会话的另一个请求正在处理
+	ErrChunkUploadBusy = errors.New("another request is processing this upload")
+	// ErrChunkPartOutOfOrder 分块必须按顺序上传
+	ErrChunkPartOutOfOrder = errors.New("part is out of order")
+	// ErrChunkPartSize 分块大小与约定不符
+	ErrChunkPartSize = errors.New("part size does not match")
+	// ErrChunkPartMismatch 重传的分块内容与已接收的不同
+	ErrChunkPartMismatch = errors.New("part content differs from the part already received")
+	// ErrChunkUploadIncomplete 还有分块未上传
+	ErrChunkUploadIncomplete = errors.New("not all parts have been uploaded")
+)
+
+// chunkPart 已接收的分块
+type chunkPart struct {
+	Number int    `json:"number"`
+	Size   int64  `json:"size"`
+	ETag   string `json:"etag"`
+	Md5    string `json:"md5"`
+}
+
+// chunkUploadSession 分块上传会话，保存在 Redis 中
+type chunkUploadSession struct {
+	ID          string `json:"id"`
+	CosPath     string `json:"cosPath"`
+	CosUploadID string `json:"cosUploadId"`
+	uploadMetadata
+	Size       int64       `json:"size"`
+	PartSize   int64       `json:"partSize"`
+	TotalParts int         `json:"totalParts"`
+	Parts      []chunkPart `json:"parts"`
+	Md5State   []byte      `json:"md5State"` // md5 的中间状态 (MarshalBinary)，每接收一块更新一次
+	Width      int         `json:"width"`    // 位图尺寸，从第一个分块读取
+	Height     int         `json:"height"`
+	ExpireAt   time.Time   `json:"expireAt"`
+}
+
+// chunkOptions 分块上传参数
+type chunkOptions struct {
+	maxSizeBytes  int64
+	partSizeBytes int64
+	sessionTTL    time.Duration
+}
+
+func newChunkOptions() chunkOptions {
+	opts := chunkOptions{
+		maxSizeBytes:  defaultChunkMaxSizeBytes,
+		partSizeBytes: defaultChunkPartSizeBytes,
+		sessionTTL:    defaultChunkSessionHours * time.Hour,
+	}
+	if cfg := settings.Config.UploadConfig; cfg != nil {
+		if cfg.ChunkMaxSizeBytes > 0 {
+			opts.maxSizeBytes = cfg.ChunkMaxSizeBytes
+		}
+		if cfg.ChunkPartSizeBytes >= minChunkPartSizeBytes {
+			opts.partSizeBytes = cfg.ChunkPartSizeBytes
+		}
+		if cfg.ChunkSessionHours > 0 {
+			opts.sessionTTL = time.Duration(cfg.ChunkSessionHours) * time.Hour
+		}
+	}
+	return opts
+}
+
+// InitChunkUpload 校验元数据并初始化 COS 分块上传。文件内容的校验在第一个分块到达时进行
+func (svc *ResourceService) InitChunkUpload(ctx context.Context, req dto.ChunkUploadInitReq) (vo.ChunkUploadStatusResp, error) {
+	opts := newChunkOptions()
+	req.Type = util.NormalizeFileType(req.Type)
+
+	// 1. 元数据校验，与单文件上传一致
+	errs, err := validateUploadMetadata(uploadCandidate{
+		Type:        req.Type,
+		Name:        req.Name,
+		Title:       req.Title,
+		ShortName:   req.ShortName,
+		UsedForEdge: req.UsedForEdge,
+	})
+	if err != nil {
+		return vo.ChunkUploadStatusResp{}, err
+	}
+	if req.Size > opts.maxSizeBytes {
+		errs = append(errs, model.FieldError{Field: "size", Code: "too_large",
+			Message: fmt.Sprintf("file size %d bytes exceeds the limit of %d bytes", req.Size, opts.maxSizeBytes)})
+	}
+	totalParts := int((req.Size + opts.partSizeBytes - 1) / opts.partSizeBytes)
+	if totalParts > chunkMaxParts {
+		errs = append(errs, model.FieldError{Field: "size", Code: "too_many_parts",
+			Message: fmt.Sprintf("file needs %d parts, the limit is %d", totalParts, chunkMaxParts)})
+	}
+	if len(errs) > 0 {
+		return vo.ChunkUploadStatusResp{}, &UploadValidationError{Errors: errs}
+	}
+	// 2. 在会话有效期内占用名称：同名资源或另一个同名上传会在合并时覆盖 COS 中的文件，提前拒绝
+	id, err := newLockToken()
+	if err != nil {
+		return vo.ChunkUploadStatusResp{}, err
+	}
+	if err = reserveResourceName(ctx, req.ShortName, req.Name, id, opts.sessionTTL); err != nil {
+		return vo.ChunkUploadStatusResp{}, err
+	}
+
+	// 3. 初始化 COS 分块上传并保存会话
+	md5State, err := md5.New().(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		releaseResourceName(req.ShortName, req.Name, id)
+		return vo.ChunkUploadStatusResp{}, err
+	}
+	cosPath := util.CosDownloadsPrefix + req.ShortName + "/" + req.Name
+	cosUploadID, err := svc.CosClient.InitiateMultipartUpload(ctx, cosPath)
+	if err != nil {
+		releaseResourceName(req.ShortName, req.Name, id)
+		return vo.ChunkUploadStatusResp{}, err
+	}
+	session := &chunkUploadSession{
+		ID:          id,
+		CosPath:     cosPath,
+		CosUploadID: cosUploadID,
+		uploadMetadata: uploadMetadata{
+			Title:           req.Title,
+			ShortName:       req.ShortName,
+			Name:            req.Name,
+			Type:            req.Type,
+			UsedForEdge:     req.UsedForEdge,
+			BackgroundColor: req.BackgroundColor,
+		},
+		Size:       req.Size,
+		PartSize:   opts.partSizeBytes,
+		TotalParts: totalParts,
+		Md5State:   md5State,
+	}
+	if err = saveChunkSession(ctx, session, opts.sessionTTL); err != nil {
+		if abortErr := svc.CosClient.AbortMultipartUpload(context.Background(), cosPath, cosUploadID); abortErr != nil {
+			zap.L().Error("CosClient.AbortMultipartUpload() failed during rollback", zap.Error(abortErr))
+		}
+		releaseResourceName(req.ShortName, req.Name, id)
+		return vo.ChunkUploadStatusResp{}, err
+	}
+	zap.L().Info("service.InitChunkUpload() success", zap.String("uploadId", id),
+		zap.String("cosPath", cosPath), zap.Int64("size", req.Size), zap.Int("totalParts", totalParts))
+	return session.status(), nil
+}
+
+// UploadChunkPart 接收一个分块：上传到 COS 后再更新 Redis 中的进度和 md5 状态，任何一步失败客户端都可以重传该分块
+func (svc *ResourceService) UploadChunkPart(ctx context.Context, req dto.ChunkUploadPartReq) (vo.ChunkUploadStatusResp, error) {
+	var resp vo.ChunkUploadStatusResp
+	err := withChunkSession(ctx, req.UploadID, func(session *chunkUploadSession) error {
+		opts := newChunkOptions()
+		partMd5, err := util.CalculateMD5(req.File)
+		if err != nil {
+			return err
+		}
+		received := len(session.Parts)
+		// 1. 已接收过的分块：内容一致则视为重传成功，不再上传
+		if req.PartNumber <= received {
+			if session.Parts[req.PartNumber-1].Md5 != partMd5 {
+				return fmt.Errorf("%w: part %d", ErrChunkPartMismatch, req.PartNumber)
+			}
+			resp = session.status()
+			return nil
+		}
+		if req.PartNumber != received+1 || req.PartNumber > session.TotalParts {
+			return fmt.Errorf("%w: expected part %d, got %d", ErrChunkPartOutOfOrder, received+1, req.PartNumber)
+		}
+		if want := session.partSize(req.PartNumber); req.File.Size != want {
+			return fmt.Errorf("%w: part %d should be %d bytes, got %d", ErrChunkPartSize, req.PartNumber, want, req.File.Size)
+		}
+
+		// 2. 第一个分块包含文件头，在这里校验内容类型和位图尺寸
+		if req.PartNumber == 1 {
+			errs, err := validateUploadContent(req.File, session.Type, newUploadLimits())
+			if err != nil {
+				return err
+			}
+			if len(errs) > 0 {
+				return &UploadValidationError{Errors: errs}
+			}
+			if util.IsBitmapType(session.Type) {
+				session.Width, session.Height, _ = decodeImageSize(req.File)
+			}
+		}
+
+		// 3. 上传到 COS
+		fd, err := req.File.Open()
+		if err != nil {
+			return err
+		}
+		defer fd.Close()
+		etag, err := svc.CosClient.UploadPart(ctx, session.CosPath, session.CosUploadID, req.PartNumber, fd, req.File.Size)
+		if err != nil {
+			return err
+		}
+
+		// 4. 增量计算 md5，更新会话
+		if _, err = fd.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		hash := md5.New()
+		if err = hash.(encoding.BinaryUnmarshaler).UnmarshalBinary(session.Md5State); err != nil {
+			return err
+		}
+		if _, err = io.Copy(hash, fd); err != nil {
+			return err
+		}
+		if session.Md5State, err = hash.(encoding.BinaryMarshaler).MarshalBinary(); err != nil {
+			return err
+		}
+		session.Parts = append(session.Parts, chunkPart{Number: req.PartNumber, Size: req.File.Size, ETag: etag, Md5: partMd5})
+		if err = saveChunkSession(ctx, session, opts.sessionTTL); err != nil {
+			return err
+		}
+		resp = session.status()
+		return nil
+	})
+	return resp, err
+}
+
+// CompleteChunkUpload 合并分块并写入资源表；内容与已有资源重复时删除合并后的文件
+func (svc *ResourceService) CompleteChunkUpload(ctx context.Context, uploadID string) (vo.UploadCompleteResp, error) {
+	var resp vo.UploadCompleteResp
+	err := withChunkSession(ctx, uploadID, func(session *chunkUploadSession) error {
+		if len(session.Parts) != session.TotalParts {
+			return fmt.Errorf("%w: %d of %d received", ErrChunkUploadIncomplete, len(session.Parts), session.TotalParts)
+		}
+		// 1. 合并前确认名称仍由本会话占用，避免覆盖其他资源的文件
+		err := confirmResourceName(ctx, session.ShortName, session.Name, session.ID, newChunkOptions().sessionTTL)
+		if err != nil {
+			if errors.Is(err, ErrResourceNameTaken) {
+				svc.abortChunkSession(ctx, session)
+			}
+			return err
+		}
+
+		// 2. 合并分块
+		hash := md5.New()
+		if err = hash.(encoding.BinaryUnmarshaler).UnmarshalBinary(session.Md5State); err != nil {
+			return err
+		}
+		parts := make([]util.UploadedPart, 0, len(session.Parts))
+		for _, p := range session.Parts {
+			parts = append(parts, util.UploadedPart{PartNumber: p.Number, ETag: p.ETag})
+		}
+		if err = svc.CosClient.CompleteMultipartUpload(ctx, session.CosPath, session.CosUploadID, parts); err != nil {
+			return err
+		}
+		// 合并后无论写库成功与否都不再需要占用名称：成功后由资源表保证唯一，失败时文件已删除
+		defer releaseResourceName(session.ShortName, session.Name, session.ID)
+		// 分块已合并，COS 中的 UploadID 失效，会话不再可用
+		if err = redis.DeleteChunkUpload(ctx, session.ID); err != nil {
+			zap.L().Warn("redis.DeleteChunkUpload() failed, session will expire later", zap.String("uploadId", session.ID), zap.Error(err))
+		}
+
+		// 3. 计算检索特征并检查近似重复，策略为 block 时删除合并后的文件
+		resource := session.toEntity(hex.EncodeToString(hash.Sum(nil)), session.Size, session.Width, session.Height)
+		resp.Similar, err = svc.storedImageFeatures(ctx, resource, session.CosPath)
+		if err != nil {
+			svc.deleteUploadedObject(session.CosPath)
+			return err
+		}
+
+		// 4. 写入资源表
+		duplicates, err := mysql.InsertResourcesWithReport([]*do.Resource{resource})
+		if err != nil {
+			zap.L().Error("mysql.InsertResourcesWithReport() failed", zap.String("uploadId", session.ID), zap.Error(err))
+			svc.deleteUploadedObject(session.CosPath)
+			return err
+		}
+		resp.Resource = doResourceToDTO(*resource)
+		if len(duplicates) > 0 {
+			// 同一高校已有相同内容的资源；名称由本会话占用，合并后的路径不属于其他资源，可以直接删除
+			svc.deleteUploadedObject(session.CosPath)
+			resp.Status = model.BatchItemDuplicate
+			return nil
+		}
+		resp.Status = model.BatchItemInserted
+		svc.purgeUniversityVariants(ctx, session.ShortName)
+		zap.L().Info("service.CompleteChunkUpload() success", zap.String("uploadId", session.ID),
+			zap.Int("id", resource.ID), zap.String("md5", resource.Md5), zap.Int64("size", session.Size))
+		return nil
+	})
+	return resp, err
+}
+
+// AbortChunkUpload 放弃分块上传，删除已上传的分块和会话
+func (svc *ResourceService) AbortChunkUpload(ctx context.Context, uploadID string) error {
+	return withChunkSession(ctx, uploadID, func(session *chunkUploadSession) error {
+		svc.abortChunkSession(ctx, session)
+		zap.L().Info("service.AbortChunkUpload() success", zap.String("uploadId", uploadID))
+		return nil
+	})
+}
+
+// GetChunkUploadStatus 查询分块上传进度，用于断点续传
+func GetChunkUploadStatus(ctx context.Context, uploadID string) (vo.ChunkUploadStatusResp, error) {
+	session, err := loadChunkSession(ctx, uploadID)
+	if err != nil {
+		return vo.ChunkUploadStatusResp{}, err
+	}
+	return session.status(), nil
+}
+
+// abortChunkSession 放弃 COS 分块上传，删除会话并释放名称；COS 放弃失败时保留的分块由 CleanAbandonedUploads 清理
+func (svc *ResourceService) abortChunkSession(ctx context.Context, session *chunkUploadSession) {
+	if err := svc.CosClient.AbortMultipartUpload(ctx, session.CosPath, session.CosUploadID); err != nil {
+		zap.L().Warn("CosClient.AbortMultipartUpload() failed", zap.String("uploadId", session.ID), zap.Error(err))
+	}
+	releaseResourceName(session.ShortName, session.Name, session.ID)
+	if err := redis.DeleteChunkUpload(ctx, session.ID); err != nil {
+		zap.L().Warn("redis.DeleteChunkUpload() failed, session will expire later", zap.String("uploadId", session.ID), zap.Error(err))
+	}
+}
+
+// withChunkSession 持有会话锁并加载会话后执行 fn，保证同一会话的请求串行处理
+func withChunkSession(ctx context.Context, uploadID string, fn func(session *chunkUploadSession) error) error {
+	lockKey := redis.ChunkUploadLockPrefix + uploadID
+	token, err := newLockToken()
+	if err != nil {
+		return err
+	}
+	ok, err := redis.AcquireLock(ctx, lockKey, token, chunkUploadLockTTL)
+	if err != nil {
+		zap.L().Error("redis.AcquireLock() failed", zap.String("key", lockKey), zap.Error(err))
+		return err
+	}
+	if !ok {
+		return ErrChunkUploadBusy
+	}
+	defer func() {
+		if err := redis.ReleaseLock(context.Background(), lockKey, token); err != nil {
+			zap.L().Warn("redis.ReleaseLock() failed", zap.String("key", lockKey), zap.Error(err))
+		}
+	}()
+	session, err := loadChunkSession(ctx, uploadID)
+	if err != nil {
+		return err
+	}
+	return fn(session)
+}
+
+func loadChunkSession(ctx context.Context, uploadID string) (*chunkUploadSession, error) {
+	data, err := redis.GetChunkUpload(ctx, uploadID)
+	if errors.Is(err, goredis.Nil) {
+		return nil, ErrChunkUploadNotFound
+	}
+	if err != nil {
+		zap.L().Error("redis.GetChunkUpload() failed", zap.String("uploadId", uploadID), zap.Error(err))
+		return nil, err
+	}
+	var session chunkUploadSession
+	if err = json.Unmarshal(data, &session); err != nil {
+		zap.L().Error("json.Unmarshal() chunk session failed", zap.String("uploadId", uploadID), zap.Error(err))
+		return nil, err
+	}
+	return &session, nil
+}
+
+func saveChunkSession(ctx context.Context, session *chunkUploadSession, ttl time.Duration) error {
+	session.ExpireAt = time.Now().Add(ttl)
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	if err = redis.SetChunkUpload(ctx, session.ID, data, ttl); err != nil {
+		zap.L().Error("redis.SetChunkUpload() failed", zap.String("uploadId", session.ID), zap.Error(err))
+		return err
+	}
+	// 名称占用与会话同时续期
+	key := resourceNameKey(session.ShortName, session.Name)
+	held, err := redis.ExtendLock(ctx, key, session.ID, ttl)
+	if err != nil {
+		zap.L().Error("redis.ExtendLock() failed", zap.String("key", key), zap.Error(err))
+		return err
+	}
+	if !held {
+		return fmt.Errorf("%w: reservation of %s has expired", ErrResourceNameTaken, session.Name)
+	}
+	return nil
+}
+
+// partSize 第 n 块应有的大小，最后一块为剩余字节数
+func (s *chunkUploadSession) partSize(n int) int64 {
+	if n < s.TotalParts {
+		return s.PartSize
+	}
+	return s.Size - int64(s.TotalParts-1)*s.PartSize
+}
+
+func (s *chunkUploadSession) status() vo.ChunkUploadStatusResp {
+	resp := vo.ChunkUploadStatusResp{
+		UploadID:      s.ID,
+		ShortName:     s.ShortName,
+		Name:          s.Name,
+		Size:          s.Size,
+		PartSize:      s.PartSize,
+		TotalParts:    s.TotalParts,
+		ReceivedParts: len(s.Parts),
+		ExpireAt:      s.ExpireAt.Format(time.DateTime),
+	}
+	for _, p := range s.Parts {
+		resp.ReceivedBytes += p.Size
+	}
+	if len(s.Parts) < s.TotalParts {
+		resp.NextPart = len(s.Parts) + 1
+	}
+	return resp
+}
@@ -2,78 +2,253 @@ package service
 
 import (
 	"context"
-	goredis "github.com/redis/go-redis/v9"
+	"crypto/rand"
+	"encoding/hex"
 	"go.uber.org/zap"
 	"logo_api/dao/redis"
 	"logo_api/model/cos/dto"
+	"logo_api/settings"
+	"logo_api/util"
 	"net/url"
+	"sync"
 	"time"
 )
 
+// cleanerLockTTL 清理任务锁的过期时间；运行期间每隔 1/3 TTL 续期一次，单次清理的耗时可以超过它
+const cleanerLockTTL = 5 * time.Minute
+
 // CleanExpiredCOSObjects 清理过期的 COS 对象 以及 Redis 对象
+// 通过 Redis 分布式锁保证多个实例（本地定时器、SCF 定时触发器、手动调用）不会同时执行清理
 func (svc *ResourceService) CleanExpiredCOSObjects(ctx context.Context) (*dto.CleanResultDTO, error) {
-	encodedPaths, err := redis.GetExpiredPendingDeletePaths(ctx, time.Now())
+	token, err := newLockToken()
 	if err != nil {
 		return nil, err
 	}
-	result := &dto.CleanResultDTO{
-		Total: len(encodedPaths),
+	locked, err := redis.AcquireLock(ctx, redis.CleanerLockKey, token, cleanerLockTTL)
+	if err != nil {
+		zap.L().Error("redis.AcquireLock() failed", zap.Error(err))
+		return nil, err
+	}
+	if !locked {
+		zap.L().Info("Another cleaner is running, skip this round")
+		return &dto.CleanResultDTO{Skipped: true}, nil
 	}
+	defer func() {
+		// 使用 Background，确保父级 Context 取消后仍能释放锁
+		if err := redis.ReleaseLock(context.Background(), redis.CleanerLockKey, token); err != nil {
+			zap.L().Warn("redis.ReleaseLock() failed", zap.Error(err))
+		}
+	}()
 
+	// 限制单次运行的时长，避免超过云函数的执行时间窗口
+	opts := newCleanerOptions()
+	if _, ok := ctx.Deadline(); !ok && opts.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.timeout)
+		defer cancel()
+	}
+	// 运行期间持续续期，锁丢失时取消 ctx，不再派发新的批次
+	ctx, stopKeepAlive := keepLockAlive(ctx, redis.CleanerLockKey, token, cleanerLockTTL)
+	defer stopKeepAlive()
+
+	encodedPaths, err := redis.GetExpiredPendingDeletePaths(ctx, time.Now(), int64(opts.maxPerRun))
+	if err != nil {
+		return nil, err
+	}
 	if len(encodedPaths) == 0 {
 		zap.L().Info("No expired COS objects to clean")
-		return result, nil
+		return &dto.CleanResultDTO{}, nil
 	}
+	result := svc.purgeCachedPaths(ctx, encodedPaths)
+	if opts.maxPerRun > 0 && len(encodedPaths) == opts.maxPerRun {
+		// 达到单次上限，ZSET 中可能还有剩余
+		result.Truncated = true
+	}
+	return result, nil
+}
 
+// keepLockAlive 每隔 ttl/3 延长一次锁，直到返回的 cancel 被调用；续期失败或锁已被他人持有时取消返回的 ctx
+func keepLockAlive(ctx context.Context, key, token string, ttl time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				ok, err := redis.ExtendLock(context.Background(), key, token, ttl)
+				if err != nil || !ok {
+					zap.L().Warn("redis.ExtendLock() failed, stop the run", zap.String("key", key), zap.Bool("held", ok), zap.Error(err))
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+	return ctx, cancel
+}
+
+// newLockToken 生成随机的锁持有者标识
+func newLockToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		zap.L().Error("rand.Read() failed", zap.Error(err))
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// cleanerOptions 一次清理任务的批量参数
+type cleanerOptions struct {
+	batchSize   int           // 单批删除的对象数，不超过 COS 批量删除上限
+	concurrency int           // 同时执行的批次数
+	maxPerRun   int           // 单次运行最多处理的路径数，0 表示不限制
+	timeout     time.Duration // 单次运行的最长时间，0 表示不限制
+}
+
+// newCleanerOptions 从配置中读取清理参数，未配置时使用默认值
+func newCleanerOptions() cleanerOptions {
+	opts := cleanerOptions{
+		batchSize:   util.MaxDeleteMultiKeys,
+		concurrency: 4,
+		maxPerRun:   20000,
+		timeout:     50 * time.Second, // 默认留出余量，避免超过云函数的执行时长
+	}
+	config := settings.Config.CleanerConfig
+	if config == nil {
+		return opts
+	}
+	if config.BatchSize > 0 && config.BatchSize < util.MaxDeleteMultiKeys {
+		opts.batchSize = config.BatchSize
+	}
+	if config.Concurrency > 0 {
+		opts.concurrency = config.Concurrency
+	}
+	if config.MaxPerRun != 0 {
+		opts.maxPerRun = max(config.MaxPerRun, 0)
+	}
+	if config.TimeoutSeconds != 0 {
+		opts.timeout = time.Duration(max(config.TimeoutSeconds, 0)) * time.Second
+	}
+	return opts
+}
+
+// purgeCachedPaths 删除一组缓存文件：COS 对象、Key 1、Key 2、ZSET 成员以及大小记录（接收 ENCODED 路径）
+// 路径按批次调用 COS 批量删除，Redis 清理使用 Pipeline，批次之间以有限的并发执行；
+// ctx 到期后不再派发新的批次，未处理的路径留在 ZSET 中等待下一次清理
+func (svc *ResourceService) purgeCachedPaths(ctx context.Context, encodedPaths []string) *dto.CleanResultDTO {
+	opts := newCleanerOptions()
+	result := &dto.CleanResultDTO{
+		Total: len(encodedPaths),
+	}
+
+	// 1. 对 ZSET 取出的 ENCODED 路径进行 DECODE
+	valid := make([]string, 0, len(encodedPaths))
+	var broken []string
 	for _, encodedPath := range encodedPaths {
-		// 对 ZSET 取出的 ENCODED 路径进行 DECODE
-		cosPath, decodeErr := url.QueryUnescape(encodedPath)
-		if decodeErr != nil {
+		if _, decodeErr := url.QueryUnescape(encodedPath); decodeErr != nil {
 			zap.L().Error("Failed to unescape COS path, skipping deletion", zap.String("encodedPath", encodedPath), zap.Error(decodeErr))
-			// 无法解码，认为路径损坏，但 ZSET 成员仍然需要移除
-			_ = redis.RemovePendingDeletePaths(ctx, encodedPath)
-			result.FailCount++
+			broken = append(broken, encodedPath)
 			continue
 		}
+		valid = append(valid, encodedPath)
+	}
+	if len(broken) > 0 {
+		// 无法解码，认为路径损坏，但 ZSET 成员仍然需要移除
+		if err := redis.RemoveCacheEntries(ctx, nil, broken); err != nil {
+			zap.L().Warn("redis.RemoveCacheEntries() failed for broken paths", zap.Error(err))
+		}
+		result.FailCount += len(broken)
+	}
 
-		// 1. 从 Key 2 (反向映射) 中查找对应的 cacheKey (用于清理 Key 1)
-		cacheKey, err := redis.GetReverseMapping(ctx, cosPath)
-		if err != nil && err != goredis.Nil {
-			zap.L().Error("Failed to get cacheKey from ReverseMapping (Key 2)", zap.String("path", cosPath), zap.Error(err))
-			// 即使查询失败也继续清理 COS，防止存储泄漏
+	// 2. 按批次切分，并发执行
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, opts.concurrency)
+	)
+	for start := 0; start < len(valid); start += opts.batchSize {
+		end := min(start+opts.batchSize, len(valid))
+		batch := valid[start:end]
+		// 2a. 超时或被取消：剩余批次留到下一次
+		select {
+		case <-ctx.Done():
+		case sem <- struct{}{}:
 		}
+		if ctx.Err() != nil {
+			mu.Lock()
+			result.Truncated = true
+			mu.Unlock()
+			zap.L().Warn("Cleaner deadline reached, remaining paths are left for next run", zap.Int("remaining", len(valid)-start))
+			break
+		}
+		wg.Add(1)
+		go func(batch []string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			batchResult := svc.purgeBatch(ctx, batch)
+			mu.Lock()
+			result.SuccessCount += batchResult.SuccessCount
+			result.FailCount += batchResult.FailCount
+			result.FailedPaths = append(result.FailedPaths, batchResult.FailedPaths...)
+			mu.Unlock()
+		}(batch)
+	}
+	wg.Wait()
+	return result
+}
 
-		// 2. 从腾讯云COS进行删除
-		// 传入 COS DeleteObject 的必须是 DECODED 原始路径
-		err = svc.CosClient.DeleteObject(context.Background(), cosPath)
-		if err != nil {
-			// COS 删除失败：不从 ZSET 和 Key 2 中移除，等待下一次重试
-			zap.L().Error("Failed to delete COS object", zap.String("path", cosPath), zap.Error(err))
+// purgeBatch 处理一个批次：批量读取 Key 2、COS 批量删除、Pipeline 清理 Redis
+func (svc *ResourceService) purgeBatch(ctx context.Context, encodedPaths []string) *dto.CleanResultDTO {
+	result := &dto.CleanResultDTO{Total: len(encodedPaths)}
+	cosPaths := make([]string, len(encodedPaths))
+	for i, encodedPath := range encodedPaths {
+		cosPaths[i], _ = url.QueryUnescape(encodedPath)
+	}
+
+	// 1. 从 Key 2 (反向映射) 中批量查找对应的 cacheKey (用于清理 Key 1)
+	cacheKeys, err := redis.GetReverseMappings(ctx, cosPaths)
+	if err != nil {
+		zap.L().Error("Failed to get cacheKeys from ReverseMapping (Key 2)", zap.Int("count", len(cosPaths)), zap.Error(err))
+		// 即使查询失败也继续清理 COS，防止存储泄漏
+		cacheKeys = map[string]string{}
+	}
+
+	// 2. 从腾讯云COS进行批量删除
+	// 传入 COS 的必须是 DECODED 原始路径
+	failed, err := svc.CosClient.DeleteObjects(ctx, cosPaths)
+	if err != nil {
+		// 整批删除失败：不从 ZSET 和 Key 2 中移除，等待下一次重试
+		zap.L().Error("Failed to delete COS objects", zap.Int("count", len(cosPaths)), zap.Error(err))
+		result.FailCount = len(cosPaths)
+		result.FailedPaths = cosPaths
+		return result
+	}
+
+	// 3. COS 删除成功的部分，使用 Pipeline 执行 Redis 缓存清理
+	deletedEncoded := make([]string, 0, len(encodedPaths))
+	deletedKeys := make([]string, 0, len(encodedPaths))
+	for i, cosPath := range cosPaths {
+		if reason, ok := failed[cosPath]; ok {
+			// 单个对象删除失败：保留 Redis 记录，等待下一次重试
+			zap.L().Error("Failed to delete COS object", zap.String("path", cosPath), zap.String("reason", reason))
 			result.FailCount++
 			result.FailedPaths = append(result.FailedPaths, cosPath)
 			continue
 		}
-		// 3. COS 删除成功，执行 Redis 缓存清理
-		// 3a. 删除 Key 1: hash -> cosPath (如果 Key 2 查到了 Key)
-		if cacheKey != "" && err != goredis.Nil {
-			if err = redis.DeleteCacheMapping(ctx, cacheKey); err != nil {
-				zap.L().Warn("Failed to delete CacheMapping (Key 1)", zap.String("key", cacheKey), zap.Error(err))
-			}
-		}
-
-		// 3b. 删除 Key 2: cosPath -> hash
-		if err = redis.DeleteReverseMapping(ctx, cosPath); err != nil {
-			zap.L().Warn("Failed to delete ReverseMapping (Key 2)", zap.String("path", cosPath), zap.Error(err))
+		deletedEncoded = append(deletedEncoded, encodedPaths[i])
+		if cacheKey := cacheKeys[cosPath]; cacheKey != "" {
+			deletedKeys = append(deletedKeys, cacheKey)
 		}
-		// 3c. 从 ZSET 移除
-		// ZSET 移除使用 ENCODED 路径（Redis 中存储的原始成员）
-		err = redis.RemovePendingDeletePaths(ctx, encodedPath)
-		if err != nil {
-			zap.L().Error("Failed to remove path from pending delete", zap.String("path", cosPath), zap.Error(err))
-		}
-		// 这里的日志应该显示 DECODED 路径，更友好
-		zap.L().Info("Deleted expired COS object", zap.String("path", cosPath))
 		result.SuccessCount++
 	}
-	return result, nil
+	if err = redis.RemoveCacheEntries(ctx, deletedKeys, deletedEncoded); err != nil {
+		zap.L().Error("redis.RemoveCacheEntries() failed", zap.Int("count", len(deletedEncoded)), zap.Error(err))
+	}
+	// 同步失效 L1 缓存
+	svc.InvalidateVariant(deletedKeys...)
+	zap.L().Info("Deleted expired COS objects", zap.Int("success", result.SuccessCount), zap.Int("fail", result.FailCount))
+	return result
 }
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"go.uber.org/zap"
+	"logo_api/settings"
+	"logo_api/util"
+	"time"
+)
+
+// L1 缓存的 key 前缀：转换后的位图按 generateCacheKey 缓存，直接读取的源文件按 md5 缓存
+const (
+	l1VariantKeyPrefix = "variant:"
+	l1SourceKeyPrefix  = "source:"
+)
+
+// variantCacheTTL 转换结果的缓存有效期：COS 中的缓存文件在此之后进入待清理队列，
+// L1 中的转换结果也不能活得更久，否则其他实例清理或替换资源后本实例会一直返回旧数据
+const variantCacheTTL = 20 * time.Second
+
+const (
+	defaultL1MaxBytes      int64 = 64 << 20 // 默认总容量 64MB
+	defaultL1MaxEntryBytes int64 = 4 << 20  // 默认单个文件上限 4MB，超过的大文件不进内存
+)
+
+// newL1Cache 根据配置创建进程内 L1 缓存
+func newL1Cache(config *settings.L1CacheConfig) *util.ByteCache {
+	maxBytes, maxEntryBytes := defaultL1MaxBytes, defaultL1MaxEntryBytes
+	if config != nil {
+		if config.MaxBytes > 0 {
+			maxBytes = config.MaxBytes
+		}
+		if config.MaxEntryBytes > 0 {
+			maxEntryBytes = config.MaxEntryBytes
+		}
+	}
+	cache := util.NewByteCache(maxBytes, maxEntryBytes)
+	cache.OnInvalidate(func(key string) {
+		zap.L().Debug("L1 cache entry invalidated", zap.String("key", key))
+	})
+	return cache
+}
+
+// InvalidateVariant 失效某个转换结果（cacheKey 由 generateCacheKey 生成）
+func (svc *ResourceService) InvalidateVariant(cacheKeys ...string) {
+	keys := make([]string, 0, len(cacheKeys))
+	for _, cacheKey := range cacheKeys {
+		if cacheKey != "" {
+			keys = append(keys, l1VariantKeyPrefix+cacheKey)
+		}
+	}
+	svc.L1.Invalidate(keys...)
+}
+
+// InvalidateSource 失效某个源资源（按 md5）
+func (svc *ResourceService) InvalidateSource(md5s ...string) {
+	keys := make([]string, 0, len(md5s))
+	for _, md5 := range md5s {
+		if md5 != "" {
+			keys = append(keys, l1SourceKeyPrefix+md5)
+		}
+	}
+	svc.L1.Invalidate(keys...)
+}
+
+// OnL1Invalidate 注册 L1 缓存失效回调
+func (svc *ResourceService) OnL1Invalidate(hook util.InvalidateHook) {
+	svc.L1.OnInvalidate(hook)
+}
+
+// L1Stats 返回 L1 缓存的命中统计
+func (svc *ResourceService) L1Stats() util.ByteCacheStats {
+	return svc.L1.Stats()
+}
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	goredis "github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"logo_api/dao/mysql"
+	"logo_api/dao/redis"
+	"logo_api/model"
+	"logo_api/model/resource/do"
+	"logo_api/model/resource/dto"
+	"logo_api/model/resource/vo"
+	"logo_api/settings"
+	"logo_api/util"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPresignExpire = 15 * time.Minute
+	// presignTicketGrace 凭据比上传地址多保留一段时间，客户端在地址过期前完成上传后仍可回调
+	presignTicketGrace = time.Hour
+	presignLockTTL     = 5 * time.Minute
+	// presignSniffBytes 回调时读取的文件头字节数，足够嗅探类型并读取常见位图的尺寸
+	presignSniffBytes int64 = 64 << 10
+)
+
+var (
+	// ErrPresignUploadNotFound 凭据不存在或已过期
+	ErrPresignUploadNotFound = errors.New("presigned upload not found or expired")
+	// ErrPresignUploadBusy 同一凭据的回调正在处理
+	ErrPresignUploadBusy = errors.New("another request is completing this upload")
+	// ErrPresignObjectMissing COS 中还没有上传的对象
+	ErrPresignObjectMissing = errors.New("object has not been uploaded yet")
+	// ErrPresignObjectMismatch COS 中对象的大小或 md5 与申请时声明的不同
+	ErrPresignObjectMismatch = errors.New("uploaded object does not match the declared size or md5")
+)
+
+// presignTicket 预签名直传凭据，保存在 Redis 中
+// 客户端上传到暂存路径，回调校验通过后再复制到正式路径，避免直传覆盖已有资源
+type presignTicket struct {
+	ID          string `json:"id"`
+	StagingPath string `json:"stagingPath"`
+	CosPath     string `json:"cosPath"`
+	uploadMetadata
+	Size int64  `json:"size"`
+	Md5  string `json:"md5"`
+}
+
+// presignExpire 预签名地址的有效期
+func presignExpire() time.Duration {
+	if cfg := settings.Config.UploadConfig; cfg != nil && cfg.PresignExpireMinutes > 0 {
+		return time.Duration(cfg.PresignExpireMinutes) * time.Minute
+	}
+	return defaultPresignExpire
+}
+
+// PresignUpload 校验元数据后签发直传 COS 的 PUT 地址，文件不再经过本服务
+func (svc *ResourceService) PresignUpload(ctx context.Context, req dto.PresignUploadReq) (vo.PresignUploadResp, error) {
+	meta := uploadMetadata{
+		Title:           req.Title,
+		ShortName:       req.ShortName,
+		Name:            req.Name,
+		Type:            util.NormalizeFileType(req.Type),
+		UsedForEdge:     req.UsedForEdge,
+		BackgroundColor: req.BackgroundColor,
+	}
+	// 1. 元数据校验；直传的大小上限与分块上传相同
+	errs, err := validateUploadMetadata(meta.candidate())
+	if err != nil {
+		return vo.PresignUploadResp{}, err
+	}
+	if maxSize := newChunkOptions().maxSizeBytes; req.Size > maxSize {
+		errs = append(errs, model.FieldError{Field: "size", Code: "too_large",
+			Message: fmt.Sprintf("file size %d bytes exceeds the limit of %d bytes", req.Size, maxSize)})
+	}
+	if len(errs) > 0 {
+		return vo.PresignUploadResp{}, &UploadValidationError{Errors: errs}
+	}
+	md5Bytes, err := hex.DecodeString(req.Md5)
+	if err != nil {
+		return vo.PresignUploadResp{}, err
+	}
+	// 凭据有效期内占用名称，同名资源或另一个同名上传会在复制到正式路径时覆盖文件，提前拒绝
+	expire := presignExpire()
+	id, err := newLockToken()
+	if err != nil {
+		return vo.PresignUploadResp{}, err
+	}
+	if err = reserveResourceName(ctx, meta.ShortName, meta.Name, id, expire+presignTicketGrace); err != nil {
+		return vo.PresignUploadResp{}, err
+	}
+
+	// 2. 签发地址：Content-MD5 参与签名，COS 会拒绝内容与声明不符的上传
+	contentMd5 := base64.StdEncoding.EncodeToString(md5Bytes)
+	stagingPath := util.CosStagingPrefix + id + "/" + meta.Name
+	cosPath := util.CosDownloadsPrefix + meta.ShortName + "/" + meta.Name
+	url, err := svc.CosClient.PresignPutURL(ctx, stagingPath, contentMd5, expire)
+	if err != nil {
+		releaseResourceName(meta.ShortName, meta.Name, id)
+		return vo.PresignUploadResp{}, err
+	}
+
+	// 3. 保存凭据
+	ticket := presignTicket{
+		ID:             id,
+		StagingPath:    stagingPath,
+		CosPath:        cosPath,
+		uploadMetadata: meta,
+		Size:           req.Size,
+		Md5:            strings.ToLower(req.Md5),
+	}
+	data, err := json.Marshal(ticket)
+	if err != nil {
+		releaseResourceName(meta.ShortName, meta.Name, id)
+		return vo.PresignUploadResp{}, err
+	}
+	if err = redis.SetPresignUpload(ctx, id, data, expire+presignTicketGrace); err != nil {
+		zap.L().Error("redis.SetPresignUpload() failed", zap.String("uploadId", id), zap.Error(err))
+		releaseResourceName(meta.ShortName, meta.Name, id)
+		return vo.PresignUploadResp{}, err
+	}
+	zap.L().Info("service.PresignUpload() success", zap.String("uploadId", id), zap.String("cosPath", cosPath), zap.Int64("size", req.Size))
+	return vo.PresignUploadResp{
+		UploadID: id,
+		URL:      url,
+		Method:   http.MethodPut,
+		Headers:  map[string]string{"Content-MD5": contentMd5},
+		ExpireAt: time.Now().Add(expire).Format(time.DateTime),
+	}, nil
+}
+
+// CompletePresignUpload 直传完成回调：核对暂存对象的大小、md5 和内容类型，复制到正式路径后写入资源表
+// 大小或 md5 不符时删除暂存对象并保留凭据，客户端可在地址过期前重新上传；其余校验不通过时删除暂存对象和凭据
+func (svc *ResourceService) CompletePresignUpload(ctx context.Context, uploadID string) (vo.UploadCompleteResp, error) {
+	lockKey := redis.PresignUploadLockPrefix + uploadID
+	token, err := newLockToken()
+	if err != nil {
+		return vo.UploadCompleteResp{}, err
+	}
+	locked, err := redis.AcquireLock(ctx, lockKey, token, presignLockTTL)
+	if err != nil {
+		zap.L().Error("redis.AcquireLock() failed", zap.String("key", lockKey), zap.Error(err))
+		return vo.UploadCompleteResp{}, err
+	}
+	if !locked {
+		return vo.UploadCompleteResp{}, ErrPresignUploadBusy
+	}
+	defer func() {
+		if err := redis.ReleaseLock(context.Background(), lockKey, token); err != nil {
+			zap.L().Warn("redis.ReleaseLock() failed", zap.String("key", lockKey), zap.Error(err))
+		}
+	}()
+
+	// 1. 读取凭据
+	data, err := redis.GetPresignUpload(ctx, uploadID)
+	if errors.Is(err, goredis.Nil) {
+		return vo.UploadCompleteResp{}, ErrPresignUploadNotFound
+	}
+	if err != nil {
+		zap.L().Error("redis.GetPresignUpload() failed", zap.String("uploadId", uploadID), zap.Error(err))
+		return vo.UploadCompleteResp{}, err
+	}
+	var ticket presignTicket
+	if err = json.Unmarshal(data, &ticket); err != nil {
+		return vo.UploadCompleteResp{}, err
+	}
+
+	// 2. 核对大小和 md5（简单上传的 ETag 即为 md5）
+	exists, err := svc.CosClient.ObjectExists(ctx, ticket.StagingPath)
+	if err != nil {
+		return vo.UploadCompleteResp{}, err
+	}
+	if !exists {
+		return vo.UploadCompleteResp{}, ErrPresignObjectMissing
+	}
+	info, err := svc.CosClient.HeadObject(ctx, ticket.StagingPath)
+	if err != nil {
+		return vo.UploadCompleteResp{}, err
+	}
+	if info.Size != ticket.Size || strings.ToLower(info.ETag) != ticket.Md5 {
+		zap.L().Warn("presigned upload mismatch", zap.String("uploadId", uploadID),
+			zap.Int64("size", info.Size), zap.Int64("wantSize", ticket.Size),
+			zap.String("etag", info.ETag), zap.String("wantMd5", ticket.Md5))
+		svc.deleteUploadedObject(ticket.StagingPath)
+		return vo.UploadCompleteResp{}, fmt.Errorf("%w: got %d bytes / %s", ErrPresignObjectMismatch, info.Size, info.ETag)
+	}
+
+	// 3. 读取文件头校验内容类型和位图尺寸，再读取完整文件计算检索特征并检查近似重复
+	head, err := svc.CosClient.GetObjectHead(ctx, ticket.StagingPath, presignSniffBytes)
+	if err != nil {
+		return vo.UploadCompleteResp{}, err
+	}
+	headFile, err := util.NewFileHeader(ticket.Name, head)
+	if err != nil {
+		return vo.UploadCompleteResp{}, err
+	}
+	errs, err := validateUploadContent(headFile, ticket.Type, newUploadLimits())
+	if err != nil {
+		return vo.UploadCompleteResp{}, err
+	}
+	if len(errs) > 0 {
+		svc.deleteUploadedObject(ticket.StagingPath)
+		svc.deletePresignTicket(ctx, ticket)
+		return vo.UploadCompleteResp{}, &UploadValidationError{Errors: errs}
+	}
+	var width, height int
+	if util.IsBitmapType(ticket.Type) {
+		width, height, _ = decodeImageSize(headFile)
+	}
+	resource := ticket.toEntity(ticket.Md5, ticket.Size, width, height)
+	similar, err := svc.storedImageFeatures(ctx, resource, ticket.StagingPath)
+	var validationErr *UploadValidationError
+	if errors.As(err, &validationErr) {
+		svc.deleteUploadedObject(ticket.StagingPath)
+		svc.deletePresignTicket(ctx, ticket)
+		return vo.UploadCompleteResp{}, err
+	}
+	if err != nil {
+		return vo.UploadCompleteResp{}, err
+	}
+
+	// 4. 复制前确认名称仍由本凭据占用，避免覆盖其他资源的文件
+	if err = confirmResourceName(ctx, ticket.ShortName, ticket.Name, ticket.ID, presignTicketGrace); err != nil {
+		if errors.Is(err, ErrResourceNameTaken) {
+			svc.deleteUploadedObject(ticket.StagingPath)
+			svc.deletePresignTicket(ctx, ticket)
+		}
+		return vo.UploadCompleteResp{}, err
+	}
+	if err = svc.CosClient.CopyObject(ctx, ticket.StagingPath, ticket.CosPath); err != nil {
+		return vo.UploadCompleteResp{}, err
+	}
+	svc.deleteUploadedObject(ticket.StagingPath)
+
+	// 5. 写入资源表
+	duplicates, err := mysql.InsertResourcesWithReport([]*do.Resource{resource})
+	if err != nil {
+		zap.L().Error("mysql.InsertResourcesWithReport() failed", zap.String("uploadId", uploadID), zap.Error(err))
+		svc.deleteUploadedObject(ticket.CosPath)
+		svc.deletePresignTicket(ctx, ticket)
+		return vo.UploadCompleteResp{}, err
+	}
+	svc.deletePresignTicket(ctx, ticket)
+	resp := vo.UploadCompleteResp{Resource: doResourceToDTO(*resource), Similar: similar}
+	if len(duplicates) > 0 {
+		// 名称由本凭据占用，正式路径不属于其他资源，可以直接删除
+		svc.deleteUploadedObject(ticket.CosPath)
+		resp.Status = model.BatchItemDuplicate
+		return resp, nil
+	}
+	resp.Status = model.BatchItemInserted
+	svc.purgeUniversityVariants(ctx, ticket.ShortName)
+	zap.L().Info("service.CompletePresignUpload() success", zap.String("uploadId", uploadID), zap.Int("id", resource.ID))
+	return resp, nil
+}
+
+// PresignDownload 签发有效资源的限时下载地址，下载不再经过本服务
+func (svc *ResourceService) PresignDownload(ctx context.Context, id int) (vo.PresignDownloadResp, error) {
+	resource, err := mysql.GetResourceByID(id)
+	if err != nil {
+		return vo.PresignDownloadResp{}, err
+	}
+	if resource.IsDeleted != model.ResourceIsActive {
+		return vo.PresignDownloadResp{}, gorm.ErrRecordNotFound
+	}
+	expire := presignExpire()
+	url, err := svc.CosClient.PresignGetURL(ctx, util.CosDownloadsPrefix+resource.ShortName+"/"+resource.Name, resource.Name, expire)
+	if err != nil {
+		return vo.PresignDownloadResp{}, err
+	}
+	return vo.PresignDownloadResp{URL: url, ExpireAt: time.Now().Add(expire).Format(time.DateTime)}, nil
+}
+
+// deletePresignTicket 删除凭据并释放名称，失败只记录日志（凭据和名称占用都会自动过期）
+func (svc *ResourceService) deletePresignTicket(ctx context.Context, ticket presignTicket) {
+	if err := redis.DeletePresignUpload(ctx, ticket.ID); err != nil {
+		zap.L().Warn("redis.DeletePresignUpload() failed, ticket will expire later", zap.String("uploadId", ticket.ID), zap.Error(err))
+	}
+	releaseResourceName(ticket.ShortName, ticket.Name, ticket.ID)
+}
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"logo_api/dao/mysql"
+	"logo_api/dao/redis"
+	"logo_api/model"
+	"logo_api/model/cos/dto"
+	"logo_api/model/cos/vo"
+	"logo_api/util"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrReconcileRunning 已有对账任务在运行
+var ErrReconcileRunning = errors.New("another reconcile job is running")
+
+const (
+	reconcileLockTTL = 10 * time.Minute
+	// orphanGracePeriod 最近上传的对象可能还没写入资源表（InsertResource 先上传后入库），不视为孤儿
+	orphanGracePeriod = 10 * time.Minute
+)
+
+// Reconcile 对账任务：列出 COS 中 beacon/downloads/ 下的对象，与资源表和 Redis 缓存进行比对，
+// 报告孤儿对象、文件丢失的资源、md5 不一致的资源以及悬空的缓存记录，并按请求进行修复
+// 有未完成的重命名任务时，对象正在新旧目录之间迁移，两个目录都跳过，避免把迁移中的资源和对象误判后修复
+func (svc *ResourceService) Reconcile(ctx context.Context, req dto.ReconcileReq) (vo.ReconcileResp, error) {
+	token, err := newLockToken()
+	if err != nil {
+		return vo.ReconcileResp{}, err
+	}
+	locked, err := redis.AcquireLock(ctx, redis.ReconcileLockKey, token, reconcileLockTTL)
+	if err != nil {
+		zap.L().Error("redis.AcquireLock() failed", zap.Error(err))
+		return vo.ReconcileResp{}, err
+	}
+	if !locked {
+		return vo.ReconcileResp{}, ErrReconcileRunning
+	}
+	defer func() {
+		if err := redis.ReleaseLock(context.Background(), redis.ReconcileLockKey, token); err != nil {
+			zap.L().Warn("redis.ReleaseLock() failed", zap.Error(err))
+		}
+	}()
+
+	// 1. 先列出 COS 对象，再读取资源表和缓存，尽量避免把刚入库的资源误判为丢失
+	prefix := util.CosDownloadsPrefix
+	if req.ShortName != "" {
+		prefix = fmt.Sprintf("%s%s/", util.CosDownloadsPrefix, req.ShortName)
+	}
+	objects, err := svc.CosClient.ListObjects(ctx, prefix)
+	if err != nil {
+		zap.L().Error("CosClient.ListObjects() failed", zap.String("prefix", prefix), zap.Error(err))
+		return vo.ReconcileResp{}, err
+	}
+	objectMap := make(map[string]util.ObjectInfo, len(objects))
+	for _, obj := range objects {
+		objectMap[obj.Key] = obj
+	}
+
+	resources, err := mysql.GetAllResourcesIncludeDeleted()
+	if err != nil {
+		return vo.ReconcileResp{}, err
+	}
+	pending, err := redis.ListPendingDelete(ctx)
+	if err != nil {
+		return vo.ReconcileResp{}, err
+	}
+	jobs, err := mysql.GetUnfinishedRenameJobs()
+	if err != nil {
+		return vo.ReconcileResp{}, err
+	}
+
+	resp := vo.ReconcileResp{
+		CheckedObjects: len(objects),
+		Orphans:        []dto.ReconcileObjectDTO{},
+		MissingFiles:   []dto.ReconcileResourceDTO{},
+		Md5Mismatches:  []dto.ReconcileResourceDTO{},
+		DanglingCache:  []string{},
+		Errors:         []string{},
+		Warnings:       []string{},
+	}
+	referenced := make(map[string]bool, len(resources)+len(pending))
+	renaming := make(map[string]bool, len(jobs)*2)
+	for _, job := range jobs {
+		renaming[job.OldShortName], renaming[job.NewShortName] = true, true
+		resp.Warnings = append(resp.Warnings, fmt.Sprintf("skipped %s and %s: rename job %d is %s",
+			job.OldShortName, job.NewShortName, job.ID, job.Status))
+	}
+	// inRenamingFolder COS 路径是否位于正在迁移的高校目录中
+	inRenamingFolder := func(cosPath string) bool {
+		shortName, _, ok := strings.Cut(strings.TrimPrefix(cosPath, util.CosDownloadsPrefix), "/")
+		return ok && renaming[shortName]
+	}
+
+	// 2. 资源表 -> COS：文件丢失、md5 不一致
+	for _, r := range resources {
+		if (req.ShortName != "" && r.ShortName != req.ShortName) || renaming[r.ShortName] {
+			continue
+		}
+		resp.CheckedResources++
+		cosPath := fmt.Sprintf("%s%s/%s", util.CosDownloadsPrefix, r.ShortName, r.Name)
+		referenced[cosPath] = true
+		item := dto.ReconcileResourceDTO{
+			ID:        r.ID,
+			ShortName: r.ShortName,
+			Name:      r.Name,
+			IsDeleted: r.IsDeleted,
+			Md5:       r.Md5,
+			Size:      r.Size,
+		}
+		obj, ok := objectMap[cosPath]
+		if !ok {
+			resp.MissingFiles = append(resp.MissingFiles, item)
+			continue
+		}
+		// 分块上传的 ETag 不是文件 md5，无法比对
+		etagIsMd5 := len(obj.ETag) == 32 && !strings.Contains(obj.ETag, "-")
+		if (etagIsMd5 && !strings.EqualFold(obj.ETag, r.Md5)) || obj.Size != int64(r.Size) {
+			if etagIsMd5 {
+				item.ActualMd5 = strings.ToLower(obj.ETag)
+			}
+			item.ActualSize = obj.Size
+			resp.Md5Mismatches = append(resp.Md5Mismatches, item)
+		}
+	}
+
+	// 3. Redis 缓存 -> COS：悬空的缓存记录
+	var danglingEncoded []string
+	for _, p := range pending {
+		cosPath, decodeErr := url.QueryUnescape(p.EncodedPath)
+		if decodeErr != nil {
+			danglingEncoded = append(danglingEncoded, p.EncodedPath)
+			resp.DanglingCache = append(resp.DanglingCache, p.EncodedPath)
+			continue
+		}
+		if !strings.HasPrefix(cosPath, prefix) || inRenamingFolder(cosPath) {
+			continue
+		}
+		resp.CheckedCache++
+		referenced[cosPath] = true
+		if _, ok := objectMap[cosPath]; !ok {
+			danglingEncoded = append(danglingEncoded, p.EncodedPath)
+			resp.DanglingCache = append(resp.DanglingCache, cosPath)
+		}
+	}
+
+	// 4. COS -> 资源表/缓存：孤儿对象
+	graceLine := time.Now().Add(-orphanGracePeriod)
+	for _, obj := range objects {
+		if referenced[obj.Key] || obj.LastModified.After(graceLine) || inRenamingFolder(obj.Key) {
+			continue
+		}
+		resp.Orphans = append(resp.Orphans, dto.ReconcileObjectDTO{CosPath: obj.Key, Size: obj.Size, ETag: obj.ETag})
+	}
+
+	// 5. 按请求修复
+	svc.applyReconcileFixes(ctx, req, &resp, danglingEncoded)
+
+	zap.L().Info("Reconcile() finished",
+		zap.Any("req", req),
+		zap.Int("orphans", len(resp.Orphans)),
+		zap.Int("missing", len(resp.MissingFiles)),
+		zap.Int("md5Mismatches", len(resp.Md5Mismatches)),
+		zap.Int("danglingCache", len(resp.DanglingCache)),
+		zap.Any("fixed", resp.Fixed))
+	return resp, nil
+}
+
+// applyReconcileFixes 根据对账报告执行修复，单项失败只记录到 Errors，不中断其他修复
+func (svc *ResourceService) applyReconcileFixes(ctx context.Context, req dto.ReconcileReq, resp *vo.ReconcileResp, danglingEncoded []string) {
+	if req.FixOrphans && len(resp.Orphans) > 0 {
+		for start := 0; start < len(resp.Orphans); start += util.MaxDeleteMultiKeys {
+			end := min(start+util.MaxDeleteMultiKeys, len(resp.Orphans))
+			paths := make([]string, 0, end-start)
+			for _, o := range resp.Orphans[start:end] {
+				paths = append(paths, o.CosPath)
+			}
+			failed, err := svc.CosClient.DeleteObjects(ctx, paths)
+			if err != nil {
+				resp.Errors = append(resp.Errors, fmt.Sprintf("delete orphans: %v", err))
+				continue
+			}
+			for _, p := range paths {
+				if reason, ok := failed[p]; ok {
+					resp.Errors = append(resp.Errors, fmt.Sprintf("delete orphan %s: %s", p, reason))
+					continue
+				}
+				resp.Fixed.Orphans++
+			}
+		}
+	}
+	if req.FixMissing {
+		for _, r := range resp.MissingFiles {
+			if r.IsDeleted != model.ResourceIsActive {
+				continue // 已经是删除状态，无需处理
+			}
+			if err := mysql.MarkResourceDeleted(r.ID, r.ShortName); err != nil {
+				resp.Errors = append(resp.Errors, fmt.Sprintf("mark resource %d deleted: %v", r.ID, err))
+				continue
+			}
+			resp.Fixed.Missing++
+		}
+	}
+	if req.FixMd5 {
+		for _, r := range resp.Md5Mismatches {
+			md5 := r.ActualMd5
+			if md5 == "" {
+				md5 = r.Md5 // ETag 不可用时只修正 size
+			}
+			if err := mysql.UpdateResourceFileInfo(r.ID, md5, int(r.ActualSize)); err != nil {
+				resp.Errors = append(resp.Errors, fmt.Sprintf("update resource %d md5: %v", r.ID, err))
+				continue
+			}
+			svc.InvalidateSource(r.Md5)
+			resp.Fixed.Md5Mismatches++
+		}
+	}
+	if req.FixDanglingCache && len(danglingEncoded) > 0 {
+		cosPaths := make([]string, 0, len(danglingEncoded))
+		for _, p := range danglingEncoded {
+			if cosPath, err := url.QueryUnescape(p); err == nil {
+				cosPaths = append(cosPaths, cosPath)
+			}
+		}
+		cacheKeys, err := redis.GetReverseMappings(ctx, cosPaths)
+		if err != nil {
+			resp.Errors = append(resp.Errors, fmt.Sprintf("get reverse mappings: %v", err))
+			cacheKeys = map[string]string{}
+		}
+		keys := make([]string, 0, len(cacheKeys))
+		for _, k := range cacheKeys {
+			keys = append(keys, k)
+		}
+		if err = redis.RemoveCacheEntries(ctx, keys, danglingEncoded); err != nil {
+			resp.Errors = append(resp.Errors, fmt.Sprintf("remove dangling cache: %v", err))
+		} else {
+			svc.InvalidateVariant(keys...)
+			resp.Fixed.DanglingCache = len(danglingEncoded)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"logo_api/dao/mysql"
+	"logo_api/dao/redis"
+	"logo_api/model"
+	"logo_api/model/university/do"
+	"logo_api/model/university/vo"
+	"logo_api/util"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrRenameJobRunning 该重命名任务正在被其他实例执行
+var ErrRenameJobRunning = errors.New("rename job is running")
+
+const (
+	renameJobLockTTL = 10 * time.Minute
+	// renameObjectMaxAttempts 单轮执行中每个对象的最大尝试次数，超过后任务标记为 failed，等待下次重试
+	renameObjectMaxAttempts = 3
+	renameRetryBaseDelay    = 500 * time.Millisecond
+)
+
+// RunRenameJob 执行（或继续执行）一个 short_name 重命名任务，把 COS 中旧目录下的对象逐个迁移到新目录
+// 每个对象按 复制 -> 标记 copied -> 删除旧对象 -> 改写 Redis 缓存 -> 标记 done 推进，
+// 任一步骤中断后再次执行都会从记录的状态继续，重复执行是安全的
+func (svc *ResourceService) RunRenameJob(ctx context.Context, id int) (do.RenameJob, error) {
+	job, err := mysql.GetRenameJob(id)
+	if err != nil {
+		return do.RenameJob{}, err
+	}
+	if job.Status == model.RenameJobDone {
+		return job, nil
+	}
+
+	token, err := newLockToken()
+	if err != nil {
+		return job, err
+	}
+	lockKey := redis.RenameJobLockPrefix + strconv.Itoa(id)
+	locked, err := redis.AcquireLock(ctx, lockKey, token, renameJobLockTTL)
+	if err != nil {
+		zap.L().Error("redis.AcquireLock() failed", zap.String("key", lockKey), zap.Error(err))
+		return job, err
+	}
+	if !locked {
+		return job, ErrRenameJobRunning
+	}
+	defer func() {
+		if err := redis.ReleaseLock(context.Background(), lockKey, token); err != nil {
+			zap.L().Warn("redis.ReleaseLock() failed", zap.String("key", lockKey), zap.Error(err))
+		}
+	}()
+	// 大目录的迁移可能超过锁的有效期，运行期间持续续期；锁丢失时 ctx 被取消，不再迁移后续对象
+	ctx, stopKeepAlive := keepLockAlive(ctx, lockKey, token, renameJobLockTTL)
+	defer stopKeepAlive()
+
+	if err = mysql.UpdateRenameJob(id, map[string]interface{}{"attempts": gorm.Expr("attempts + 1")}); err != nil {
+		return job, err
+	}
+
+	// 1. 任务刚创建：列出旧目录下的对象并持久化，之后的执行都以这份清单为准
+	if job.Status == model.RenameJobPending {
+		if err = svc.initRenameObjects(ctx, job); err != nil {
+			failRenameJob(id, err)
+			return job, err
+		}
+	}
+
+	// 2. 逐个迁移未完成的对象
+	objects, err := mysql.GetRenameObjects(id, true)
+	if err != nil {
+		failRenameJob(id, err)
+		return job, err
+	}
+	var lastErr error
+	for _, obj := range objects {
+		if ctx.Err() != nil {
+			lastErr = ctx.Err()
+			break
+		}
+		if err = svc.migrateRenameObject(ctx, obj); err != nil {
+			lastErr = err
+		}
+	}
+
+	// 3. 汇总进度
+	doneCount, err := mysql.CountDoneRenameObjects(id)
+	if err != nil {
+		return job, err
+	}
+	updates := map[string]interface{}{"done_count": doneCount}
+	if lastErr != nil {
+		updates["status"] = model.RenameJobFailed
+		updates["last_error"] = lastErr.Error()
+	} else {
+		updates["status"] = model.RenameJobDone
+		updates["last_error"] = nil
+	}
+	if err = mysql.UpdateRenameJob(id, updates); err != nil {
+		return job, err
+	}
+	job, _ = mysql.GetRenameJob(id)
+	zap.L().Info("RunRenameJob() finished",
+		zap.Int("id", id),
+		zap.String("from", job.OldShortName),
+		zap.String("to", job.NewShortName),
+		zap.String("status", job.Status),
+		zap.Int("done", job.DoneCount),
+		zap.Int("total", job.Total))
+	return job, lastErr
+}
+
+// initRenameObjects 列出旧目录下的对象，写入对象进度表
+func (svc *ResourceService) initRenameObjects(ctx context.Context, job do.RenameJob) error {
+	oldPrefix := fmt.Sprintf("%s%s/", util.CosDownloadsPrefix, job.OldShortName)
+	newPrefix := fmt.Sprintf("%s%s/", util.CosDownloadsPrefix, job.NewShortName)
+	list, err := svc.CosClient.ListObjects(ctx, oldPrefix)
+	if err != nil {
+		zap.L().Error("CosClient.ListObjects() failed", zap.String("prefix", oldPrefix), zap.Error(err))
+		return err
+	}
+	objects := make([]do.RenameObject, 0, len(list))
+	for _, o := range list {
+		objects = append(objects, do.RenameObject{
+			JobID:  job.ID,
+			OldKey: o.Key,
+			NewKey: newPrefix + strings.TrimPrefix(o.Key, oldPrefix),
+			Status: model.RenameObjectPending,
+		})
+	}
+	return mysql.InitRenameObjects(job.ID, objects)
+}
+
+// migrateRenameObject 迁移单个对象，失败时按指数退避重试
+func (svc *ResourceService) migrateRenameObject(ctx context.Context, obj do.RenameObject) error {
+	var err error
+	for attempt := 0; attempt < renameObjectMaxAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(renameRetryBaseDelay << (attempt - 1)):
+			}
+		}
+		obj.Attempts++
+		if err = svc.migrateRenameObjectOnce(ctx, &obj); err == nil {
+			return nil
+		}
+		zap.L().Warn("migrateRenameObject() failed",
+			zap.String("oldKey", obj.OldKey), zap.Int("attempt", attempt+1), zap.Error(err))
+		_ = mysql.UpdateRenameObject(obj.ID, map[string]interface{}{
+			"attempts":   obj.Attempts,
+			"last_error": err.Error(),
+		})
+	}
+	return fmt.Errorf("migrate %s: %w", obj.OldKey, err)
+}
+
+// migrateRenameObjectOnce 从对象当前状态继续推进一次
+func (svc *ResourceService) migrateRenameObjectOnce(ctx context.Context, obj *do.RenameObject) error {
+	if obj.Status == model.RenameObjectPending {
+		exists, err := svc.CosClient.ObjectExists(ctx, obj.OldKey)
+		if err != nil {
+			return err
+		}
+		if exists {
+			if err = svc.CosClient.CopyObject(ctx, obj.OldKey, obj.NewKey); err != nil {
+				return err
+			}
+		} else if exists, err = svc.CosClient.ObjectExists(ctx, obj.NewKey); err != nil {
+			return err
+		} else if !exists {
+			// 新旧路径都不存在：通常是过期的缓存文件已被清理任务删除，无需迁移
+			zap.L().Warn("Rename object not found in COS, skip", zap.String("oldKey", obj.OldKey))
+		}
+		if err = mysql.UpdateRenameObject(obj.ID, map[string]interface{}{
+			"status":   model.RenameObjectCopied,
+			"attempts": obj.Attempts,
+		}); err != nil {
+			return err
+		}
+		obj.Status = model.RenameObjectCopied
+	}
+
+	// 删除旧对象（对象不存在时 COS 同样返回成功）并改写缓存记录
+	if err := svc.CosClient.DeleteObject(ctx, obj.OldKey); err != nil {
+		return err
+	}
+	if err := redis.RenameCachePath(ctx, obj.OldKey, obj.NewKey); err != nil {
+		zap.L().Error("redis.RenameCachePath() failed", zap.String("oldKey", obj.OldKey), zap.Error(err))
+		return err
+	}
+	if err := mysql.UpdateRenameObject(obj.ID, map[string]interface{}{
+		"status":     model.RenameObjectDone,
+		"attempts":   obj.Attempts,
+		"last_error": nil,
+	}); err != nil {
+		return err
+	}
+	obj.Status = model.RenameObjectDone
+	return nil
+}
+
+// failRenameJob 记录任务级别的失败原因
+func failRenameJob(id int, cause error) {
+	if err := mysql.UpdateRenameJob(id, map[string]interface{}{
+		"status":     model.RenameJobFailed,
+		"last_error": cause.Error(),
+	}); err != nil {
+		zap.L().Error("failRenameJob() failed", zap.Int("id", id), zap.Error(err))
+	}
+}
+
+// ResumeRenameJobs 继续执行所有未完成的重命名任务，用于服务重启后和定时触发时恢复
+func (svc *ResourceService) ResumeRenameJobs(ctx context.Context) {
+	jobs, err := mysql.GetUnfinishedRenameJobs()
+	if err != nil {
+		return
+	}
+	for _, job := range jobs {
+		if ctx.Err() != nil {
+			return
+		}
+		if _, err = svc.RunRenameJob(ctx, job.ID); err != nil && !errors.Is(err, ErrRenameJobRunning) {
+			zap.L().Error("RunRenameJob() failed, will retry later", zap.Int("id", job.ID), zap.Error(err))
+		}
+	}
+}
+
+// GetRenameJob 查询重命名任务详情及每个对象的迁移进度
+func GetRenameJob(id int) (vo.RenameJobResp, error) {
+	job, err := mysql.GetRenameJob(id)
+	if err != nil {
+		return vo.RenameJobResp{}, err
+	}
+	objects, err := mysql.GetRenameObjects(id, false)
+	if err != nil {
+		return vo.RenameJobResp{}, err
+	}
+	return vo.RenameJobResp{Job: job, Objects: objects}, nil
+}
+
+// GetRenameJobList 查询重命名任务列表
+func GetRenameJobList(status string) (vo.RenameJobListResp, error) {
+	jobs, err := mysql.GetRenameJobList(status)
+	if err != nil {
+		return vo.RenameJobListResp{}, err
+	}
+	return vo.RenameJobListResp{List: jobs, TotalCount: len(jobs)}, nil
+}
+
+// getObjectDuringRename 读取资源文件；若该高校正在重命名且对象尚未迁移到新目录，则回退到旧目录读取
+// 所有按 short_name 目录读取资源内容的地方（直接读取、缓存命中、SVG 转位图、特征回填）都应通过它读取
+func (svc *ResourceService) getObjectDuringRename(resourceName, shortName string) ([]byte, error) {
+	data, err := svc.CosClient.GetObjectByResourceName(resourceName, shortName)
+	if err == nil {
+		return data, nil
+	}
+	job, jobErr := mysql.GetUnfinishedRenameJobByShortName(shortName)
+	if jobErr != nil {
+		return nil, err
+	}
+	zap.L().Info("Object not found in new folder, fallback to old folder of unfinished rename job",
+		zap.String("name", resourceName), zap.String("from", job.OldShortName), zap.String("to", shortName))
+	return svc.CosClient.GetObjectByResourceName(resourceName, job.OldShortName)
+}
@@ -2,9 +2,11 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	goredis "github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 	"logo_api/dao/mysql"
 	"logo_api/dao/redis"
 	"logo_api/model"
@@ -20,10 +22,14 @@ import (
 
 type ResourceService struct {
 	CosClient *util.CosClient
+	L1        *util.ByteCache // 进程内 L1 缓存，位于 Redis 和 COS 之前
 }
 
 func NewResourceService(cosClient *util.CosClient) *ResourceService {
-	return &ResourceService{CosClient: cosClient}
+	return &ResourceService{
+		CosClient: cosClient,
+		L1:        newL1Cache(settings.Config.L1CacheConfig),
+	}
 }
 
 // GetLogo 获取logo文件二进制数据、相关字段数据
@@ -38,16 +44,24 @@ func (svc *ResourceService) GetLogo(req dto.ResourceGetLogoReq) ([]byte, string,
 	// 1. 缓存查找 (仅对位图进行缓存查找)
 	if ext != "svg" {
 		cacheKey := generateCacheKey(preName, ext, bgColor, size, width, height)
+		// 1a. 先查进程内 L1 缓存，命中则无需访问 Redis 和 COS
+		if data, resourceName, ok := svc.L1.Get(l1VariantKeyPrefix + cacheKey); ok {
+			zap.L().Info("L1 Cache Hit - Serving from memory", zap.String("key", cacheKey))
+			return data, ext, resourceName, nil
+		}
+		// 1b. 再查 Redis 映射
 		cosPath, err := redis.GetCacheMapping(ctx, cacheKey)
 		if err == nil && cosPath != "" {
 			// 缓存命中 (Key 1命中): 尝试从 COS 获取文件
-			parts := strings.Split(cosPath, "/")
-			if len(parts) >= 3 {
-				shortName := parts[1]
-				resourceName := parts[2]
-				data, err := svc.CosClient.GetObjectByResourceName(resourceName, shortName)
+			// 路径格式: beacon/downloads/<short_name>/<name>
+			parts := strings.SplitN(strings.TrimPrefix(cosPath, util.CosDownloadsPrefix), "/", 2)
+			if len(parts) == 2 {
+				shortName := parts[0]
+				resourceName := parts[1]
+				data, err := svc.getObjectDuringRename(resourceName, shortName)
 				if err == nil {
 					zap.L().Info("Cache Hit - Serving from COS via Redis mapping", zap.String("key", cacheKey))
+					svc.L1.SetWithTTL(l1VariantKeyPrefix+cacheKey, data, resourceName, variantCacheTTL)
 					return data, ext, resourceName, nil
 				}
 				// COS 文件获取失败，可能已被清理，删除脏缓存，继续执行生成逻辑
@@ -64,36 +78,51 @@ func (svc *ResourceService) GetLogo(req dto.ResourceGetLogoReq) ([]byte, string,
 	var resource settings.UniversityResources
 	var err error
 
+	// 名称也可以是识别码、英文全称、官网域名、别名或拼音，先解析为 short_name；缓存键仍使用原始名称
+	lookupName := preName
+	resolved := false
+	if match, err := ResolveUniversity(preName); err == nil {
+		lookupName, resolved = match.ShortName, true
+	}
+
 	// 先找出来需要计算的主文件
 	if ext == "svg" {
-		resource, err = mysql.QueryFromNameAndSvg(preName, ext)
+		resource, err = mysql.QueryFromNameAndSvg(lookupName, ext)
 		if err != nil {
 			zap.L().Error("Could not find source SVG file for conversion", zap.String("name", preName), zap.Error(err))
-			return nil, ext, "", err
 		}
 	} else {
-		resource, err = mysql.QueryFromNameAndBitmapInfo(preName, ext, size, width, height, bgColor)
+		resource, err = mysql.QueryFromNameAndBitmapInfo(lookupName, ext, size, width, height, bgColor)
 	}
 	if err != nil {
 		zap.L().Error("mysql.Query() failed", zap.Error(err))
+		// 找不到资源：名称无法解析为任何高校时附带"你是不是要找"的建议
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ext, "", newLogoNotFoundError(preName, resolved)
+		}
 		return nil, ext, "", err
 	}
 
 	// 如果是 svg 转出来的位图，说明缓存没有生效
 	if ext != "svg" && resource.ResourceType == "svg" {
-		data, info, err := svc.CosClient.GetObjectByResourceNameAndSvgToBitmap(
-			resource.ResourceName, resource.Title, resource.ShortName,
+		svgData, err := svc.getObjectDuringRename(resource.ResourceName, resource.ShortName)
+		if err != nil {
+			zap.L().Error("svc.getObjectDuringRename() failed", zap.Error(err))
+			return nil, ext, "", err
+		}
+		data, info, err := svc.CosClient.SvgToBitmap(
+			svgData, resource.ResourceName, resource.Title, resource.ShortName,
 			ext, size, width, height, bgColor,
 		)
 		if err != nil {
-			zap.L().Error("CosClient.GetObjectByResourceNameAndSvgToBitmap() failed", zap.Error(err))
+			zap.L().Error("CosClient.SvgToBitmap() failed", zap.Error(err))
 			return nil, ext, "", err
 		}
 		// 4. 转换成功，执行三层缓存写入
 		fullCosPath := fmt.Sprintf("beacon/downloads/%s/%s", info.ShortName, info.ResourceName) // 这里应该进行 ResourceName 的中文路径转换！
 		cacheKey := generateCacheKey(preName, ext, bgColor, size, width, height)
 		//ttl := time.Hour // 缓存过期时间
-		localTtl := time.Second * 20
+		localTtl := variantCacheTTL
 		// 4a. 写入 Key 1: hash -> cosPath (查询映射)
 		if err = redis.SetCacheMapping(ctx, cacheKey, fullCosPath); err != nil {
 			zap.L().Warn("redis.SetCacheMapping() failed", zap.Error(err))
@@ -110,15 +139,31 @@ func (svc *ResourceService) GetLogo(req dto.ResourceGetLogoReq) ([]byte, string,
 		if err != nil {
 			zap.L().Warn("redis.AddPendingDelete() failed", zap.Error(err))
 		}
+		// 4d. 记录缓存文件大小 (用于缓存统计)
+		if err = redis.SetCacheSize(ctx, fullCosPath, int64(len(data))); err != nil {
+			zap.L().Warn("redis.SetCacheSize() failed", zap.Error(err))
+		}
+		// 4e. 写入 L1 缓存
+		svc.L1.SetWithTTL(l1VariantKeyPrefix+cacheKey, data, info.ResourceName, variantCacheTTL)
 
 		return data, ext, info.ResourceName, nil
 	}
-	// 可以直接获取到这张图片
-	data, err := svc.CosClient.GetObjectByResourceName(resource.ResourceName, resource.ShortName)
+	// 可以直接获取到这张图片，源文件按 md5 缓存在 L1 中
+	sourceKey := l1SourceKeyPrefix + resource.ResourceMd5
+	if resource.ResourceMd5 != "" {
+		if data, _, ok := svc.L1.Get(sourceKey); ok {
+			zap.L().Info("L1 Cache Hit - Serving source resource from memory", zap.String("md5", resource.ResourceMd5))
+			return data, ext, resource.ResourceName, nil
+		}
+	}
+	data, err := svc.getObjectDuringRename(resource.ResourceName, resource.ShortName)
 	if err != nil {
-		zap.L().Error("CosClient.GetObjectByResourceName() failed", zap.Error(err))
+		zap.L().Error("svc.getObjectDuringRename() failed", zap.Error(err))
 		return nil, ext, "", err
 	}
+	if resource.ResourceMd5 != "" {
+		svc.L1.Set(sourceKey, data, resource.ResourceName)
+	}
 	return data, ext, resource.ResourceName, nil
 
 }
@@ -138,25 +183,50 @@ func GetResourceByName(name string) (do.Resource, error) {
 }
 
 // InsertResource 插入资源. 不需要插入Redis缓存，缓存只给转换后的图片使用
-func InsertResource(ctx context.Context, req dto.ResourceInsertReq) error {
-	// 1. 初始化 COS 客户端
+// 返回同一高校内的近似重复资源（策略为 warn 时），策略为 block 时以 *UploadValidationError 拒绝
+func InsertResource(ctx context.Context, req dto.ResourceInsertReq) ([]dto.SimilarResource, error) {
+	// 0. 上传校验，失败时返回 *UploadValidationError
+	if err := validateUpload(uploadCandidate{
+		File:        req.File,
+		Type:        req.Type,
+		Name:        req.Name,
+		Title:       req.Title,
+		ShortName:   req.ShortName,
+		UsedForEdge: req.UsedForEdge,
+	}); err != nil {
+		return nil, err
+	}
+	// 1. 转换为 Entity，并按感知哈希检查近似重复
+	doResource, err := req.ToEntity()
+	if err != nil {
+		zap.L().Error("dto.ResourceInsertReq.ToEntity() failed", zap.Any("req", req), zap.Error(err))
+		return nil, err
+	}
+	similar, err := checkNearDuplicates(doResource, 0)
+	if err != nil {
+		return nil, err
+	}
+	// 同名资源会覆盖 COS 中的文件：上传到写库期间占用名称，已有同名资源或同名上传时拒绝
+	owner, err := newLockToken()
+	if err != nil {
+		return nil, err
+	}
+	if err = reserveResourceName(ctx, req.ShortName, req.Name, owner, resourceNameReserveTTL); err != nil {
+		return nil, err
+	}
+	defer releaseResourceName(req.ShortName, req.Name, owner)
+	// 2. 初始化 COS 客户端
 	cosClient, err := util.NewClient(settings.Config.CosConfig)
 	if err != nil {
 		zap.L().Error("util.NewClient() failed", zap.Error(err))
-		return err
+		return nil, err
 	}
-	// 2. 上传对象到 COS
+	// 3. 上传对象到 COS
 	uploadCosPath := fmt.Sprintf("beacon/downloads/%s/%s", req.ShortName, req.Name)
 	err = cosClient.UploadObject(ctx, req.File, uploadCosPath)
 	if err != nil {
 		zap.L().Error("cosClient.UploadObject() failed", zap.String("uploadCosPath", uploadCosPath), zap.Error(err))
-		return err
-	}
-	// 3. 转换为 Entity
-	doResource, err := req.ToEntity()
-	if err != nil {
-		zap.L().Error("dto.ResourceInsertReq.ToEntity() failed", zap.Any("req", req), zap.Error(err))
-		return err
+		return nil, err
 	}
 	// 4. 调用 DAO 插入数据 (包含原有的 University 统计更新)
 	doResources := []*do.Resource{doResource}
@@ -168,30 +238,34 @@ func InsertResource(ctx context.Context, req dto.ResourceInsertReq) error {
 		if delErr := cosClient.DeleteObject(context.Background(), uploadCosPath); err != nil {
 			zap.L().Error("cosClient.DeleteObject() failed during rollback", zap.Error(delErr))
 		}
-		return err
+		return nil, err
 	}
-	return nil
+	return similar, nil
 }
 
-func GetResourceList(req dto.ResourceGetListReq) (vo.ResourceResp, error) {
+func GetResourceList(req dto.ResourceGetListReq) (vo.ResourceListResp, error) {
 	var (
-		doResourceList  []do.Resource
-		dtoResourceList []dto.ResourceInfoDTO
-		voResourceList  vo.ResourceResp
-		err             error
+		doResourceList []do.Resource
+		voResourceList vo.ResourceListResp
+		totalCount     int64
+		err            error
 	)
-	if doResourceList, err = mysql.GetResourceList(req); err != nil {
-		zap.L().Error("mysql.GetResourceList() failed", zap.String("name", req.Name), zap.Error(err))
-		return vo.ResourceResp{}, err
+	if doResourceList, totalCount, voResourceList.NextCursor, err = mysql.GetResourceList(req); err != nil {
+		if !errors.Is(err, mysql.ErrInvalidCursor) {
+			zap.L().Error("mysql.GetResourceList() failed", zap.String("name", req.Name), zap.Error(err))
+		}
+		return vo.ResourceListResp{}, err
 	}
+	voResourceList.List = make([]dto.ResourceInfoDTO, 0, len(doResourceList))
 	for _, resource := range doResourceList {
-		var dtoResource dto.ResourceInfoDTO
-		dtoResource = doResourceToDTO(resource)
-		dtoResourceList = append(dtoResourceList, dtoResource)
+		voResourceList.List = append(voResourceList.List, doResourceToDTO(resource))
+	}
+	voResourceList.TotalCount = int(totalCount)
+	voResourceList.PageSize = req.PageSize
+	if req.Cursor == "" {
+		voResourceList.Page = req.Page
 	}
-	voResourceList.List = dtoResourceList
-	voResourceList.TotalCount = len(dtoResourceList)
-	zap.L().Info("GetResourceList() success", zap.String("name", req.Name), zap.Int("success count", voResourceList.TotalCount))
+	zap.L().Info("GetResourceList() success", zap.String("name", req.Name), zap.Int("success count", len(voResourceList.List)), zap.Int64("totalCount", totalCount))
 	return voResourceList, nil
 }
 
@@ -267,5 +341,10 @@ func doResourceToDTO(resource do.Resource) dto.ResourceInfoDTO {
 	dtoResource.IsDeleted = resource.IsDeleted
 	dtoResource.BackgroundColor = resource.BackgroundColor
 	dtoResource.CosURL = fmt.Sprintf("%s/%s/%s", model.BeaconCosPreURL, resource.ShortName, url.PathEscape(resource.Name))
+	dtoResource.Version = resource.Version
+	dtoResource.ChainID = resource.ChainID()
+	dtoResource.PrevID = resource.PrevID
+	dtoResource.PHash = resource.PHash
+	dtoResource.Palette = resource.Palette
 	return dtoResource
 }
@@ -0,0 +1,413 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"io"
+	"logo_api/dao/mysql"
+	"logo_api/model"
+	"logo_api/model/resource/do"
+	"logo_api/model/resource/dto"
+	"logo_api/model/resource/vo"
+	"logo_api/util"
+	"path"
+	"strings"
+)
+
+const (
+	// batchMaxEntries 单个压缩包最多处理的文件数
+	batchMaxEntries = 1000
+	// batchMaxTotalSize 单个压缩包内全部文件解压后的总大小上限，通过校验的文件在写库前都保存在内存中
+	batchMaxTotalSize int64 = 256 << 20
+	// batchManifestName 压缩包内清单文件名
+	batchManifestName = "manifest.json"
+)
+
+var (
+	// ErrBatchArchiveInvalid 压缩包无法解析
+	ErrBatchArchiveInvalid = errors.New("archive is not a valid zip file")
+	// ErrBatchManifestInvalid 清单不是合法的 JSON
+	ErrBatchManifestInvalid = errors.New("manifest is not valid json")
+	// ErrBatchTooManyEntries 压缩包内文件过多
+	ErrBatchTooManyEntries = fmt.Errorf("archive contains more than %d files", batchMaxEntries)
+	// ErrBatchTooLarge 压缩包内文件解压后的总大小超过上限
+	ErrBatchTooLarge = fmt.Errorf("archive uncompressed size exceeds %d MB", batchMaxTotalSize>>20)
+)
+
+// batchEntry 压缩包中一个待处理的文件
+type batchEntry struct {
+	item     *vo.BatchUploadItem
+	req      dto.ResourceInsertReq
+	resource *do.Resource
+	cosPath  string
+	// readBytes 从压缩包中实际读出的字节数
+	readBytes int64
+}
+
+// BatchInsertResources 批量上传：逐个文件走 validateUpload + ToEntity，通过校验的文件上传 COS 后在同一个事务中写入，返回每个文件的处理结果
+func (svc *ResourceService) BatchInsertResources(ctx context.Context, req dto.ResourceBatchInsertReq) (vo.BatchUploadResp, error) {
+	// 1. 打开压缩包
+	f, err := req.Archive.Open()
+	if err != nil {
+		return vo.BatchUploadResp{}, err
+	}
+	defer f.Close()
+	zr, err := zip.NewReader(f, req.Archive.Size)
+	if err != nil {
+		zap.L().Info("zip.NewReader() failed", zap.String("filename", req.Archive.Filename), zap.Error(err))
+		return vo.BatchUploadResp{}, ErrBatchArchiveInvalid
+	}
+
+	// 2. 解析清单：表单中的 manifest 优先，其次是压缩包根目录的 manifest.json
+	manifest, err := loadBatchManifest(zr, req)
+	if err != nil {
+		return vo.BatchUploadResp{}, err
+	}
+	manifestFiles := make(map[string]dto.BatchManifestEntry, len(manifest.Files))
+	for _, mf := range manifest.Files {
+		manifestFiles[path.Clean(mf.Path)] = mf
+	}
+
+	files := make([]*zip.File, 0, len(zr.File))
+	for _, zf := range zr.File {
+		if skipBatchEntry(zf) {
+			continue
+		}
+		files = append(files, zf)
+	}
+	if len(files) > batchMaxEntries {
+		return vo.BatchUploadResp{}, ErrBatchTooManyEntries
+	}
+	// 超过单文件上限的文件不会被读取，其余文件按声明的解压大小先检查一次总量
+	limits := newUploadLimits()
+	var declaredSize uint64
+	for _, zf := range files {
+		if zf.UncompressedSize64 <= uint64(limits.maxSizeBytes) {
+			declaredSize += zf.UncompressedSize64
+		}
+	}
+	if declaredSize > uint64(batchMaxTotalSize) {
+		return vo.BatchUploadResp{}, ErrBatchTooLarge
+	}
+
+	// 3. 逐个文件校验，批次内的同名文件和相同内容只保留第一个
+	// 声明的大小可能与实际不符，读取时再按实际读出的字节数累计
+	titles := make(map[string]string)
+	seenNames := make(map[string]string)
+	seenContents := make(map[string]string)
+	items := make([]*vo.BatchUploadItem, 0, len(files))
+	var accepted []*batchEntry
+	var readSize int64
+	owner, err := newLockToken()
+	if err != nil {
+		return vo.BatchUploadResp{}, err
+	}
+	defer func() {
+		// 通过校验的文件都占用了名称
+		for _, entry := range accepted {
+			releaseResourceName(entry.req.ShortName, entry.req.Name, owner)
+		}
+	}()
+	for _, zf := range files {
+		entry, prepErr := svc.prepareBatchEntry(zf, manifest, manifestFiles, titles, limits)
+		items = append(items, entry.item)
+		if prepErr != nil {
+			return vo.BatchUploadResp{}, prepErr
+		}
+		if readSize += entry.readBytes; readSize > batchMaxTotalSize {
+			zap.L().Info("archive uncompressed size exceeds the limit", zap.String("archive", req.Archive.Filename), zap.Int64("readSize", readSize))
+			return vo.BatchUploadResp{}, ErrBatchTooLarge
+		}
+		if entry.item.Status != "" {
+			continue
+		}
+		nameKey := entry.req.ShortName + "/" + entry.req.Name
+		if prev, ok := seenNames[nameKey]; ok {
+			rejectBatchItem(entry.item, "name", "duplicate_in_batch", "name %q is already used by %s in this archive", entry.req.Name, prev)
+			continue
+		}
+		contentKey := fmt.Sprintf("%s_%s_%d", entry.resource.ShortName, entry.resource.Md5, entry.resource.Size)
+		if prev, ok := seenContents[contentKey]; ok {
+			entry.item.Status = model.BatchItemDuplicate
+			entry.item.Message = "same content as " + prev
+			continue
+		}
+		// 已有同名资源：内容相同视为重复，否则会覆盖 COS 中的文件，拒绝
+		existing, dbErr := mysql.GetResourceByStatus(entry.req.Name, entry.req.ShortName, model.ResourceIsActive)
+		switch {
+		case dbErr == nil && existing.Md5 == entry.resource.Md5 && existing.Size == entry.resource.Size:
+			entry.item.Status = model.BatchItemDuplicate
+			entry.item.ID = existing.ID
+			entry.item.Message = "resource already exists"
+			continue
+		case dbErr != nil && !errors.Is(dbErr, gorm.ErrRecordNotFound):
+			return vo.BatchUploadResp{}, dbErr
+		}
+		// 占用名称直到写库结束，防止与其他上传同时写入同一个 COS 路径
+		if dbErr = reserveResourceName(ctx, entry.req.ShortName, entry.req.Name, owner, resourceNameReserveTTL); dbErr != nil {
+			if !errors.Is(dbErr, ErrResourceNameTaken) {
+				return vo.BatchUploadResp{}, dbErr
+			}
+			rejectBatchItem(entry.item, "name", "name_taken", "name %q has been taken in university %q", entry.req.Name, entry.req.ShortName)
+			continue
+		}
+		seenNames[nameKey] = entry.item.Path
+		seenContents[contentKey] = entry.item.Path
+		accepted = append(accepted, entry)
+	}
+
+	// 4. 上传 COS，上传失败的文件不参与写库
+	var uploaded []*batchEntry
+	for _, entry := range accepted {
+		if err = svc.CosClient.UploadObject(ctx, entry.req.File, entry.cosPath); err != nil {
+			zap.L().Error("CosClient.UploadObject() failed", zap.String("uploadCosPath", entry.cosPath), zap.Error(err))
+			entry.item.Status = model.BatchItemFailed
+			entry.item.Message = "upload failed"
+			continue
+		}
+		uploaded = append(uploaded, entry)
+	}
+
+	// 5. 一个事务写入全部资源；事务失败时删除本批上传的文件
+	resources := make([]*do.Resource, 0, len(uploaded))
+	for _, entry := range uploaded {
+		resources = append(resources, entry.resource)
+	}
+	duplicates, err := mysql.InsertResourcesWithReport(resources)
+	if err != nil {
+		zap.L().Error("mysql.InsertResourcesWithReport() failed", zap.Int("count", len(resources)), zap.Error(err))
+		for _, entry := range uploaded {
+			svc.deleteUploadedObject(entry.cosPath)
+			entry.item.Status = model.BatchItemFailed
+			entry.item.Message = "database insert failed, batch rolled back"
+		}
+		return summarizeBatch(items), nil
+	}
+	isDuplicate := make(map[*do.Resource]bool, len(duplicates))
+	for _, d := range duplicates {
+		isDuplicate[d] = true
+	}
+	purged := make(map[string]bool)
+	for _, entry := range uploaded {
+		if isDuplicate[entry.resource] {
+			// 数据库中已有相同内容（不同文件名）的资源，刚上传的路径此前不存在，可以直接删除
+			svc.deleteUploadedObject(entry.cosPath)
+			entry.item.Status = model.BatchItemDuplicate
+			entry.item.Message = "same content already exists in this university"
+			continue
+		}
+		entry.item.Status = model.BatchItemInserted
+		entry.item.ID = entry.resource.ID
+		if !purged[entry.resource.ShortName] {
+			purged[entry.resource.ShortName] = true
+			svc.purgeUniversityVariants(ctx, entry.resource.ShortName)
+		}
+	}
+
+	resp := summarizeBatch(items)
+	zap.L().Info("service.BatchInsertResources() done", zap.String("archive", req.Archive.Filename),
+		zap.Int("total", resp.Total), zap.Int("inserted", resp.Inserted),
+		zap.Int("duplicates", resp.Duplicates), zap.Int("rejected", resp.Rejected), zap.Int("failed", resp.Failed))
+	return resp, nil
+}
+
+// prepareBatchEntry 读取压缩包中的单个文件并完成校验；校验失败时 item.Status 为 rejected，只有数据库等系统错误才返回 error
+func (svc *ResourceService) prepareBatchEntry(zf *zip.File, manifest dto.BatchManifest, manifestFiles map[string]dto.BatchManifestEntry,
+	titles map[string]string, limits uploadLimits) (*batchEntry, error) {
+	entryPath := path.Clean(zf.Name)
+	meta := manifestFiles[entryPath]
+	entry := &batchEntry{item: &vo.BatchUploadItem{Path: entryPath}}
+
+	// 1. 元数据：清单条目 > 清单/表单默认值 > 从路径推断（第一级目录作为 shortName）
+	shortName := firstNonEmpty(meta.ShortName, manifest.ShortName)
+	if shortName == "" {
+		if dir, _, ok := strings.Cut(entryPath, "/"); ok {
+			shortName = dir
+		}
+	}
+	name := firstNonEmpty(meta.Name, path.Base(entryPath))
+	fileType := firstNonEmpty(meta.Type, path.Ext(name))
+	title := firstNonEmpty(meta.Title, manifest.Title)
+	if title == "" && shortName != "" {
+		cached, ok := titles[shortName]
+		if !ok {
+			university, err := mysql.GetUniversityByShortName(shortName)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return entry, err
+			}
+			cached = university.Title
+			titles[shortName] = cached
+		}
+		title = cached
+	}
+	entry.item.ShortName = shortName
+	entry.item.Name = name
+	if shortName == "" {
+		rejectBatchItem(entry.item, "shortName", "required", "shortName is missing for %s", entryPath)
+		return entry, nil
+	}
+	// 清单中的 name 会拼进 COS 路径，只能是单个文件名
+	if name == "" || path.Base(name) != name || strings.Contains(name, "..") {
+		rejectBatchItem(entry.item, "name", "invalid", "name %q must be a plain file name without directories", name)
+		return entry, nil
+	}
+
+	// 2. 读取文件内容，超过上限的只读到上限 + 1 字节，交给 validateUpload 报告 too_large
+	if zf.UncompressedSize64 > uint64(limits.maxSizeBytes) {
+		rejectBatchItem(entry.item, "file", "too_large", "file size %d bytes exceeds the limit of %d bytes", zf.UncompressedSize64, limits.maxSizeBytes)
+		return entry, nil
+	}
+	rc, err := zf.Open()
+	if err != nil {
+		rejectBatchItem(entry.item, "file", "corrupted", "entry could not be opened: %v", err)
+		return entry, nil
+	}
+	data, err := io.ReadAll(io.LimitReader(rc, limits.maxSizeBytes+1))
+	rc.Close()
+	entry.readBytes = int64(len(data))
+	if err != nil {
+		rejectBatchItem(entry.item, "file", "corrupted", "entry could not be read: %v", err)
+		return entry, nil
+	}
+	fileHeader, err := util.NewFileHeader(path.Base(entryPath), data)
+	if err != nil {
+		return entry, err
+	}
+
+	// 3. 与单文件上传相同的校验和实体转换
+	entry.req = dto.ResourceInsertReq{
+		File:            fileHeader,
+		Title:           title,
+		ShortName:       shortName,
+		Name:            name,
+		Type:            util.NormalizeFileType(fileType),
+		UsedForEdge:     meta.UsedForEdge,
+		BackgroundColor: util.NormalizeColor(meta.BackgroundColor),
+	}
+	if err = validateUpload(uploadCandidate{
+		File:        entry.req.File,
+		Type:        entry.req.Type,
+		Name:        entry.req.Name,
+		Title:       entry.req.Title,
+		ShortName:   entry.req.ShortName,
+		UsedForEdge: entry.req.UsedForEdge,
+	}); err != nil {
+		var validationErr *UploadValidationError
+		if errors.As(err, &validationErr) {
+			entry.item.Status = model.BatchItemRejected
+			entry.item.Errors = validationErr.Errors
+			return entry, nil
+		}
+		return entry, err
+	}
+	entry.resource, err = entry.req.ToEntity()
+	if err != nil {
+		zap.L().Error("dto.ResourceInsertReq.ToEntity() failed", zap.String("path", entryPath), zap.Error(err))
+		return entry, err
+	}
+	similar, err := checkNearDuplicates(entry.resource, 0)
+	var validationErr *UploadValidationError
+	if errors.As(err, &validationErr) {
+		entry.item.Status = model.BatchItemRejected
+		entry.item.Errors = validationErr.Errors
+		entry.item.Similar = similar
+		return entry, nil
+	}
+	if err != nil {
+		return entry, err
+	}
+	entry.item.Similar = similar
+	entry.cosPath = util.CosDownloadsPrefix + shortName + "/" + name
+	return entry, nil
+}
+
+// loadBatchManifest 解析清单，没有清单时返回只包含表单默认值的空清单
+func loadBatchManifest(zr *zip.Reader, req dto.ResourceBatchInsertReq) (dto.BatchManifest, error) {
+	var raw []byte
+	if req.Manifest != "" {
+		raw = []byte(req.Manifest)
+	} else {
+		for _, zf := range zr.File {
+			if path.Clean(zf.Name) != batchManifestName {
+				continue
+			}
+			rc, err := zf.Open()
+			if err != nil {
+				return dto.BatchManifest{}, ErrBatchManifestInvalid
+			}
+			raw, err = io.ReadAll(io.LimitReader(rc, 1<<20))
+			rc.Close()
+			if err != nil {
+				return dto.BatchManifest{}, ErrBatchManifestInvalid
+			}
+			break
+		}
+	}
+	var manifest dto.BatchManifest
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &manifest); err != nil {
+			zap.L().Info("json.Unmarshal() manifest failed", zap.Error(err))
+			return dto.BatchManifest{}, ErrBatchManifestInvalid
+		}
+	}
+	manifest.ShortName = firstNonEmpty(manifest.ShortName, req.ShortName)
+	manifest.Title = firstNonEmpty(manifest.Title, req.Title)
+	return manifest, nil
+}
+
+// skipBatchEntry 目录、清单文件以及 macOS 打包产生的元数据文件不作为资源处理
+func skipBatchEntry(zf *zip.File) bool {
+	name := path.Clean(zf.Name)
+	base := path.Base(name)
+	return zf.FileInfo().IsDir() ||
+		name == batchManifestName ||
+		strings.HasPrefix(name, "__MACOSX/") ||
+		strings.HasPrefix(base, ".")
+}
+
+// rejectBatchItem 把文件标记为校验未通过
+func rejectBatchItem(item *vo.BatchUploadItem, field, code, format string, args ...interface{}) {
+	item.Status = model.BatchItemRejected
+	item.Errors = append(item.Errors, model.FieldError{Field: field, Code: code, Message: fmt.Sprintf(format, args...)})
+}
+
+// deleteUploadedObject 删除未能写入资源表的上传文件，失败只记录日志（孤儿对象会被 reconcile 任务清理）
+func (svc *ResourceService) deleteUploadedObject(cosPath string) {
+	if err := svc.CosClient.DeleteObject(context.Background(), cosPath); err != nil {
+		zap.L().Error("CosClient.DeleteObject() failed during rollback", zap.String("cosPath", cosPath), zap.Error(err))
+	}
+}
+
+// summarizeBatch 汇总每个文件的处理结果
+func summarizeBatch(items []*vo.BatchUploadItem) vo.BatchUploadResp {
+	resp := vo.BatchUploadResp{Total: len(items), Items: make([]vo.BatchUploadItem, 0, len(items))}
+	for _, item := range items {
+		switch item.Status {
+		case model.BatchItemInserted:
+			resp.Inserted++
+		case model.BatchItemDuplicate:
+			resp.Duplicates++
+		case model.BatchItemRejected:
+			resp.Rejected++
+		case model.BatchItemFailed:
+			resp.Failed++
+		}
+		resp.Items = append(resp.Items, *item)
+	}
+	return resp
+}
+
+// firstNonEmpty 返回第一个非空字符串
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
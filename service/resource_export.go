@@ -0,0 +1,58 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"go.uber.org/zap"
+	"logo_api/dao/mysql"
+	"logo_api/model/resource/dto"
+	"logo_api/util"
+	"strconv"
+)
+
+// resourceExportColumns 资源元数据导出的列，取值见 resourceExportRow
+var resourceExportColumns = []string{
+	"id", "shortName", "title", "name", "type", "md5", "size", "width", "height", "isVector", "isBitmap",
+	"usedForEdge", "isDeleted", "backgroundColor", "version", "chainID", "prevID", "phash", "palette", "lastUpdateTime", "cosURL",
+}
+
+// ExportResources 按筛选条件导出资源元数据（不含文件本身），返回文件内容；JSON 为 ResourceInfoDTO 数组
+func ExportResources(req dto.ResourceExportReq) ([]byte, error) {
+	resources, err := mysql.ExportResources(req.ResourceGetListReq)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]dto.ResourceInfoDTO, 0, len(resources))
+	for _, r := range resources {
+		list = append(list, doResourceToDTO(r))
+	}
+	var buf bytes.Buffer
+	if req.Format == util.TableFormatJSON {
+		if err = json.NewEncoder(&buf).Encode(list); err != nil {
+			return nil, err
+		}
+	} else {
+		rows := make([][]string, 0, len(list))
+		for _, r := range list {
+			rows = append(rows, resourceExportRow(r))
+		}
+		if err = util.WriteTable(req.Format, &buf, resourceExportColumns, rows); err != nil {
+			return nil, err
+		}
+	}
+	zap.L().Info("service.ExportResources() success", zap.String("format", req.Format), zap.Int("count", len(list)))
+	return buf.Bytes(), nil
+}
+
+// resourceExportRow 按 resourceExportColumns 的顺序输出一行
+func resourceExportRow(r dto.ResourceInfoDTO) []string {
+	prevID := ""
+	if r.PrevID != nil {
+		prevID = strconv.Itoa(*r.PrevID)
+	}
+	return []string{
+		strconv.Itoa(r.ID), r.ShortName, r.Title, r.Name, r.Type, r.Md5, strconv.Itoa(r.Size), strconv.Itoa(r.Width), strconv.Itoa(r.Height),
+		strconv.Itoa(r.IsVector), strconv.Itoa(r.IsBitmap), strconv.Itoa(r.UsedForEdge), strconv.Itoa(r.IsDeleted), r.BackgroundColor,
+		strconv.Itoa(r.Version), strconv.Itoa(r.ChainID), prevID, r.PHash, r.Palette, r.LastUpdateTime, r.CosURL,
+	}
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"logo_api/dao/mysql"
+	"logo_api/model"
+	"logo_api/model/resource/do"
+	"logo_api/model/resource/dto"
+	"logo_api/model/resource/vo"
+	udto "logo_api/model/university/dto"
+	"logo_api/util"
+	"strconv"
+	"strings"
+)
+
+// resourceImportFields 资源元数据可导入的字段（同 /resource/export 的列名），id 用于定位资源，其余列在导入时会被忽略
+// title 跟随高校中文名、文件相关的列由文件本身决定，都不能通过导入修改
+var resourceImportFields = []string{"id", "usedForEdge", "backgroundColor"}
+
+// ImportResources 解析 CSV/XLSX/JSON 文件，按 id 修改已有资源的 usedForEdge 和 backgroundColor
+// 与 /university/import 一样总是全部成功才提交：预览（默认）时只读地判断每一行，返回逐行 diff；正式导入时任意一行不成功则整批回滚
+func (svc *ResourceService) ImportResources(ctx context.Context, req dto.ResourceImportReq) (vo.ResourceImportResp, error) {
+	// 1. 读取并解析文件，确定列映射
+	format, header, rows, err := readImportFile(req.File, req.Format)
+	if err != nil {
+		return vo.ResourceImportResp{}, err
+	}
+	columns, ignored, err := resolveImportColumns(header, req.Mapping, resourceImportFields, func(field string) bool {
+		return field == "id"
+	})
+	if err != nil {
+		return vo.ResourceImportResp{}, err
+	}
+	dryRun := req.DryRun == nil || *req.DryRun
+	resp := vo.ResourceImportResp{Format: format, Columns: make([]udto.ColumnMapping, 0, len(columns)), Ignored: ignored,
+		Total: len(rows), DryRun: dryRun, Items: make([]dto.ResourceRowResult, len(rows))}
+	for _, c := range columns {
+		resp.Columns = append(resp.Columns, c.ColumnMapping)
+	}
+
+	// 2. 一次查出所有涉及的资源，逐行校验并计算 diff
+	values := make([]map[string]string, len(rows))
+	var ids []int
+	for i, row := range rows {
+		values[i] = make(map[string]string, len(columns))
+		for _, c := range columns {
+			values[i][c.Field] = strings.TrimSpace(row.Cells[c.Index])
+		}
+		if id, err := strconv.Atoi(values[i]["id"]); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	resources, err := mysql.GetResourcesByIDs(ids)
+	if err != nil {
+		return vo.ResourceImportResp{}, err
+	}
+	byID := make(map[int]do.Resource, len(resources))
+	for _, r := range resources {
+		byID[r.ID] = r
+	}
+	var current []do.Resource
+	var updates []map[string]interface{}
+	var updateRows []int
+	seen := make(map[int]int)
+	for i, row := range rows {
+		result := resourceImportRow(values[i], byID, seen)
+		result.Index, result.Line = i, row.Line
+		if result.Status == model.ResourceRowUpdated {
+			current = append(current, byID[result.ID])
+			updates = append(updates, resourceImportUpdates(result.Changes))
+			updateRows = append(updateRows, i)
+		}
+		if result.ID != 0 {
+			seen[result.ID] = row.Line
+		}
+		resp.Items[i] = result
+	}
+
+	// 3. 全部通过时在一个事务中写入，否则（或预览时）不写库
+	invalid := false
+	for _, item := range resp.Items {
+		invalid = invalid || item.Status == model.ResourceRowInvalid
+	}
+	switch {
+	case dryRun:
+	case invalid:
+		markResourceRowsSkipped(resp.Items)
+	case len(current) > 0:
+		failed, err := mysql.UpdateResourcesMetadata(current, updates)
+		if failed < 0 && err != nil {
+			return vo.ResourceImportResp{}, err
+		}
+		if err != nil {
+			resp.Items[updateRows[failed]].Status = model.ResourceRowFailed
+			resp.Items[updateRows[failed]].Message = err.Error()
+			resp.Items[updateRows[failed]].Changes = nil
+			markResourceRowsSkipped(resp.Items)
+			break
+		}
+		resp.Committed = true
+		purged := make(map[string]bool)
+		for _, r := range current {
+			if !purged[r.ShortName] {
+				purged[r.ShortName] = true
+				svc.purgeUniversityVariants(ctx, r.ShortName)
+			}
+		}
+	default:
+		resp.Committed = true
+	}
+	for _, item := range resp.Items {
+		switch item.Status {
+		case model.ResourceRowUpdated:
+			resp.Updated++
+		case model.ResourceRowUnchanged:
+			resp.Unchanged++
+		case model.ResourceRowInvalid:
+			resp.Invalid++
+		case model.ResourceRowFailed:
+			resp.Failed++
+		case model.ResourceRowSkipped:
+			resp.Skipped++
+		}
+	}
+	zap.L().Info("service.ImportResources() finished", zap.String("filename", req.File.Filename), zap.String("format", format),
+		zap.Bool("dryRun", dryRun), zap.Int("rows", len(rows)), zap.Int("updated", resp.Updated), zap.Bool("committed", resp.Committed))
+	return resp, nil
+}
+
+// resourceImportRow 校验一行并与数据库中的资源比较；seen 为前面的行中出现过的 id 及其行号
+// 只有有效的资源可以修改；usedForEdge 只能是 0/1 且只适用于矢量文件；位图的背景色已渲染进文件，不能单独修改
+func resourceImportRow(values map[string]string, byID map[int]do.Resource, seen map[int]int) dto.ResourceRowResult {
+	var result dto.ResourceRowResult
+	invalid := func(field, code, format string, args ...interface{}) dto.ResourceRowResult {
+		result.Status = model.ResourceRowInvalid
+		result.Errors = append(result.Errors, model.FieldError{Field: field, Code: code, Message: fmt.Sprintf(format, args...)})
+		return result
+	}
+	id, err := strconv.Atoi(values["id"])
+	if err != nil || id <= 0 {
+		return invalid("id", "invalid", "id %q is not a positive integer", values["id"])
+	}
+	if line, ok := seen[id]; ok {
+		return invalid("id", "duplicate", "id %d already appears on line %d", id, line)
+	}
+	result.ID = id
+	r, ok := byID[id]
+	if !ok || r.IsDeleted != model.ResourceIsActive {
+		return invalid("id", "not_found", "resource %d does not exist or has been deleted", id)
+	}
+	result.Name = r.Name
+
+	if v, ok := values["usedForEdge"]; ok {
+		switch {
+		case v != "0" && v != "1":
+			invalid("usedForEdge", "invalid", "usedForEdge must be 0 or 1, got %q", v)
+		case v != strconv.Itoa(r.UsedForEdge):
+			if v == "1" && r.IsVector != 1 {
+				invalid("usedForEdge", "not_vector", "usedForEdge=1 only applies to vector files (svg/ai/eps/pdf), got %q", r.Type)
+				break
+			}
+			old := strconv.Itoa(r.UsedForEdge)
+			result.Changes = append(result.Changes, udto.FieldChange{Field: "usedForEdge", Old: &old, New: &v})
+		}
+	}
+	if v, ok := values["backgroundColor"]; ok {
+		color := util.NormalizeColor(v)
+		switch {
+		case color == "" && v != "" && !strings.EqualFold(v, "transparent"):
+			invalid("backgroundColor", "invalid", "backgroundColor %q is not a color", v)
+		case color != util.NormalizeColor(r.BackgroundColor):
+			if r.IsBitmap == 1 {
+				invalid("backgroundColor", "bitmap", "the background of a bitmap is part of the file, use /resource/replace to change it")
+			} else {
+				old := r.BackgroundColor
+				result.Changes = append(result.Changes, udto.FieldChange{Field: "backgroundColor", Old: &old, New: &color})
+			}
+		}
+	}
+	switch {
+	case result.Status == model.ResourceRowInvalid:
+		result.Changes = nil
+	case len(result.Changes) > 0:
+		result.Status = model.ResourceRowUpdated
+	default:
+		result.Status = model.ResourceRowUnchanged
+	}
+	return result
+}
+
+// resourceImportUpdates 把字段变化转换为要更新的列
+func resourceImportUpdates(changes []udto.FieldChange) map[string]interface{} {
+	updates := make(map[string]interface{}, len(changes))
+	for _, c := range changes {
+		switch c.Field {
+		case "usedForEdge":
+			updates["used_for_edge"], _ = strconv.Atoi(*c.New)
+		case "backgroundColor":
+			updates["background_color"] = *c.New
+		}
+	}
+	return updates
+}
+
+// markResourceRowsSkipped 整批回滚后，把原本会成功的行标记为 skipped
+func markResourceRowsSkipped(items []dto.ResourceRowResult) {
+	for i := range items {
+		if items[i].Status == model.ResourceRowUpdated || items[i].Status == model.ResourceRowUnchanged {
+			items[i].Status = model.ResourceRowSkipped
+			items[i].Message = "rolled back because another row failed"
+			items[i].Changes = nil
+		}
+	}
+}
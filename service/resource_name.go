@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"logo_api/dao/mysql"
+	"logo_api/dao/redis"
+	"time"
+)
+
+// resourceNameReserveTTL 单文件上传、替换和批量上传占用名称的时长，覆盖 上传 COS -> 写库 的整个过程
+const resourceNameReserveTTL = 10 * time.Minute
+
+// resourceNameKey 名称占用的 Redis key，COS 路径由 short_name + name 决定
+func resourceNameKey(shortName, name string) string {
+	return redis.ResourceNameLockPrefix + shortName + "/" + name
+}
+
+// reserveResourceName 在 Redis 中占用 shortName/name，并确认资源表中还没有同名资源，owner 为占用者标识
+// 名称已被其他上传占用或已有同名资源时返回 ErrResourceNameTaken；成功后由调用方负责 releaseResourceName
+func reserveResourceName(ctx context.Context, shortName, name, owner string, ttl time.Duration) error {
+	key := resourceNameKey(shortName, name)
+	ok, err := redis.AcquireLock(ctx, key, owner, ttl)
+	if err != nil {
+		zap.L().Error("redis.AcquireLock() failed", zap.String("key", key), zap.Error(err))
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: %s is being uploaded", ErrResourceNameTaken, name)
+	}
+	exists, err := mysql.ResourceNameExists(shortName, name)
+	if err == nil && exists {
+		err = fmt.Errorf("%w: %s", ErrResourceNameTaken, name)
+	}
+	if err != nil {
+		releaseResourceName(shortName, name, owner)
+		return err
+	}
+	return nil
+}
+
+// confirmResourceName 确认名称仍由 owner 占用（同时续期到 ttl）且资源表中没有同名资源，用于长时间上传完成前的最后检查
+func confirmResourceName(ctx context.Context, shortName, name, owner string, ttl time.Duration) error {
+	key := resourceNameKey(shortName, name)
+	held, err := redis.ExtendLock(ctx, key, owner, ttl)
+	if err != nil {
+		zap.L().Error("redis.ExtendLock() failed", zap.String("key", key), zap.Error(err))
+		return err
+	}
+	if !held {
+		return fmt.Errorf("%w: reservation of %s has expired", ErrResourceNameTaken, name)
+	}
+	exists, err := mysql.ResourceNameExists(shortName, name)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("%w: %s", ErrResourceNameTaken, name)
+	}
+	return nil
+}
+
+// releaseResourceName 释放名称占用，只删除 owner 自己的占用；失败只记录日志（占用会自动过期）
+func releaseResourceName(shortName, name, owner string) {
+	key := resourceNameKey(shortName, name)
+	if err := redis.ReleaseLock(context.Background(), key, owner); err != nil {
+		zap.L().Warn("redis.ReleaseLock() failed", zap.String("key", key), zap.Error(err))
+	}
+}
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"logo_api/dao/mysql"
+	"logo_api/model"
+	cosdto "logo_api/model/cos/dto"
+	"logo_api/model/resource/do"
+	"logo_api/model/resource/dto"
+	"logo_api/model/resource/vo"
+	"logo_api/util"
+	"path"
+	"strings"
+)
+
+var (
+	// ErrResourceNotActive 只能替换当前有效的版本
+	ErrResourceNotActive = errors.New("only the active version of a resource can be replaced")
+	// ErrResourceNotReplaced 只能回滚到已被替换的历史版本
+	ErrResourceNotReplaced = errors.New("only a replaced version can be rolled back to")
+	// ErrResourceUnchanged 新文件与当前版本内容相同
+	ErrResourceUnchanged = errors.New("the uploaded file is identical to the current version")
+	// ErrResourceNameTaken 同一高校下已有同名资源（COS 路径冲突）
+	ErrResourceNameTaken = errors.New("resource name has been taken in this university")
+	// ErrResourceDifferentChain 比较的两个版本不在同一版本链中
+	ErrResourceDifferentChain = errors.New("resources are not in the same version chain")
+)
+
+// ReplaceResource 上传新版本替换当前有效版本。每个版本在 COS 中都有独立的文件，旧版本文件保留用于回滚
+func (svc *ResourceService) ReplaceResource(ctx context.Context, req dto.ResourceReplaceReq) (dto.ResourceInfoDTO, error) {
+	current, err := mysql.GetResourceByID(req.ID)
+	if err != nil {
+		return dto.ResourceInfoDTO{}, err
+	}
+	if current.IsDeleted != model.ResourceIsActive {
+		return dto.ResourceInfoDTO{}, ErrResourceNotActive
+	}
+	history, err := mysql.GetResourceHistory(current.ChainID())
+	if err != nil {
+		return dto.ResourceInfoDTO{}, err
+	}
+
+	// 1. 组装新版本：未指定的字段沿用当前版本
+	usedForEdge := current.UsedForEdge
+	if req.UsedForEdge != nil {
+		usedForEdge = *req.UsedForEdge
+	}
+	bgColor := current.BackgroundColor
+	if req.BackgroundColor != "" {
+		bgColor = req.BackgroundColor
+	}
+	name := req.Name
+	if name == "" {
+		name = versionedResourceName(history, req.Type)
+	}
+	if err = validateUpload(uploadCandidate{
+		File:                req.File,
+		Type:                req.Type,
+		Name:                name,
+		Title:               current.Title,
+		ShortName:           current.ShortName,
+		UsedForEdge:         usedForEdge,
+		SkipUniversityCheck: true,
+	}); err != nil {
+		return dto.ResourceInfoDTO{}, err
+	}
+	newResource, err := dto.ResourceInsertReq{
+		File:            req.File,
+		Title:           current.Title,
+		ShortName:       current.ShortName,
+		Name:            name,
+		Type:            req.Type,
+		UsedForEdge:     usedForEdge,
+		BackgroundColor: bgColor,
+	}.ToEntity()
+	if err != nil {
+		zap.L().Error("dto.ResourceInsertReq.ToEntity() failed", zap.Int("id", req.ID), zap.Error(err))
+		return dto.ResourceInfoDTO{}, err
+	}
+
+	// 2. 内容校验：与当前版本相同无需替换；与更早的版本相同应使用回滚
+	for _, h := range history {
+		if h.Md5 != newResource.Md5 || h.Size != newResource.Size {
+			continue
+		}
+		if h.ID == current.ID {
+			return dto.ResourceInfoDTO{}, ErrResourceUnchanged
+		}
+		return dto.ResourceInfoDTO{}, fmt.Errorf("%w: same as version %d (id %d), use rollback instead", ErrResourceUnchanged, h.Version, h.ID)
+	}
+	// 同一版本链的旧版本本来就相似，只与其他资源比较；warn 策略下仅记录日志
+	if _, err = checkNearDuplicates(newResource, current.ChainID()); err != nil {
+		return dto.ResourceInfoDTO{}, err
+	}
+	owner, err := newLockToken()
+	if err != nil {
+		return dto.ResourceInfoDTO{}, err
+	}
+	if err = reserveResourceName(ctx, current.ShortName, name, owner, resourceNameReserveTTL); err != nil {
+		return dto.ResourceInfoDTO{}, err
+	}
+	defer releaseResourceName(current.ShortName, name, owner)
+
+	// 3. 先上传新文件，再写数据库；写库失败时删除刚上传的文件
+	uploadCosPath := fmt.Sprintf("beacon/downloads/%s/%s", current.ShortName, name)
+	if err = svc.CosClient.UploadObject(ctx, req.File, uploadCosPath); err != nil {
+		zap.L().Error("CosClient.UploadObject() failed", zap.String("uploadCosPath", uploadCosPath), zap.Error(err))
+		return dto.ResourceInfoDTO{}, err
+	}
+	if err = mysql.ReplaceResource(current, newResource); err != nil {
+		zap.L().Error("mysql.ReplaceResource() failed", zap.Int("id", req.ID), zap.Error(err))
+		if delErr := svc.CosClient.DeleteObject(context.Background(), uploadCosPath); delErr != nil {
+			zap.L().Error("CosClient.DeleteObject() failed during rollback", zap.Error(delErr))
+		}
+		return dto.ResourceInfoDTO{}, err
+	}
+
+	// 4. 该高校的转换结果基于旧版本生成，全部失效
+	svc.purgeUniversityVariants(ctx, current.ShortName)
+	zap.L().Info("service.ReplaceResource() success",
+		zap.Int("from", current.ID), zap.Int("to", newResource.ID), zap.Int("version", newResource.Version))
+	return doResourceToDTO(*newResource), nil
+}
+
+// RollbackResource 把版本链的有效版本切换回历史版本 id，并通过 RefreshUniversityStats 刷新 computation_id
+func (svc *ResourceService) RollbackResource(ctx context.Context, id int) (dto.ResourceInfoDTO, error) {
+	target, err := mysql.GetResourceByID(id)
+	if err != nil {
+		return dto.ResourceInfoDTO{}, err
+	}
+	if target.IsDeleted != model.ResourceIsReplaced {
+		return dto.ResourceInfoDTO{}, ErrResourceNotReplaced
+	}
+	previous, err := mysql.RollbackResource(target)
+	if err != nil {
+		zap.L().Error("mysql.RollbackResource() failed", zap.Int("id", id), zap.Error(err))
+		return dto.ResourceInfoDTO{}, err
+	}
+	svc.purgeUniversityVariants(ctx, target.ShortName)
+	target.IsDeleted = model.ResourceIsActive
+	zap.L().Info("service.RollbackResource() success", zap.Int("from", previous.ID), zap.Int("to", target.ID))
+	return doResourceToDTO(target), nil
+}
+
+// GetResourceHistory 查询资源所在版本链的全部版本
+func GetResourceHistory(id int) (vo.ResourceHistoryResp, error) {
+	resource, err := mysql.GetResourceByID(id)
+	if err != nil {
+		return vo.ResourceHistoryResp{}, err
+	}
+	history, err := mysql.GetResourceHistory(resource.ChainID())
+	if err != nil {
+		return vo.ResourceHistoryResp{}, err
+	}
+	resp := vo.ResourceHistoryResp{
+		ChainID:    resource.ChainID(),
+		List:       make([]dto.ResourceInfoDTO, 0, len(history)),
+		TotalCount: len(history),
+	}
+	for _, h := range history {
+		if h.IsDeleted == model.ResourceIsActive {
+			resp.ActiveID = h.ID
+		}
+		resp.List = append(resp.List, doResourceToDTO(h))
+	}
+	return resp, nil
+}
+
+// DiffResourceVersions 比较同一版本链中两个版本的元数据
+func DiffResourceVersions(fromID, toID int) (vo.ResourceDiffResp, error) {
+	from, err := mysql.GetResourceByID(fromID)
+	if err != nil {
+		return vo.ResourceDiffResp{}, err
+	}
+	to, err := mysql.GetResourceByID(toID)
+	if err != nil {
+		return vo.ResourceDiffResp{}, err
+	}
+	if from.ChainID() != to.ChainID() {
+		return vo.ResourceDiffResp{}, ErrResourceDifferentChain
+	}
+	return vo.ResourceDiffResp{
+		From:    doResourceToDTO(from),
+		To:      doResourceToDTO(to),
+		Changes: diffResources(from, to),
+	}, nil
+}
+
+// diffResources 列出两个版本之间有变化的元数据字段
+func diffResources(from, to do.Resource) []dto.ResourceFieldChange {
+	fields := []struct {
+		name     string
+		from, to interface{}
+	}{
+		{"name", from.Name, to.Name},
+		{"type", from.Type, to.Type},
+		{"md5", from.Md5, to.Md5},
+		{"size", from.Size, to.Size},
+		{"width", from.Width, to.Width},
+		{"height", from.Height, to.Height},
+		{"isVector", from.IsVector, to.IsVector},
+		{"isBitmap", from.IsBitmap, to.IsBitmap},
+		{"usedForEdge", from.UsedForEdge, to.UsedForEdge},
+		{"backgroundColor", from.BackgroundColor, to.BackgroundColor},
+		{"isDeleted", from.IsDeleted, to.IsDeleted},
+	}
+	changes := make([]dto.ResourceFieldChange, 0, len(fields))
+	for _, f := range fields {
+		if f.from != f.to {
+			changes = append(changes, dto.ResourceFieldChange{Field: f.name, From: f.from, To: f.to})
+		}
+	}
+	return changes
+}
+
+// versionedResourceName 按版本号生成新版本的文件名，如 sdut.svg -> sdut_v3.svg
+func versionedResourceName(history []do.Resource, ext string) string {
+	root := history[len(history)-1] // history 按版本号从新到旧排列，最后一个是第一个版本
+	base := strings.TrimSuffix(root.Name, path.Ext(root.Name))
+	version := history[0].Version + 1
+	return fmt.Sprintf("%s_v%d.%s", base, version, util.NormalizeFileType(ext))
+}
+
+// purgeUniversityVariants 清理某高校的全部转换缓存（COS 与本实例 L1），失败只记录日志（缓存会在过期后被清理任务删除）
+func (svc *ResourceService) purgeUniversityVariants(ctx context.Context, shortName string) {
+	if _, err := svc.PurgeCache(ctx, cosdto.CachePurgeReq{ShortName: shortName}); err != nil {
+		zap.L().Warn("svc.PurgeCache() failed, stale variants will expire later", zap.String("shortName", shortName), zap.Error(err))
+	}
+}
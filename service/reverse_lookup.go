@@ -0,0 +1,186 @@
+package service
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"logo_api/dao/mysql"
+	"logo_api/model"
+	"logo_api/model/resource/dto"
+	"logo_api/model/resource/vo"
+	"logo_api/util"
+	"math"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// logoIndexTTL 内存索引的有效期，过期后下一次查询时从 MySQL 重建
+	logoIndexTTL = 5 * time.Minute
+	// reverseLookupHashSpan 汉明距离达到该值时哈希相似度为 0（两张无关图片的期望距离约为 32）
+	reverseLookupHashSpan = 32
+	// reverseLookupHashWeight 感知哈希在综合得分中的权重，其余为主色相似度
+	reverseLookupHashWeight = 0.75
+	// reverseLookupMinConfidence 低于该置信度的候选不返回
+	reverseLookupMinConfidence = 0.5
+	defaultReverseLookupLimit  = 5
+)
+
+// logoIndexEntry 内存索引中的一个有效资源
+type logoIndexEntry struct {
+	resourceID int
+	shortName  string
+	title      string
+	name       string
+	hash       string
+	palette    []util.PaletteColor
+}
+
+// logoIndex 以图识校使用的内存索引，数据来自 resource 表中已计算特征的有效资源
+type logoIndex struct {
+	mu      sync.Mutex
+	entries []logoIndexEntry
+	builtAt time.Time
+}
+
+var reverseLogoIndex = &logoIndex{}
+
+// snapshot 返回当前索引，过期时先重建
+func (idx *logoIndex) snapshot() ([]logoIndexEntry, error) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if idx.entries != nil && time.Since(idx.builtAt) < logoIndexTTL {
+		return idx.entries, nil
+	}
+	resources, err := mysql.GetResourceHashes("")
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]logoIndexEntry, 0, len(resources))
+	for _, r := range resources {
+		entries = append(entries, logoIndexEntry{
+			resourceID: r.ID,
+			shortName:  r.ShortName,
+			title:      r.Title,
+			name:       r.Name,
+			hash:       r.PHash,
+			palette:    util.ParsePalette(r.Palette),
+		})
+	}
+	idx.entries, idx.builtAt = entries, time.Now()
+	zap.L().Info("reverse logo index rebuilt", zap.Int("entries", len(entries)))
+	return entries, nil
+}
+
+// ReverseLookup 以图识校：计算上传图片的感知哈希和主色，与所有有效资源比较，按高校汇总返回候选
+func ReverseLookup(req dto.ReverseLookupReq) (vo.ReverseLookupResp, error) {
+	// 1. 校验文件：只接受位图和 SVG
+	limits := newUploadLimits()
+	if req.File.Size > limits.maxSizeBytes {
+		return vo.ReverseLookupResp{}, &UploadValidationError{Errors: []model.FieldError{{Field: "file", Code: "too_large",
+			Message: fmt.Sprintf("file size %d bytes exceeds the limit of %d bytes", req.File.Size, limits.maxSizeBytes)}}}
+	}
+	fileType := util.NormalizeFileType(req.Type)
+	if fileType == "" {
+		sniffed, _, err := util.SniffFileType(req.File)
+		if err != nil {
+			return vo.ReverseLookupResp{}, err
+		}
+		fileType = sniffed
+	}
+	if !util.IsBitmapType(fileType) && fileType != "svg" {
+		return vo.ReverseLookupResp{}, &UploadValidationError{Errors: []model.FieldError{{Field: "file", Code: "unsupported_type",
+			Message: "only bitmap images and svg can be looked up"}}}
+	}
+
+	// 2. 计算查询图片的特征
+	features, err := util.ComputeImageFeatures(req.File, fileType)
+	if err != nil || features.PHash == "" {
+		zap.L().Info("util.ComputeImageFeatures() failed", zap.String("filename", req.File.Filename), zap.Error(err))
+		return vo.ReverseLookupResp{}, &UploadValidationError{Errors: []model.FieldError{{Field: "file", Code: "corrupted",
+			Message: "image could not be decoded"}}}
+	}
+	queryPalette := util.ParsePalette(features.Palette)
+
+	// 3. 与索引逐个比较，每所高校只保留得分最高的资源
+	entries, err := reverseLogoIndex.snapshot()
+	if err != nil {
+		return vo.ReverseLookupResp{}, err
+	}
+	best := make(map[string]vo.ReverseLookupCandidate)
+	for _, e := range entries {
+		distance := util.PHashDistance(features.PHash, e.hash)
+		if distance < 0 {
+			continue
+		}
+		hashSim := math.Max(0, 1-float64(distance)/reverseLookupHashSpan)
+		score := hashSim
+		colorSim := 0.0
+		if len(queryPalette) > 0 && len(e.palette) > 0 {
+			colorSim = util.PaletteSimilarity(queryPalette, e.palette)
+			score = reverseLookupHashWeight*hashSim + (1-reverseLookupHashWeight)*colorSim
+		}
+		if score < reverseLookupMinConfidence {
+			continue
+		}
+		if cur, ok := best[e.shortName]; ok && cur.Confidence >= score {
+			continue
+		}
+		best[e.shortName] = vo.ReverseLookupCandidate{
+			ShortName:       e.shortName,
+			Title:           e.title,
+			Confidence:      score,
+			ResourceID:      e.resourceID,
+			ResourceName:    e.name,
+			HashDistance:    distance,
+			ColorSimilarity: roundScore(colorSim),
+			CosURL:          fmt.Sprintf("%s/%s/%s", model.BeaconCosPreURL, e.shortName, url.PathEscape(e.name)),
+		}
+	}
+
+	// 4. 排序并补充高校信息
+	candidates := make([]vo.ReverseLookupCandidate, 0, len(best))
+	for _, c := range best {
+		candidates = append(candidates, c)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Confidence != candidates[j].Confidence {
+			return candidates[i].Confidence > candidates[j].Confidence
+		}
+		return candidates[i].HashDistance < candidates[j].HashDistance
+	})
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultReverseLookupLimit
+	}
+	// 已停用的高校不作为候选
+	matched := make([]vo.ReverseLookupCandidate, 0, min(limit, len(candidates)))
+	for _, c := range candidates {
+		if len(matched) >= limit {
+			break
+		}
+		c.Confidence = roundScore(c.Confidence)
+		university, err := mysql.GetUniversityByShortName(c.ShortName)
+		if err != nil {
+			zap.L().Warn("mysql.GetUniversityByShortName() failed", zap.String("shortName", c.ShortName), zap.Error(err))
+			matched = append(matched, c)
+			continue
+		}
+		if university.IsDeleted == model.UniversityIsDeleted {
+			continue
+		}
+		c.Slug = university.Slug
+		c.OfficialResourceID = university.ComputationID
+		matched = append(matched, c)
+	}
+	candidates = matched
+
+	zap.L().Info("service.ReverseLookup() done", zap.String("phash", features.PHash), zap.Int("candidates", len(candidates)))
+	return vo.ReverseLookupResp{PHash: features.PHash, Palette: paletteHex(features.Palette), Candidates: candidates}, nil
+}
+
+// roundScore 保留三位小数
+func roundScore(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"logo_api/dao/mysql"
+	"logo_api/model"
+	"logo_api/model/resource/do"
+	"logo_api/model/resource/dto"
+	"logo_api/model/resource/vo"
+	"logo_api/settings"
+	"logo_api/util"
+	"sort"
+	"strings"
+)
+
+const (
+	// defaultSimilarThreshold dHash 汉明距离不超过 6 时通常是同一 logo 的不同尺寸或压缩版本
+	defaultSimilarThreshold     = 6
+	defaultFeatureBackfillLimit = 200
+
+	similarPolicyWarn  = "warn"
+	similarPolicyBlock = "block"
+	similarPolicyOff   = "off"
+)
+
+// similarOptions 近似重复检测配置
+func similarOptions() (threshold int, policy string) {
+	threshold, policy = defaultSimilarThreshold, similarPolicyWarn
+	if cfg := settings.Config.UploadConfig; cfg != nil {
+		if cfg.SimilarThreshold > 0 {
+			threshold = cfg.SimilarThreshold
+		}
+		switch p := strings.ToLower(cfg.SimilarPolicy); p {
+		case similarPolicyWarn, similarPolicyBlock, similarPolicyOff:
+			policy = p
+		}
+	}
+	return threshold, policy
+}
+
+// checkNearDuplicates 检查新资源与同一高校已有资源是否近似重复
+// 策略为 block 时返回 *UploadValidationError；为 warn 时返回相似资源列表供调用方提示；excludeChainID 用于替换版本时排除同一版本链
+func checkNearDuplicates(resource *do.Resource, excludeChainID int) ([]dto.SimilarResource, error) {
+	threshold, policy := similarOptions()
+	if policy == similarPolicyOff || resource.PHash == "" {
+		return nil, nil
+	}
+	similar, err := findSimilarResources(resource.ShortName, resource.PHash, threshold, func(r do.Resource) bool {
+		return excludeChainID != 0 && r.ChainID() == excludeChainID
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(similar) == 0 {
+		return nil, nil
+	}
+	zap.L().Info("near-duplicate resources found", zap.String("shortName", resource.ShortName),
+		zap.String("name", resource.Name), zap.String("policy", policy), zap.Any("similar", similar))
+	if policy == similarPolicyBlock {
+		nearest := similar[0]
+		return similar, &UploadValidationError{Errors: []model.FieldError{{
+			Field:   "file",
+			Code:    "near_duplicate",
+			Message: fmt.Sprintf("file looks like existing resource %q (id %d, distance %d)", nearest.Name, nearest.ID, nearest.Distance),
+		}}}
+	}
+	return similar, nil
+}
+
+// storedImageFeatures 从 COS 读取已上传的对象，为位图和 SVG 资源计算感知哈希和主色，再按配置的策略检查近似重复
+// 超过单文件上传上限的文件不在请求中下载计算，读取或计算失败时同样只记录日志，之后由补算任务补齐
+func (svc *ResourceService) storedImageFeatures(ctx context.Context, resource *do.Resource, cosPath string) ([]dto.SimilarResource, error) {
+	if !util.IsBitmapType(resource.Type) && resource.Type != "svg" {
+		return nil, nil
+	}
+	if resource.Size <= 0 || int64(resource.Size) > newUploadLimits().maxSizeBytes {
+		zap.L().Info("skip computing image features for large upload", zap.String("cosPath", cosPath), zap.Int("size", resource.Size))
+		return nil, nil
+	}
+	data, err := svc.CosClient.GetObjectHead(ctx, cosPath, int64(resource.Size))
+	if err != nil {
+		zap.L().Warn("CosClient.GetObjectHead() failed, features left for backfill", zap.String("cosPath", cosPath), zap.Error(err))
+		return nil, nil
+	}
+	features, err := util.ComputeImageFeaturesBytes(data, resource.Type)
+	if err != nil {
+		zap.L().Warn("util.ComputeImageFeaturesBytes() failed", zap.String("cosPath", cosPath), zap.Error(err))
+	}
+	resource.PHash, resource.Palette = features.PHash, features.Palette
+	return checkNearDuplicates(resource, 0)
+}
+
+// findSimilarResources 查询感知哈希距离不超过 threshold 的有效资源，按距离排序；skip 返回 true 的资源不参与比较
+func findSimilarResources(shortName, pHash string, threshold int, skip func(do.Resource) bool) ([]dto.SimilarResource, error) {
+	candidates, err := mysql.GetResourceHashes(shortName)
+	if err != nil {
+		return nil, err
+	}
+	similar := make([]dto.SimilarResource, 0)
+	for _, c := range candidates {
+		if skip != nil && skip(c) {
+			continue
+		}
+		distance := util.PHashDistance(pHash, c.PHash)
+		if distance < 0 || distance > threshold {
+			continue
+		}
+		similar = append(similar, dto.SimilarResource{
+			ID:        c.ID,
+			ShortName: c.ShortName,
+			Name:      c.Name,
+			Type:      c.Type,
+			Width:     c.Width,
+			Height:    c.Height,
+			Distance:  distance,
+		})
+	}
+	sort.SliceStable(similar, func(i, j int) bool { return similar[i].Distance < similar[j].Distance })
+	return similar, nil
+}
+
+// GetSimilarResources 查询与指定资源相似的资源，默认只在同一高校内查找，排除同一版本链
+func GetSimilarResources(req dto.SimilarResourceReq) (vo.SimilarResourceResp, error) {
+	resource, err := mysql.GetResourceByID(req.ID)
+	if err != nil {
+		return vo.SimilarResourceResp{}, err
+	}
+	threshold := req.Threshold
+	if threshold == 0 {
+		threshold, _ = similarOptions()
+	}
+	resp := vo.SimilarResourceResp{ID: resource.ID, PHash: resource.PHash, Threshold: threshold, List: []dto.SimilarResource{}}
+	if resource.PHash == "" {
+		return resp, nil
+	}
+	shortName := resource.ShortName
+	if req.AllUniversities {
+		shortName = ""
+	}
+	resp.List, err = findSimilarResources(shortName, resource.PHash, threshold, func(r do.Resource) bool {
+		return r.ChainID() == resource.ChainID()
+	})
+	if err != nil {
+		return vo.SimilarResourceResp{}, err
+	}
+	return resp, nil
+}
+
+// BackfillImageFeatures 为还没有感知哈希或主色的位图和 SVG 资源补算，文件从 COS 下载
+// 从 req.AfterID 之后按 id 顺序扫描，补算失败的资源会一直缺少特征，调用方应以返回的 LastID 作为下次的游标
+func (svc *ResourceService) BackfillImageFeatures(ctx context.Context, req dto.FeatureBackfillReq) (dto.FeatureBackfillResultDTO, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultFeatureBackfillLimit
+	}
+	types := []string{"svg", "png", "jpg", "jpeg", "gif", "webp", "bmp"}
+	result := dto.FeatureBackfillResultDTO{LastID: req.AfterID}
+	for result.Scanned < limit {
+		resources, err := mysql.GetResourcesMissingFeatures(result.LastID, min(100, limit-result.Scanned), types)
+		if err != nil {
+			return result, err
+		}
+		if len(resources) == 0 {
+			break
+		}
+		for _, r := range resources {
+			if ctx.Err() != nil {
+				return result, ctx.Err()
+			}
+			result.LastID = r.ID
+			result.Scanned++
+			data, err := svc.getObjectDuringRename(r.Name, r.ShortName)
+			if err != nil {
+				result.Failed++
+				continue
+			}
+			features, err := util.ComputeImageFeaturesBytes(data, r.Type)
+			if err != nil || (features.PHash == "" && features.Palette == "") {
+				zap.L().Warn("util.ComputeImageFeaturesBytes() failed", zap.Int("id", r.ID), zap.Error(err))
+				result.Failed++
+				continue
+			}
+			if err = mysql.UpdateResourceFeatures(r.ID, features.PHash, features.Palette); err != nil {
+				return result, err
+			}
+			result.Updated++
+		}
+	}
+	zap.L().Info("service.BackfillImageFeatures() done", zap.Any("result", result))
+	return result, nil
+}
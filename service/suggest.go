@@ -0,0 +1,205 @@
+package service
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"logo_api/dao/mysql"
+	"logo_api/model/university/dto"
+	"logo_api/util"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// nameIndexTTL 名称索引的有效期；高校或别名变更时会主动失效
+	nameIndexTTL = 5 * time.Minute
+	// suggestMinScore 低于该相似度的候选不返回
+	suggestMinScore = 0.5
+	// defaultSuggestLimit "你是不是要找"和自动补全默认返回的候选数量
+	defaultSuggestLimit = 5
+)
+
+// nameIndexField 高校的一个可匹配名称
+type nameIndexField struct {
+	field string // title/shortName/fullNameEn/pinyin/initials/alias
+	value string // 比较用的小写形式
+	raw   string // 原始值，返回给调用方
+}
+
+// nameIndexEntry 名称索引中的一所高校
+type nameIndexEntry struct {
+	suggestion dto.UniversitySuggestion // 基本信息，匹配结果在副本上填写
+	fields     []nameIndexField
+	weight     int // 资源数量，相似度相同时资源多的优先
+}
+
+// nameIndex 名称建议使用的内存索引，数据来自 university 和 university_alias 表
+type nameIndex struct {
+	mu      sync.Mutex
+	entries []nameIndexEntry
+	builtAt time.Time
+}
+
+var universityNameIndex = &nameIndex{}
+
+// invalidate 使索引失效，下一次查询时重建
+func (idx *nameIndex) invalidate() {
+	idx.mu.Lock()
+	idx.entries = nil
+	idx.mu.Unlock()
+}
+
+// snapshot 返回当前索引，过期时先重建
+func (idx *nameIndex) snapshot() ([]nameIndexEntry, error) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if idx.entries != nil && time.Since(idx.builtAt) < nameIndexTTL {
+		return idx.entries, nil
+	}
+	universities, err := mysql.GetUniversityNames()
+	if err != nil {
+		return nil, err
+	}
+	aliases, err := mysql.GetActiveUniversityAliases()
+	if err != nil {
+		return nil, err
+	}
+	aliasesBySlug := make(map[string][]string, len(aliases))
+	for _, a := range aliases {
+		aliasesBySlug[a.Slug] = append(aliasesBySlug[a.Slug], a.Alias)
+	}
+	entries := make([]nameIndexEntry, 0, len(universities))
+	for _, u := range universities {
+		entry := nameIndexEntry{
+			suggestion: dto.UniversitySuggestion{Slug: u.Slug, ShortName: u.ShortName, Title: u.Title, FullNameEn: u.FullNameEn},
+			weight:     u.ResourceCount,
+		}
+		add := func(field, raw string) {
+			if raw = strings.TrimSpace(raw); raw != "" {
+				entry.fields = append(entry.fields, nameIndexField{field: field, value: strings.ToLower(raw), raw: raw})
+			}
+		}
+		add("title", u.Title)
+		add("shortName", u.ShortName)
+		add("fullNameEn", u.FullNameEn)
+		add("pinyin", u.Pinyin)
+		add("initials", u.Initials)
+		for _, a := range aliasesBySlug[u.Slug] {
+			add("alias", a)
+		}
+		entries = append(entries, entry)
+	}
+	idx.entries, idx.builtAt = entries, time.Now()
+	zap.L().Info("university name index rebuilt", zap.Int("entries", len(entries)))
+	return entries, nil
+}
+
+// scoreName 输入与一个名称的相似度：完全相同为 1，前缀匹配 0.9 以上，包含 0.75 以上，
+// 其余按编辑距离计算，同时与名称等长前缀比较，以便容忍输入到一半时的拼写错误
+func scoreName(query, candidate string) float64 {
+	if query == "" || candidate == "" {
+		return 0
+	}
+	if query == candidate {
+		return 1
+	}
+	qr, cr := []rune(query), []rune(candidate)
+	ratio := float64(len(qr)) / float64(len(cr))
+	if strings.HasPrefix(candidate, query) {
+		return 0.9 + 0.09*ratio
+	}
+	if strings.Contains(candidate, query) {
+		return 0.75 + 0.14*ratio
+	}
+	score := util.NameSimilarity(query, candidate)
+	if len(qr) >= 3 && len(cr) > len(qr) {
+		score = max(score, 0.85*util.NameSimilarity(query, string(cr[:len(qr)])))
+	}
+	return score
+}
+
+// SuggestUniversities 按名称相似度返回最接近 query 的高校，每所高校只保留得分最高的名称
+func SuggestUniversities(query string, limit int) ([]dto.UniversitySuggestion, error) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return []dto.UniversitySuggestion{}, nil
+	}
+	if limit <= 0 {
+		limit = defaultSuggestLimit
+	}
+	entries, err := universityNameIndex.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	// 全拼和首字母以去掉空格、隔音符号后的形式比较
+	pinyinQuery := util.NormalizePinyinQuery(query)
+
+	type scored struct {
+		suggestion dto.UniversitySuggestion
+		weight     int
+	}
+	candidates := make([]scored, 0)
+	for _, e := range entries {
+		best := e.suggestion
+		for _, f := range e.fields {
+			q := query
+			if f.field == "pinyin" || f.field == "initials" {
+				if pinyinQuery == "" {
+					continue
+				}
+				q = pinyinQuery
+			}
+			if s := scoreName(q, f.value); s > best.Score {
+				best.Score, best.MatchedField, best.Matched = s, f.field, f.raw
+			}
+		}
+		if best.Score >= suggestMinScore {
+			candidates = append(candidates, scored{suggestion: best, weight: e.weight})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].suggestion.Score != candidates[j].suggestion.Score {
+			return candidates[i].suggestion.Score > candidates[j].suggestion.Score
+		}
+		return candidates[i].weight > candidates[j].weight
+	})
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	suggestions := make([]dto.UniversitySuggestion, 0, len(candidates))
+	for _, c := range candidates {
+		c.suggestion.Score = roundScore(c.suggestion.Score)
+		suggestions = append(suggestions, c.suggestion)
+	}
+	return suggestions, nil
+}
+
+// LogoNotFoundError getLogo 找不到资源；名称无法解析为任何高校时附带相近高校的建议
+type LogoNotFoundError struct {
+	Name        string
+	Suggestions []dto.UniversitySuggestion
+}
+
+func (e *LogoNotFoundError) Error() string {
+	if len(e.Suggestions) > 0 {
+		return fmt.Sprintf("logo %q not found, did you mean %q", e.Name, e.Suggestions[0].Title)
+	}
+	return fmt.Sprintf("logo %q not found", e.Name)
+}
+
+// newLogoNotFoundError 构建找不到资源的错误；universityResolved 为 false 时计算名称建议
+func newLogoNotFoundError(name string, universityResolved bool) *LogoNotFoundError {
+	notFound := &LogoNotFoundError{Name: name, Suggestions: []dto.UniversitySuggestion{}}
+	if universityResolved {
+		return notFound
+	}
+	suggestions, err := SuggestUniversities(name, defaultSuggestLimit)
+	if err != nil {
+		zap.L().Warn("SuggestUniversities() failed", zap.String("name", name), zap.Error(err))
+		return notFound
+	}
+	notFound.Suggestions = suggestions
+	return notFound
+}
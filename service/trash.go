@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"logo_api/dao/mysql"
+	"logo_api/dao/redis"
+	"logo_api/model"
+	"logo_api/model/resource/do"
+	"logo_api/model/resource/dto"
+	"logo_api/model/resource/vo"
+	"logo_api/settings"
+	"logo_api/util"
+	"time"
+)
+
+const (
+	defaultTrashRetentionDays = 30
+	defaultTrashMaxPerRun     = 500
+	trashPurgeLockTTL         = 10 * time.Minute
+)
+
+// trashOptions 从配置中读取回收站参数：retentionDays 为负数表示不自动清理
+func trashOptions() (retentionDays, maxPerRun int) {
+	retentionDays, maxPerRun = defaultTrashRetentionDays, defaultTrashMaxPerRun
+	if cfg := settings.Config.TrashConfig; cfg != nil {
+		if cfg.RetentionDays != 0 {
+			retentionDays = cfg.RetentionDays
+		}
+		if cfg.MaxPerRun != 0 {
+			maxPerRun = cfg.MaxPerRun
+		}
+	}
+	return retentionDays, maxPerRun
+}
+
+// trashDeletedAt 资源的删除时间，历史数据没有 deleted_time 时用 last_update_time 近似
+func trashDeletedAt(r do.Resource) *time.Time {
+	if r.DeletedTime != nil {
+		return r.DeletedTime
+	}
+	return r.LastUpdateTime
+}
+
+// GetTrashList 查询回收站中的资源以及它们的过期时间
+func GetTrashList(shortName string) (vo.TrashListResp, error) {
+	resources, err := mysql.GetTrashList(shortName)
+	if err != nil {
+		return vo.TrashListResp{}, err
+	}
+	retentionDays, _ := trashOptions()
+	resp := vo.TrashListResp{
+		List:          make([]vo.TrashItem, 0, len(resources)),
+		TotalCount:    len(resources),
+		RetentionDays: retentionDays,
+	}
+	for _, r := range resources {
+		item := vo.TrashItem{ResourceInfoDTO: doResourceToDTO(r)}
+		if deletedAt := trashDeletedAt(r); deletedAt != nil {
+			item.DeletedTime = deletedAt.Format("2006-01-02 15:04:05")
+			if retentionDays >= 0 {
+				item.ExpireAt = deletedAt.AddDate(0, 0, retentionDays).Format("2006-01-02 15:04:05")
+			}
+		}
+		resp.List = append(resp.List, item)
+	}
+	return resp, nil
+}
+
+// DeleteFromTrash 从回收站彻底删除指定资源，单个失败不影响其他资源
+func (svc *ResourceService) DeleteFromTrash(ctx context.Context, ids []int) *dto.TrashPurgeResultDTO {
+	result := &dto.TrashPurgeResultDTO{Total: len(ids), Errors: []string{}}
+	for _, id := range ids {
+		resource, err := mysql.GetResourceByID(id)
+		if err == nil && resource.IsDeleted != model.ResourceIsDeleted {
+			err = mysql.ErrResourceNotInTrash
+		}
+		if err == nil {
+			err = svc.hardDeleteResource(ctx, resource)
+		}
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				err = mysql.ErrResourceNotInTrash
+			}
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("resource %d: %v", id, err))
+			continue
+		}
+		result.Deleted++
+	}
+	zap.L().Info("DeleteFromTrash() finished", zap.Ints("ids", ids), zap.Int("deleted", result.Deleted), zap.Int("failed", result.Failed))
+	return result
+}
+
+// PurgeExpiredTrash 定时任务：彻底删除超过保留期的回收站资源
+func (svc *ResourceService) PurgeExpiredTrash(ctx context.Context) (*dto.TrashPurgeResultDTO, error) {
+	retentionDays, maxPerRun := trashOptions()
+	if retentionDays < 0 {
+		return &dto.TrashPurgeResultDTO{Skipped: true, Errors: []string{}}, nil
+	}
+	token, err := newLockToken()
+	if err != nil {
+		return nil, err
+	}
+	locked, err := redis.AcquireLock(ctx, redis.TrashPurgeLockKey, token, trashPurgeLockTTL)
+	if err != nil {
+		zap.L().Error("redis.AcquireLock() failed", zap.Error(err))
+		return nil, err
+	}
+	if !locked {
+		zap.L().Info("Another trash purge is running, skip this round")
+		return &dto.TrashPurgeResultDTO{Skipped: true, Errors: []string{}}, nil
+	}
+	defer func() {
+		if err := redis.ReleaseLock(context.Background(), redis.TrashPurgeLockKey, token); err != nil {
+			zap.L().Warn("redis.ReleaseLock() failed", zap.Error(err))
+		}
+	}()
+
+	before := time.Now().AddDate(0, 0, -retentionDays)
+	resources, err := mysql.GetExpiredTrash(before, maxPerRun)
+	if err != nil {
+		return nil, err
+	}
+	result := &dto.TrashPurgeResultDTO{
+		Total:     len(resources),
+		Truncated: maxPerRun > 0 && len(resources) == maxPerRun,
+		Errors:    []string{},
+	}
+	for _, r := range resources {
+		if ctx.Err() != nil {
+			result.Truncated = true
+			break
+		}
+		if err = svc.hardDeleteResource(ctx, r); err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("resource %d: %v", r.ID, err))
+			continue
+		}
+		result.Deleted++
+	}
+	zap.L().Info("PurgeExpiredTrash() finished",
+		zap.Int("retentionDays", retentionDays),
+		zap.Int("total", result.Total),
+		zap.Int("deleted", result.Deleted),
+		zap.Int("failed", result.Failed))
+	return result, nil
+}
+
+// hardDeleteResource 先删除数据库记录（以 is_deleted = 1 为条件，避免与恢复操作竞争），再删除 COS 对象
+// COS 删除失败时只留下孤儿对象，可由对账任务清理
+func (svc *ResourceService) hardDeleteResource(ctx context.Context, r do.Resource) error {
+	// 重新上传同名文件时多条记录会共用同一个 COS 对象，此时只删除数据库记录
+	shared, err := mysql.CountResourcesSharingPath(r.ID, r.ShortName, r.Name)
+	if err != nil {
+		return err
+	}
+	if err = mysql.HardDeleteResource(r.ID); err != nil {
+		return err
+	}
+	svc.InvalidateSource(r.Md5)
+	if shared > 0 {
+		zap.L().Info("COS object is shared with other resources, keep it", zap.Int("id", r.ID), zap.String("name", r.Name))
+		return nil
+	}
+	cosPath := fmt.Sprintf("%s%s/%s", util.CosDownloadsPrefix, r.ShortName, r.Name)
+	if err = svc.CosClient.DeleteObject(ctx, cosPath); err != nil {
+		zap.L().Warn("Resource row deleted but COS object remains, reconcile will clean it", zap.String("cosPath", cosPath), zap.Error(err))
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -9,45 +10,72 @@ import (
 	"logo_api/model/university/dto"
 	"logo_api/model/university/vo"
 	"logo_api/settings"
+	"logo_api/util"
 )
 
 // GetUniversityFromName 根据单个 name 获取单个 university 对象
+// name 可以是 short_name、中文名、识别码(slug)、英文全称、官网域名、别名或拼音，响应中的 matchedBy 说明命中的标识
 func GetUniversityFromName(name string) (vo.UniversityResp, error) {
 	var (
 		daoUniversity  do.University
 		respUniversity vo.UniversityResp
 		err            error
 	)
-	if daoUniversity, err = mysql.GetUniversityByName(name); err != nil {
+	match, err := ResolveUniversity(name)
+	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			zap.L().Error("GetUniversityFromName() failed because university could not found", zap.String("name", name), zap.Error(err))
 			return vo.UniversityResp{}, errors.New("university not found")
 		}
-		zap.L().Error("mysql.GetUniversityByName() failed", zap.String("name", name), zap.Error(err))
 		return vo.UniversityResp{}, err
 	}
-	respUniversity = vo.UniversityResp{
-		Slug:             daoUniversity.Slug,
-		ShortName:        daoUniversity.ShortName,
-		Title:            daoUniversity.Title,
-		Website:          daoUniversity.Website,
-		FullNameEn:       daoUniversity.FullNameEn,
-		Region:           daoUniversity.Region,
-		Province:         daoUniversity.Province,
-		City:             daoUniversity.City,
-		HasVector:        daoUniversity.HasVector,
-		ResourceCount:    daoUniversity.ResourceCount,
-		CreatedTime:      daoUniversity.CreatedTime,
-		UpdatedTime:      daoUniversity.UpdatedTime,
-		Vis:              daoUniversity.Vis,
-		Story:            daoUniversity.Story,
-		MainVectorFormat: daoUniversity.MainVectorFormat,
-		ComputationID:    daoUniversity.ComputationID,
+	if daoUniversity, err = mysql.GetUniversityByName(match.ShortName); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			zap.L().Error("GetUniversityFromName() failed because university could not found", zap.String("name", name), zap.Error(err))
+			return vo.UniversityResp{}, errors.New("university not found")
+		}
+		zap.L().Error("mysql.GetUniversityByName() failed", zap.String("name", name), zap.Error(err))
+		return vo.UniversityResp{}, err
 	}
+	respUniversity = universityToResp(daoUniversity)
+	respUniversity.MatchedBy = match.MatchedBy
 	zap.L().Info("getUniversityFromName() success", zap.String("name", name))
 	return respUniversity, nil
 }
 
+// universityToResp 把数据库中的高校转换为接口响应
+func universityToResp(u do.University) vo.UniversityResp {
+	return vo.UniversityResp{
+		Slug:             u.Slug,
+		ShortName:        u.ShortName,
+		Title:            u.Title,
+		Website:          u.Website,
+		FullNameEn:       u.FullNameEn,
+		Region:           u.Region,
+		Province:         u.Province,
+		City:             u.City,
+		HasVector:        u.HasVector,
+		ResourceCount:    u.ResourceCount,
+		CreatedTime:      u.CreatedTime,
+		UpdatedTime:      u.UpdatedTime,
+		Vis:              u.Vis,
+		Story:            u.Story,
+		MainVectorFormat: u.MainVectorFormat,
+		ComputationID:    u.ComputationID,
+		Palette:          paletteHex(u.Palette),
+		Version:          u.Version,
+	}
+}
+
+// paletteHex 把存储的主色转换为 NormalizeColor 格式的十六进制颜色列表
+func paletteHex(stored string) []string {
+	colors := make([]string, 0, 5)
+	for _, p := range util.ParsePalette(stored) {
+		colors = append(colors, util.NormalizeColor(p.Hex()))
+	}
+	return colors
+}
+
 // InsertUniversity 插入单个 University 对象
 func InsertUniversity(reqUniversities []dto.UniversityInsertReq) error {
 
@@ -58,42 +86,91 @@ func InsertUniversity(reqUniversities []dto.UniversityInsertReq) error {
 		return nil
 	}
 	for _, reqU := range reqUniversities {
-		newDaoUniversity := settings.Universities{
-			Slug:          reqU.Slug,
-			ShortName:     reqU.ShortName,
-			Title:         reqU.Title,
-			Website:       reqU.Website,
-			FullNameEn:    reqU.FullNameEn,
-			Region:        reqU.Region,
-			Province:      reqU.Province,
-			City:          reqU.City,
-			HasVector:     0,
-			ResourceCount: 0,
-			// 处理可空字段
-			Vis:              reqU.Vis,
-			Story:            reqU.Story,
-			MainVectorFormat: nil,
-			ComputationID:    nil,
-
-			// 处理日期字段
-			// 让数据库自动更新
-		}
-		daoUniversities = append(daoUniversities, newDaoUniversity)
+		daoUniversities = append(daoUniversities, newUniversityRow(reqU))
 	}
 
 	if err := mysql.InsertUniversities(daoUniversities); err != nil {
 		zap.L().Error("mysql.InsertUniversities() failed", zap.Error(err))
 		return err
 	}
+	invalidateUniversityNames()
 	zap.L().Info("InsertUniversities() success", zap.Int("success count", len(daoUniversities)))
 	return nil
 }
 
-func UpdateUniversities(reqs []dto.UniversityUpdateReq) error {
-	if err := mysql.UpdateUniversities(reqs); err != nil {
+// newUniversityRow 把新增请求转换为待插入的记录，同时生成中文名的全拼和首字母
+func newUniversityRow(reqU dto.UniversityInsertReq) settings.Universities {
+	pinyin, initials := util.TitlePinyin(reqU.Title)
+	return settings.Universities{
+		Slug:          reqU.Slug,
+		ShortName:     reqU.ShortName,
+		Title:         reqU.Title,
+		Pinyin:        pinyin,
+		Initials:      initials,
+		Website:       reqU.Website,
+		FullNameEn:    reqU.FullNameEn,
+		Region:        reqU.Region,
+		Province:      reqU.Province,
+		City:          reqU.City,
+		HasVector:     0,
+		ResourceCount: 0,
+		// 处理可空字段
+		Vis:              reqU.Vis,
+		Story:            reqU.Story,
+		MainVectorFormat: nil,
+		ComputationID:    nil,
+
+		// 处理日期字段
+		// 让数据库自动更新
+	}
+}
+
+// UpdateUniversities 更新高校信息；涉及 short_name 修改时，在后台执行创建出的 COS 目录迁移任务并返回任务 id
+// 迁移中断的任务会在服务重启或定时触发时继续执行，也可以通过 /admin/renameJob 接口查看和重试
+func (svc *ResourceService) UpdateUniversities(reqs []dto.UniversityUpdateReq) ([]int, error) {
+	jobIDs, err := mysql.UpdateUniversities(reqs)
+	if err != nil {
 		zap.L().Error("mysql.UpdateUniversities() failed", zap.Error(err))
-		return err
+		return nil, err
 	}
-	zap.L().Info("service.UpdateUniversities() success", zap.Int("count", len(reqs)))
-	return nil
+	invalidateUniversityNames()
+	svc.runRenameJobsInBackground(jobIDs)
+	zap.L().Info("service.UpdateUniversities() success", zap.Int("count", len(reqs)), zap.Ints("renameJobs", jobIDs))
+	return jobIDs, nil
+}
+
+// runRenameJobsInBackground 在后台依次执行 COS 目录迁移任务，失败的任务等待重试
+func (svc *ResourceService) runRenameJobsInBackground(jobIDs []int) {
+	if len(jobIDs) == 0 {
+		return
+	}
+	go func() {
+		for _, id := range jobIDs {
+			if _, err := svc.RunRenameJob(context.Background(), id); err != nil {
+				zap.L().Error("svc.RunRenameJob() failed, will retry later", zap.Int("id", id), zap.Error(err))
+			}
+		}
+	}()
+}
+
+// RebuildUniversityPinyin 为全部高校重新生成全拼和首字母，用于存量数据和多音字词表更新后
+func RebuildUniversityPinyin() (int, error) {
+	universities, err := mysql.GetAllUniversities()
+	if err != nil {
+		return 0, err
+	}
+	updated := 0
+	for _, u := range universities {
+		pinyin, initials := util.TitlePinyin(u.Title)
+		if pinyin == u.Pinyin && initials == u.Initials {
+			continue
+		}
+		if err = mysql.UpdateUniversityPinyin(u.Slug, pinyin, initials); err != nil {
+			return updated, err
+		}
+		updated++
+	}
+	invalidateUniversityNames()
+	zap.L().Info("service.RebuildUniversityPinyin() success", zap.Int("total", len(universities)), zap.Int("updated", updated))
+	return updated, nil
 }
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"logo_api/dao/mysql"
+	"logo_api/model/university/do"
+	"logo_api/model/university/dto"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrAliasTaken 别名已被其他别名或其他高校的中文名、简称占用
+	ErrAliasTaken = errors.New("alias has been taken")
+	// ErrAliasInvalid 别名为空或生效日期晚于失效日期
+	ErrAliasInvalid = errors.New("invalid alias")
+)
+
+// aliasDateLayout 别名生效、失效日期的格式
+const aliasDateLayout = "2006-01-02"
+
+// GetUniversityAliases 查询别名列表，slug 为空表示全部高校
+func GetUniversityAliases(slug string) ([]do.UniversityAlias, error) {
+	return mysql.GetUniversityAliases(slug)
+}
+
+// InsertUniversityAlias 新增别名；高校不存在时返回 gorm.ErrRecordNotFound
+func InsertUniversityAlias(req dto.UniversityAliasInsertReq) (do.UniversityAlias, error) {
+	alias, err := buildUniversityAlias(req, 0)
+	if err != nil {
+		return do.UniversityAlias{}, err
+	}
+	if err = mysql.InsertUniversityAlias(&alias); err != nil {
+		return do.UniversityAlias{}, err
+	}
+	invalidateUniversityNames()
+	zap.L().Info("service.InsertUniversityAlias() success", zap.Any("alias", alias))
+	return alias, nil
+}
+
+// UpdateUniversityAlias 修改别名；别名或高校不存在时返回 gorm.ErrRecordNotFound
+func UpdateUniversityAlias(req dto.UniversityAliasUpdateReq) (do.UniversityAlias, error) {
+	if _, err := mysql.GetUniversityAlias(req.ID); err != nil {
+		return do.UniversityAlias{}, err
+	}
+	alias, err := buildUniversityAlias(req.UniversityAliasInsertReq, req.ID)
+	if err != nil {
+		return do.UniversityAlias{}, err
+	}
+	alias.ID = req.ID
+	if err = mysql.UpdateUniversityAlias(&alias); err != nil {
+		return do.UniversityAlias{}, err
+	}
+	invalidateUniversityNames()
+	zap.L().Info("service.UpdateUniversityAlias() success", zap.Any("alias", alias))
+	return mysql.GetUniversityAlias(req.ID)
+}
+
+// DeleteUniversityAliases 删除别名，返回实际删除的数量
+func DeleteUniversityAliases(ids []int) (int64, error) {
+	deleted, err := mysql.DeleteUniversityAliases(ids)
+	if err != nil {
+		return 0, err
+	}
+	invalidateUniversityNames()
+	zap.L().Info("service.DeleteUniversityAliases() success", zap.Ints("ids", ids), zap.Int64("deleted", deleted))
+	return deleted, nil
+}
+
+// buildUniversityAlias 校验请求并构建别名记录，excludeID 为正在修改的别名 id
+func buildUniversityAlias(req dto.UniversityAliasInsertReq, excludeID int) (do.UniversityAlias, error) {
+	alias := do.UniversityAlias{
+		Slug:      strings.TrimSpace(req.Slug),
+		Alias:     strings.TrimSpace(req.Alias),
+		AliasType: req.AliasType,
+	}
+	if alias.Alias == "" {
+		return do.UniversityAlias{}, fmt.Errorf("%w: alias is empty", ErrAliasInvalid)
+	}
+	var err error
+	if alias.ValidFrom, err = parseAliasDate(req.ValidFrom); err != nil {
+		return do.UniversityAlias{}, err
+	}
+	if alias.ValidTo, err = parseAliasDate(req.ValidTo); err != nil {
+		return do.UniversityAlias{}, err
+	}
+	if alias.ValidFrom != nil && alias.ValidTo != nil && alias.ValidFrom.After(*alias.ValidTo) {
+		return do.UniversityAlias{}, fmt.Errorf("%w: validFrom must not be later than validTo", ErrAliasInvalid)
+	}
+	if _, err = mysql.GetUniversityBySlug(alias.Slug); err != nil {
+		return do.UniversityAlias{}, err
+	}
+	taken, err := mysql.IsUniversityNameTaken(alias.Alias, alias.Slug, excludeID)
+	if err != nil {
+		return do.UniversityAlias{}, err
+	}
+	if taken {
+		return do.UniversityAlias{}, fmt.Errorf("%w: %s", ErrAliasTaken, alias.Alias)
+	}
+	return alias, nil
+}
+
+// parseAliasDate 解析日期，空串表示不限
+func parseAliasDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.ParseInLocation(aliasDateLayout, value, time.Local)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
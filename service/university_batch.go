@@ -0,0 +1,153 @@
+package service
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"logo_api/dao/mysql"
+	"logo_api/model"
+	"logo_api/model/university/dto"
+	"logo_api/model/university/vo"
+	"logo_api/settings"
+	"strings"
+	"unicode/utf8"
+)
+
+// universityFieldLimit 高校字段的必填项和长度上限（字符数），value 取出 UniversityInsertReq 中对应的字段
+type universityFieldLimit struct {
+	field    string
+	maxLen   int
+	required bool
+	value    func(u *dto.UniversityInsertReq) *string
+}
+
+// universityFieldLimits 与建表语句一致，批量写入、导入和 PATCH 共用
+var universityFieldLimits = []universityFieldLimit{
+	{"slug", 10, true, func(u *dto.UniversityInsertReq) *string { return &u.Slug }},
+	{"shortName", 20, true, func(u *dto.UniversityInsertReq) *string { return &u.ShortName }},
+	{"title", 255, true, func(u *dto.UniversityInsertReq) *string { return &u.Title }},
+	{"website", 255, true, func(u *dto.UniversityInsertReq) *string { return &u.Website }},
+	{"fullNameEn", 100, true, func(u *dto.UniversityInsertReq) *string { return &u.FullNameEn }},
+	{"region", 10, true, func(u *dto.UniversityInsertReq) *string { return &u.Region }},
+	{"province", 50, true, func(u *dto.UniversityInsertReq) *string { return &u.Province }},
+	{"city", 50, true, func(u *dto.UniversityInsertReq) *string { return &u.City }},
+	{"vis", 255, false, func(u *dto.UniversityInsertReq) *string { return u.Vis }},
+}
+
+// ValidateUniversityRow 去掉字段两端空白并检查必填项和长度，返回字段级别的错误
+func ValidateUniversityRow(u *dto.UniversityInsertReq) []model.FieldError {
+	var errs []model.FieldError
+	for _, f := range universityFieldLimits {
+		errs = append(errs, f.check(f.value(u))...)
+	}
+	// 可空字段保留 nil 与空串的区别：upsert 时 nil 表示不修改，空串表示清空；新增时两者都写入 NULL
+	if u.Story != nil {
+		*u.Story = strings.TrimSpace(*u.Story)
+	}
+	return errs
+}
+
+// check 去掉 v 两端空白并检查必填项和长度，v 为 nil 时不检查
+func (f universityFieldLimit) check(v *string) []model.FieldError {
+	if v == nil {
+		return nil
+	}
+	*v = strings.TrimSpace(*v)
+	switch {
+	case *v == "" && f.required:
+		return []model.FieldError{{Field: f.field, Code: "required", Message: f.field + " is required"}}
+	case utf8.RuneCountInString(*v) > f.maxLen:
+		return []model.FieldError{{Field: f.field, Code: "too_long", Message: fmt.Sprintf("%s must not exceed %d characters", f.field, f.maxLen)}}
+	}
+	return nil
+}
+
+// BatchSaveUniversities 批量新增或 upsert 高校，返回每一行的处理结果
+// 校验不通过的行和同批内重复的 slug 不写库；allOrNothing 时只要有一行不成功，整批都不提交
+// dryRun 时其余行只读地按相同规则判断，不写库，返回的逐行结果即为实际执行时的预览
+func (svc *ResourceService) BatchSaveUniversities(req dto.UniversityBatchReq) (vo.UniversityBatchResp, error) {
+	resp := vo.UniversityBatchResp{Total: len(req.Items), Items: make([]dto.UniversityRowResult, len(req.Items))}
+
+	// 1. 逐行校验，同批内 slug 重复的后一行视为冲突
+	rows := make([]settings.Universities, 0, len(req.Items))
+	rowIndex := make([]int, 0, len(req.Items))
+	firstRow := make(map[string]int, len(req.Items))
+	for i := range req.Items {
+		item := req.Items[i]
+		result := dto.UniversityRowResult{Index: i}
+		if errs := ValidateUniversityRow(&item); len(errs) > 0 {
+			result.Slug, result.Status, result.Errors = item.Slug, model.UniversityRowInvalid, errs
+		} else if first, ok := firstRow[item.Slug]; ok {
+			result.Slug, result.Status = item.Slug, model.UniversityRowConflict
+			result.ConflictKey, result.ConflictWith = model.UniversityKeySlug, item.Slug
+			result.Message = fmt.Sprintf("duplicates item %d in the same batch", first)
+		} else {
+			firstRow[item.Slug] = i
+			rows = append(rows, newUniversityRow(item))
+			rowIndex = append(rowIndex, i)
+			continue
+		}
+		resp.Items[i] = result
+	}
+
+	// 2. 全部成功才提交模式下已有行失败时，其余行不再写库
+	if req.AllOrNothing && !req.DryRun && len(rows) < len(req.Items) {
+		for n, i := range rowIndex {
+			resp.Items[i] = dto.UniversityRowResult{Index: i, Slug: rows[n].Slug, Status: model.UniversityRowSkipped,
+				Message: "rolled back because another row failed"}
+		}
+		rows = nil
+	}
+
+	// 3. 写库（预览时只查询不写入）
+	if len(rows) > 0 && req.DryRun {
+		results, err := mysql.PreviewUniversities(rows, req.Mode == "upsert")
+		if err != nil {
+			zap.L().Error("mysql.PreviewUniversities() failed", zap.Int("rows", len(rows)), zap.Error(err))
+			return vo.UniversityBatchResp{}, err
+		}
+		for n, r := range results {
+			r.Index = rowIndex[n]
+			resp.Items[r.Index] = r
+		}
+	} else if len(rows) > 0 {
+		results, jobIDs, committed, err := mysql.SaveUniversities(rows, req.Mode == "upsert", req.AllOrNothing)
+		if err != nil {
+			zap.L().Error("mysql.SaveUniversities() failed", zap.Int("rows", len(rows)), zap.Error(err))
+			return vo.UniversityBatchResp{}, err
+		}
+		for n, r := range results {
+			r.Index = rowIndex[n]
+			resp.Items[r.Index] = r
+		}
+		resp.Committed = committed
+		if committed {
+			invalidateUniversityNames()
+		}
+		svc.runRenameJobsInBackground(jobIDs)
+	}
+
+	// 4. 统计
+	for _, r := range resp.Items {
+		switch r.Status {
+		case model.UniversityRowCreated:
+			resp.Created++
+		case model.UniversityRowUpdated:
+			resp.Updated++
+		case model.UniversityRowUnchanged:
+			resp.Unchanged++
+		case model.UniversityRowConflict:
+			resp.Conflicts++
+		case model.UniversityRowInvalid:
+			resp.Invalid++
+		case model.UniversityRowFailed:
+			resp.Failed++
+		case model.UniversityRowSkipped:
+			resp.Skipped++
+		}
+	}
+	resp.DryRun = req.DryRun
+	zap.L().Info("service.BatchSaveUniversities() finished", zap.String("mode", req.Mode), zap.Bool("allOrNothing", req.AllOrNothing), zap.Bool("dryRun", req.DryRun),
+		zap.Int("total", resp.Total), zap.Int("created", resp.Created), zap.Int("updated", resp.Updated),
+		zap.Int("conflicts", resp.Conflicts), zap.Int("invalid", resp.Invalid), zap.Int("failed", resp.Failed))
+	return resp, nil
+}
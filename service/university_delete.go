@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"logo_api/dao/mysql"
+	"logo_api/model/university/dto"
+	"logo_api/util"
+)
+
+// SoftDeleteUniversities 停用高校，返回实际停用的数量
+// 停用后清理它的转换缓存，避免已缓存的位图绕过数据库继续对外提供
+func (svc *ResourceService) SoftDeleteUniversities(ctx context.Context, slugs []string) (int, error) {
+	universities, err := mysql.SoftDeleteUniversities(slugs)
+	if err != nil {
+		return 0, err
+	}
+	if len(universities) > 0 {
+		invalidateUniversityNames()
+	}
+	for _, u := range universities {
+		svc.purgeUniversityVariants(ctx, u.ShortName)
+	}
+	zap.L().Info("service.SoftDeleteUniversities() success", zap.Strings("slugs", slugs), zap.Int("count", len(universities)))
+	return len(universities), nil
+}
+
+// RestoreUniversities 恢复已停用的高校，返回实际恢复的数量
+func RestoreUniversities(slugs []string) (int, error) {
+	universities, err := mysql.RestoreUniversities(slugs)
+	if err != nil {
+		return 0, err
+	}
+	if len(universities) > 0 {
+		invalidateUniversityNames()
+	}
+	zap.L().Info("service.RestoreUniversities() success", zap.Strings("slugs", slugs), zap.Int("count", len(universities)))
+	return len(universities), nil
+}
+
+// HardDeleteUniversities 彻底删除已停用的高校：先在事务中删除数据库记录，再清理转换缓存和 COS 目录
+// COS 删除失败只计入结果，孤儿对象可由对账任务清理
+func (svc *ResourceService) HardDeleteUniversities(ctx context.Context, slugs []string) *dto.UniversityHardDeleteResultDTO {
+	result := &dto.UniversityHardDeleteResultDTO{Total: len(slugs), Errors: []string{}}
+	for _, slug := range slugs {
+		university, resources, err := mysql.HardDeleteUniversity(slug)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				err = errors.New("university not found")
+			}
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("university %s: %v", slug, err))
+			continue
+		}
+		result.Deleted++
+		result.Resources += len(resources)
+
+		md5s := make([]string, 0, len(resources))
+		for _, r := range resources {
+			md5s = append(md5s, r.Md5)
+		}
+		svc.InvalidateSource(md5s...)
+		// 先清理缓存映射，再删除目录下剩余的源文件
+		svc.purgeUniversityVariants(ctx, university.ShortName)
+		deleted, failed := svc.deleteCosPrefix(ctx, fmt.Sprintf("%s%s/", util.CosDownloadsPrefix, university.ShortName))
+		result.Objects += deleted
+		result.ObjectFail += failed
+	}
+	if result.Deleted > 0 {
+		invalidateUniversityNames()
+	}
+	zap.L().Info("service.HardDeleteUniversities() finished", zap.Strings("slugs", slugs),
+		zap.Int("deleted", result.Deleted), zap.Int("failed", result.Failed),
+		zap.Int("resources", result.Resources), zap.Int("objects", result.Objects), zap.Int("objectFail", result.ObjectFail))
+	return result
+}
+
+// deleteCosPrefix 分批删除 COS 前缀下的全部对象，返回删除成功和失败的数量
+func (svc *ResourceService) deleteCosPrefix(ctx context.Context, prefix string) (deleted, failed int) {
+	objects, err := svc.CosClient.ListObjects(ctx, prefix)
+	if err != nil {
+		zap.L().Warn("CosClient.ListObjects() failed, objects are left for reconcile", zap.String("prefix", prefix), zap.Error(err))
+		return 0, 0
+	}
+	for start := 0; start < len(objects); start += util.MaxDeleteMultiKeys {
+		end := min(start+util.MaxDeleteMultiKeys, len(objects))
+		keys := make([]string, 0, end-start)
+		for _, o := range objects[start:end] {
+			keys = append(keys, o.Key)
+		}
+		failedKeys, err := svc.CosClient.DeleteObjects(ctx, keys)
+		if err != nil {
+			zap.L().Warn("CosClient.DeleteObjects() failed, objects are left for reconcile", zap.String("prefix", prefix), zap.Error(err))
+			failed += len(keys)
+			continue
+		}
+		deleted += len(keys) - len(failedKeys)
+		failed += len(failedKeys)
+	}
+	return deleted, failed
+}
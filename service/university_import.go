@@ -0,0 +1,296 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"io"
+	"logo_api/dao/mysql"
+	"logo_api/model/university/do"
+	"logo_api/model/university/dto"
+	"logo_api/model/university/vo"
+	"logo_api/util"
+	"mime/multipart"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// importMaxRows 单个导入文件最多处理的数据行数，与 /university/batch 的 items 上限一致
+	importMaxRows = 1000
+	// importMaxFileSize 导入文件大小上限
+	importMaxFileSize = 10 << 20
+)
+
+var (
+	// ErrImportFileTooLarge 导入文件过大
+	ErrImportFileTooLarge = fmt.Errorf("import file is larger than %d MB", importMaxFileSize>>20)
+	// ErrImportTooManyRows 导入文件中的数据行过多
+	ErrImportTooManyRows = fmt.Errorf("import file contains more than %d rows", importMaxRows)
+	// ErrImportMappingInvalid 列映射不合法或缺少必需的列
+	ErrImportMappingInvalid = errors.New("invalid column mapping")
+)
+
+// universityImportFields 可导入的字段（同 UniversityInsertReq 的 json 名），也是导出文件的前几列
+var universityImportFields = []string{"slug", "shortName", "title", "vis", "website", "fullNameEn", "region", "province", "city", "story"}
+
+// universityExportColumns 导出文件中只读的统计列，导入时会被忽略
+var universityExportColumns = []string{"resourceCount", "hasVector", "mainVectorFormat", "version", "isDeleted", "createdTime", "updatedTime"}
+
+// ImportColumn 源文件中参与导入的一列，Index 为该列在表头中的位置
+type ImportColumn struct {
+	Index int
+	dto.ColumnMapping
+}
+
+// ImportUniversities 解析 CSV/XLSX/JSON 文件，按列映射转换为高校数据后走与 /university/batch 相同的校验和写入流程
+// 导入总是全部成功才提交：预览（默认）时只读地判断每一行，返回逐行 diff；正式导入时任意一行不成功则整批回滚
+func (svc *ResourceService) ImportUniversities(req dto.UniversityImportReq) (vo.UniversityImportResp, error) {
+	// 1. 读取并解析文件
+	format, header, rows, err := readImportFile(req.File, req.Format)
+	if err != nil {
+		return vo.UniversityImportResp{}, err
+	}
+
+	// 2. 确定列映射：upsert 以文件内容为准覆盖已有高校，所有字段都必须有对应的列（vis、story 的空单元格表示清空）
+	mode := req.Mode
+	if mode == "" {
+		mode = "insert"
+	}
+	columns, ignored, err := ResolveImportColumns(header, req.Mapping, mode == "upsert")
+	if err != nil {
+		return vo.UniversityImportResp{}, err
+	}
+
+	// 3. 转换为 UniversityInsertReq，校验和写库与批量接口一致
+	items := make([]dto.UniversityInsertReq, len(rows))
+	for i, row := range rows {
+		values := make(map[string]string, len(columns))
+		for _, c := range columns {
+			values[c.Field] = row.Cells[c.Index]
+		}
+		items[i] = universityFromValues(values)
+	}
+	dryRun := req.DryRun == nil || *req.DryRun
+	batch, err := svc.BatchSaveUniversities(dto.UniversityBatchReq{Mode: mode, AllOrNothing: true, DryRun: dryRun, Items: items})
+	if err != nil {
+		return vo.UniversityImportResp{}, err
+	}
+	for i := range batch.Items {
+		batch.Items[i].Line = rows[i].Line
+	}
+
+	resp := vo.UniversityImportResp{Format: format, Columns: make([]dto.ColumnMapping, 0, len(columns)), Ignored: ignored, UniversityBatchResp: batch}
+	for _, c := range columns {
+		resp.Columns = append(resp.Columns, c.ColumnMapping)
+	}
+	zap.L().Info("service.ImportUniversities() finished", zap.String("filename", req.File.Filename), zap.String("format", format),
+		zap.String("mode", mode), zap.Bool("dryRun", dryRun), zap.Int("rows", len(rows)), zap.Bool("committed", batch.Committed))
+	return resp, nil
+}
+
+// readImportFile 读取上传的导入文件并解析为表头和数据行，限制文件大小和行数
+func readImportFile(file *multipart.FileHeader, format string) (string, []string, []util.TableRow, error) {
+	if file.Size > importMaxFileSize {
+		return "", nil, nil, ErrImportFileTooLarge
+	}
+	format, err := util.DetectTableFormat(format, file.Filename)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	f, err := file.Open()
+	if err != nil {
+		return "", nil, nil, err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, importMaxFileSize+1))
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if len(data) > importMaxFileSize {
+		return "", nil, nil, ErrImportFileTooLarge
+	}
+	header, rows, err := util.ReadTable(format, data)
+	if err != nil {
+		zap.L().Info("util.ReadTable() failed", zap.String("filename", file.Filename), zap.String("format", format), zap.Error(err))
+		return "", nil, nil, err
+	}
+	if len(rows) == 0 {
+		return "", nil, nil, fmt.Errorf("%w: no data rows", util.ErrTableInvalid)
+	}
+	if len(rows) > importMaxRows {
+		return "", nil, nil, ErrImportTooManyRows
+	}
+	return format, header, rows, nil
+}
+
+// ResolveImportColumns 根据显式映射和列名自动匹配确定每个字段对应的列，返回参与导入的列和被忽略的列
+// 显式映射中的列必须存在、字段必须合法；同一字段不能对应多列；缺少必需字段时报错（requireAll 时所有字段都必需）
+func ResolveImportColumns(header []string, mapping string, requireAll bool) ([]ImportColumn, []string, error) {
+	return resolveImportColumns(header, mapping, universityImportFields, func(field string) bool {
+		return requireAll || isRequiredUniversityField(field)
+	})
+}
+
+// resolveImportColumns 按可导入字段 fields 解析列映射，required 判断字段是否必须有对应的列
+func resolveImportColumns(header []string, mapping string, fields []string, required func(field string) bool) ([]ImportColumn, []string, error) {
+	explicit := make(map[string]string)
+	if strings.TrimSpace(mapping) != "" {
+		if err := json.Unmarshal([]byte(mapping), &explicit); err != nil {
+			return nil, nil, fmt.Errorf("%w: mapping must be a json object of column -> field", ErrImportMappingInvalid)
+		}
+	}
+	headerIndex := make(map[string]bool, len(header))
+	for _, h := range header {
+		headerIndex[h] = true
+	}
+	for column := range explicit {
+		if !headerIndex[column] {
+			return nil, nil, fmt.Errorf("%w: column %q not found in file", ErrImportMappingInvalid, column)
+		}
+	}
+
+	var columns []ImportColumn
+	ignored := make([]string, 0)
+	mappedBy := make(map[string]string)
+	for i, h := range header {
+		target, ok := explicit[h]
+		if !ok {
+			target = h
+		}
+		field := importField(fields, target)
+		switch {
+		case ok && strings.TrimSpace(target) == "", !ok && field == "":
+			ignored = append(ignored, h)
+			continue
+		case field == "":
+			return nil, nil, fmt.Errorf("%w: unknown field %q for column %q", ErrImportMappingInvalid, target, h)
+		}
+		if prev, dup := mappedBy[field]; dup {
+			return nil, nil, fmt.Errorf("%w: field %s is mapped from both %q and %q", ErrImportMappingInvalid, field, prev, h)
+		}
+		mappedBy[field] = h
+		columns = append(columns, ImportColumn{Index: i, ColumnMapping: dto.ColumnMapping{Column: h, Field: field}})
+	}
+
+	var missing []string
+	for _, field := range fields {
+		if _, ok := mappedBy[field]; !ok && required(field) {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, nil, fmt.Errorf("%w: missing columns for %s", ErrImportMappingInvalid, strings.Join(missing, ", "))
+	}
+	return columns, ignored, nil
+}
+
+// importField 把列名或字段名规范化后匹配可导入的字段，忽略大小写、下划线、连字符和空格
+func importField(fields []string, name string) string {
+	normalized := normalizeColumnName(name)
+	for _, field := range fields {
+		if normalizeColumnName(field) == normalized {
+			return field
+		}
+	}
+	return ""
+}
+
+// normalizeColumnName 转小写并去掉下划线、连字符和空格
+func normalizeColumnName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '_', '-', ' ':
+			return -1
+		}
+		return r
+	}, strings.ToLower(strings.TrimSpace(name)))
+}
+
+// isRequiredUniversityField 是否为新增高校时的必填字段
+func isRequiredUniversityField(field string) bool {
+	for _, f := range universityFieldLimits {
+		if f.field == field {
+			return f.required
+		}
+	}
+	return false
+}
+
+// universityFromValues 由字段值构造 UniversityInsertReq；vis、story 没有对应的列时为 nil，有列但单元格为空时为空串
+func universityFromValues(values map[string]string) dto.UniversityInsertReq {
+	u := dto.UniversityInsertReq{
+		Slug:       values["slug"],
+		ShortName:  values["shortName"],
+		Title:      values["title"],
+		Website:    values["website"],
+		FullNameEn: values["fullNameEn"],
+		Region:     values["region"],
+		Province:   values["province"],
+		City:       values["city"],
+	}
+	if v, ok := values["vis"]; ok {
+		u.Vis = &v
+	}
+	if v, ok := values["story"]; ok {
+		u.Story = &v
+	}
+	return u
+}
+
+// ExportUniversities 按筛选条件导出高校目录，返回文件内容
+// CSV/XLSX 的前几列与导入字段一致，可修改后直接导入；JSON 为 UniversityResp 数组
+func ExportUniversities(req dto.UniversityExportReq) ([]byte, error) {
+	universities, err := mysql.ExportUniversities(req.UniversityGetListReq)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if req.Format == util.TableFormatJSON {
+		list := make([]vo.UniversityResp, 0, len(universities))
+		for _, u := range universities {
+			list = append(list, universityToResp(u))
+		}
+		if err = json.NewEncoder(&buf).Encode(list); err != nil {
+			return nil, err
+		}
+	} else {
+		header := append(append([]string{}, universityImportFields...), universityExportColumns...)
+		rows := make([][]string, 0, len(universities))
+		for _, u := range universities {
+			rows = append(rows, universityExportRow(u))
+		}
+		if err = util.WriteTable(req.Format, &buf, header, rows); err != nil {
+			return nil, err
+		}
+	}
+	zap.L().Info("service.ExportUniversities() success", zap.String("format", req.Format), zap.Int("count", len(universities)))
+	return buf.Bytes(), nil
+}
+
+// universityExportRow 按 universityImportFields + universityExportColumns 的顺序输出一行
+func universityExportRow(u do.University) []string {
+	return []string{
+		u.Slug, u.ShortName, u.Title, stringOrEmpty(u.Vis), u.Website, u.FullNameEn, u.Region, u.Province, u.City, stringOrEmpty(u.Story),
+		strconv.Itoa(u.ResourceCount), strconv.Itoa(u.HasVector), stringOrEmpty(u.MainVectorFormat), strconv.Itoa(u.Version),
+		strconv.Itoa(u.IsDeleted), formatExportTime(u.CreatedTime), formatExportTime(u.UpdatedTime),
+	}
+}
+
+func stringOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// formatExportTime 与接口返回的 lastUpdateTime 格式一致
+func formatExportTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"errors"
+	"go.uber.org/zap"
+	"logo_api/dao/mysql"
+	"logo_api/model"
+	"logo_api/model/university/dto"
+	"logo_api/model/university/vo"
+	"strings"
+)
+
+// ValidateUniversityPatch 去掉传入字段两端的空白，按 universityFieldLimits 检查必填项和长度，返回字段级别的错误
+// 未传入的字段不检查；vis 传入空串表示清空，story 不限长度
+func ValidateUniversityPatch(req *dto.UniversityPatchReq) []model.FieldError {
+	fields := map[string]*string{
+		"shortName":  req.ShortName,
+		"title":      req.Title,
+		"vis":        req.Vis,
+		"website":    req.Website,
+		"fullNameEn": req.FullNameEn,
+		"region":     req.Region,
+		"province":   req.Province,
+		"city":       req.City,
+	}
+	var errs []model.FieldError
+	for _, f := range universityFieldLimits {
+		if v, ok := fields[f.field]; ok {
+			errs = append(errs, f.check(v)...)
+		}
+	}
+	if req.Story != nil {
+		*req.Story = strings.TrimSpace(*req.Story)
+	}
+	return errs
+}
+
+// PatchUniversity 按 If-Match 中的版本号部分更新高校信息，short_name 变化时在后台执行 COS 目录迁移
+// req 须先经过 ValidateUniversityPatch；版本不一致时返回当前数据和 mysql.ErrUniversityVersionChanged，便于调用方合并后重试
+func (svc *ResourceService) PatchUniversity(slug string, version int, req dto.UniversityPatchReq) (vo.UniversityResp, int, error) {
+	university, jobID, err := mysql.PatchUniversity(slug, version, req)
+	if err != nil {
+		if errors.Is(err, mysql.ErrUniversityVersionChanged) {
+			return universityToResp(university), 0, err
+		}
+		return vo.UniversityResp{}, 0, err
+	}
+	if university.Version != version {
+		invalidateUniversityNames()
+	}
+	if jobID != 0 {
+		svc.runRenameJobsInBackground([]int{jobID})
+	}
+	zap.L().Info("service.PatchUniversity() success", zap.String("slug", slug), zap.Int("version", university.Version), zap.Int("renameJob", jobID))
+	return universityToResp(university), jobID, nil
+}
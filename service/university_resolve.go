@@ -0,0 +1,84 @@
+package service
+
+import (
+	"errors"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"logo_api/dao/mysql"
+	"logo_api/model/university/dto"
+	"sync"
+	"time"
+)
+
+const (
+	// resolveCacheTTL 名称解析结果在进程内的缓存时间；高校或别名变更时整体失效
+	resolveCacheTTL = 5 * time.Minute
+	// resolveCacheMaxEntries 缓存条目上限，超过后清空重建，避免被大量随机名称撑大
+	resolveCacheMaxEntries = 10000
+)
+
+type resolveCacheEntry struct {
+	match    dto.UniversityMatch
+	found    bool
+	cachedAt time.Time
+}
+
+// resolveCache getLogo 等热点路径的名称解析缓存，找不到的结果同样缓存
+type resolveCache struct {
+	mu      sync.Mutex
+	entries map[string]resolveCacheEntry
+}
+
+var universityResolveCache = &resolveCache{entries: make(map[string]resolveCacheEntry)}
+
+func (rc *resolveCache) get(name string) (resolveCacheEntry, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	e, ok := rc.entries[name]
+	if !ok || time.Since(e.cachedAt) >= resolveCacheTTL {
+		return resolveCacheEntry{}, false
+	}
+	return e, true
+}
+
+func (rc *resolveCache) set(name string, e resolveCacheEntry) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if len(rc.entries) >= resolveCacheMaxEntries {
+		rc.entries = make(map[string]resolveCacheEntry)
+	}
+	rc.entries[name] = e
+}
+
+func (rc *resolveCache) invalidate() {
+	rc.mu.Lock()
+	rc.entries = make(map[string]resolveCacheEntry)
+	rc.mu.Unlock()
+}
+
+// ResolveUniversity 统一的高校标识解析：short_name、中文名、识别码(slug)、英文全称、官网域名、别名、拼音，
+// 返回命中的高校及标识类型；找不到时返回 gorm.ErrRecordNotFound
+func ResolveUniversity(name string) (dto.UniversityMatch, error) {
+	if e, ok := universityResolveCache.get(name); ok {
+		if !e.found {
+			return dto.UniversityMatch{}, gorm.ErrRecordNotFound
+		}
+		return e.match, nil
+	}
+	match, err := mysql.ResolveUniversity(name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			universityResolveCache.set(name, resolveCacheEntry{cachedAt: time.Now()})
+		}
+		return dto.UniversityMatch{}, err
+	}
+	universityResolveCache.set(name, resolveCacheEntry{match: match, found: true, cachedAt: time.Now()})
+	zap.L().Debug("service.ResolveUniversity() resolved", zap.String("name", name), zap.Any("match", match))
+	return match, nil
+}
+
+// invalidateUniversityNames 高校或别名变更后，使名称解析缓存和名称建议索引失效
+func invalidateUniversityNames() {
+	universityResolveCache.invalidate()
+	universityNameIndex.invalidate()
+}
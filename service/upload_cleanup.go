@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"logo_api/dao/redis"
+	"logo_api/model/cos/dto"
+	"logo_api/util"
+	"strings"
+	"time"
+)
+
+const uploadCleanupLockTTL = 10 * time.Minute
+
+// CleanAbandonedUploads 定时任务：清理被放弃的上传
+// 预签名直传的凭据过期后，客户端已无法回调，暂存目录中超过 地址有效期 + presignTicketGrace 的对象直接删除；
+// 分块上传会话过期后 COS 中的分块仍会计费，发起时间超过会话有效期、且名称已不再被占用的分块上传会被放弃
+func (svc *ResourceService) CleanAbandonedUploads(ctx context.Context) (*dto.UploadCleanResultDTO, error) {
+	token, err := newLockToken()
+	if err != nil {
+		return nil, err
+	}
+	locked, err := redis.AcquireLock(ctx, redis.UploadCleanupLockKey, token, uploadCleanupLockTTL)
+	if err != nil {
+		zap.L().Error("redis.AcquireLock() failed", zap.Error(err))
+		return nil, err
+	}
+	if !locked {
+		zap.L().Info("Another upload cleanup is running, skip this round")
+		return &dto.UploadCleanResultDTO{Skipped: true, Errors: []string{}}, nil
+	}
+	defer func() {
+		if err := redis.ReleaseLock(context.Background(), redis.UploadCleanupLockKey, token); err != nil {
+			zap.L().Warn("redis.ReleaseLock() failed", zap.Error(err))
+		}
+	}()
+
+	result := &dto.UploadCleanResultDTO{Errors: []string{}}
+	if err = svc.cleanStagingObjects(ctx, result); err != nil {
+		return nil, err
+	}
+	if err = svc.abortExpiredMultipartUploads(ctx, result); err != nil {
+		return nil, err
+	}
+	zap.L().Info("CleanAbandonedUploads() finished",
+		zap.Int("stagingDeleted", result.StagingDeleted),
+		zap.Int("uploadsAborted", result.UploadsAborted),
+		zap.Int("errors", len(result.Errors)))
+	return result, nil
+}
+
+// cleanStagingObjects 删除凭据已过期的直传暂存对象，单批删除失败只记录到 Errors
+func (svc *ResourceService) cleanStagingObjects(ctx context.Context, result *dto.UploadCleanResultDTO) error {
+	objects, err := svc.CosClient.ListObjects(ctx, util.CosStagingPrefix)
+	if err != nil {
+		zap.L().Error("CosClient.ListObjects() failed", zap.String("prefix", util.CosStagingPrefix), zap.Error(err))
+		return err
+	}
+	expiredBefore := time.Now().Add(-(presignExpire() + presignTicketGrace))
+	var paths []string
+	for _, obj := range objects {
+		if obj.LastModified.Before(expiredBefore) {
+			paths = append(paths, obj.Key)
+		}
+	}
+	for start := 0; start < len(paths); start += util.MaxDeleteMultiKeys {
+		batch := paths[start:min(start+util.MaxDeleteMultiKeys, len(paths))]
+		failed, err := svc.CosClient.DeleteObjects(ctx, batch)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("delete staging objects: %v", err))
+			continue
+		}
+		for _, p := range batch {
+			if reason, ok := failed[p]; ok {
+				result.Errors = append(result.Errors, fmt.Sprintf("delete staging object %s: %s", p, reason))
+				continue
+			}
+			result.StagingDeleted++
+		}
+	}
+	return nil
+}
+
+// abortExpiredMultipartUploads 放弃已过期会话留下的分块上传
+// 会话每收到一个分块都会续期，发起时间早于会话有效期的上传仍可能在进行中；名称占用与会话同时续期，占用仍在时跳过
+func (svc *ResourceService) abortExpiredMultipartUploads(ctx context.Context, result *dto.UploadCleanResultDTO) error {
+	uploads, err := svc.CosClient.ListMultipartUploads(ctx, util.CosDownloadsPrefix)
+	if err != nil {
+		return err
+	}
+	expiredBefore := time.Now().Add(-newChunkOptions().sessionTTL)
+	for _, u := range uploads {
+		if ctx.Err() != nil {
+			break
+		}
+		if !u.Initiated.Before(expiredBefore) {
+			continue
+		}
+		shortName, name, _ := strings.Cut(strings.TrimPrefix(u.Key, util.CosDownloadsPrefix), "/")
+		held, err := redis.LockHeld(ctx, resourceNameKey(shortName, name))
+		if err != nil {
+			zap.L().Error("redis.LockHeld() failed", zap.String("key", u.Key), zap.Error(err))
+			return err
+		}
+		if held {
+			continue
+		}
+		if err = svc.CosClient.AbortMultipartUpload(ctx, u.Key, u.UploadID); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("abort multipart upload %s: %v", u.Key, err))
+			continue
+		}
+		result.UploadsAborted++
+	}
+	return nil
+}
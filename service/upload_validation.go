@@ -0,0 +1,246 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"image"
+	"logo_api/dao/mysql"
+	"logo_api/model"
+	"logo_api/model/resource/do"
+	"logo_api/settings"
+	"logo_api/util"
+	"mime/multipart"
+	"path"
+	"strings"
+)
+
+const (
+	defaultUploadMaxSizeBytes int64 = 20 << 20 // 默认单个文件上限 20MB
+	defaultUploadMinWidth           = 64
+	defaultUploadMinHeight          = 64
+)
+
+// UploadValidationError 上传校验失败，包含每个字段的错误明细
+type UploadValidationError struct {
+	Errors []model.FieldError
+}
+
+func (e *UploadValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", fe.Field, fe.Message))
+	}
+	return "upload validation failed: " + strings.Join(msgs, "; ")
+}
+
+// uploadCandidate 待校验的上传内容
+type uploadCandidate struct {
+	File        *multipart.FileHeader
+	Type        string
+	Name        string
+	Title       string
+	ShortName   string
+	UsedForEdge int
+	// SkipUniversityCheck 替换版本时 title/shortName 取自已有资源，无需再次校验
+	SkipUniversityCheck bool
+}
+
+// uploadMetadata 不经过 Gin 接收文件的上传（分块上传、预签名直传）在会话中保存的资源元数据
+type uploadMetadata struct {
+	Title           string `json:"title"`
+	ShortName       string `json:"shortName"`
+	Name            string `json:"name"`
+	Type            string `json:"type"`
+	UsedForEdge     int    `json:"usedForEdge"`
+	BackgroundColor string `json:"backgroundColor"`
+}
+
+// candidate 转换为只含元数据的校验输入
+func (m uploadMetadata) candidate() uploadCandidate {
+	return uploadCandidate{
+		Type:        m.Type,
+		Name:        m.Name,
+		Title:       m.Title,
+		ShortName:   m.ShortName,
+		UsedForEdge: m.UsedForEdge,
+	}
+}
+
+// toEntity 与 ResourceInsertReq.ToEntity 相同的字段规则，md5、大小和尺寸由调用方在文件写入 COS 后得到，感知哈希和主色由 storedImageFeatures 补充
+func (m uploadMetadata) toEntity(md5Val string, size int64, width, height int) *do.Resource {
+	resource := &do.Resource{
+		Title:           m.Title,
+		ShortName:       m.ShortName,
+		Name:            m.Name,
+		Type:            m.Type,
+		Md5:             md5Val,
+		Size:            int(size),
+		Width:           width,
+		Height:          height,
+		UsedForEdge:     m.UsedForEdge,
+		BackgroundColor: m.BackgroundColor,
+		IsDeleted:       model.ResourceIsActive,
+	}
+	if m.Type == "svg" {
+		resource.IsVector = 1
+	} else if width > 0 {
+		resource.IsBitmap = 1
+	}
+	return resource
+}
+
+// uploadLimits 上传限制
+type uploadLimits struct {
+	maxSizeBytes        int64
+	minWidth, minHeight int
+}
+
+func newUploadLimits() uploadLimits {
+	limits := uploadLimits{
+		maxSizeBytes: defaultUploadMaxSizeBytes,
+		minWidth:     defaultUploadMinWidth,
+		minHeight:    defaultUploadMinHeight,
+	}
+	if cfg := settings.Config.UploadConfig; cfg != nil {
+		if cfg.MaxSizeBytes > 0 {
+			limits.maxSizeBytes = cfg.MaxSizeBytes
+		}
+		if cfg.MinWidth > 0 {
+			limits.minWidth = cfg.MinWidth
+		}
+		if cfg.MinHeight > 0 {
+			limits.minHeight = cfg.MinHeight
+		}
+	}
+	return limits
+}
+
+// validateUpload 上传校验流水线：大小 -> 内容类型 -> 位图尺寸 -> 文件名后缀 -> usedForEdge -> 所属高校
+// 所有字段错误一次性返回；只有读取文件失败时才返回普通 error
+func validateUpload(u uploadCandidate) error {
+	limits := newUploadLimits()
+	var errs []model.FieldError
+	addErr := func(field, code, format string, args ...interface{}) {
+		errs = append(errs, model.FieldError{Field: field, Code: code, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// 1. 文件大小
+	if u.File.Size <= 0 {
+		addErr("file", "empty", "file is empty")
+	} else if u.File.Size > limits.maxSizeBytes {
+		addErr("file", "too_large", "file size %d bytes exceeds the limit of %d bytes", u.File.Size, limits.maxSizeBytes)
+	}
+
+	// 2. 内容类型与位图尺寸
+	contentErrs, err := validateUploadContent(u.File, u.Type, limits)
+	if err != nil {
+		return err
+	}
+	errs = append(errs, contentErrs...)
+
+	// 3. 文件名后缀、usedForEdge、所属高校
+	metaErrs, err := validateUploadMetadata(u)
+	if err != nil {
+		return err
+	}
+	errs = append(errs, metaErrs...)
+
+	if len(errs) > 0 {
+		zap.L().Info("validateUpload() rejected", zap.String("filename", u.File.Filename), zap.Any("errors", errs))
+		return &UploadValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// validateUploadContent 根据文件内容校验：嗅探类型与声明的 type 比对 -> 位图最小尺寸。分块上传时 file 为第一个分块
+func validateUploadContent(file *multipart.FileHeader, fileType string, limits uploadLimits) ([]model.FieldError, error) {
+	var errs []model.FieldError
+	addErr := func(field, code, format string, args ...interface{}) {
+		errs = append(errs, model.FieldError{Field: field, Code: code, Message: fmt.Sprintf(format, args...)})
+	}
+	declared := util.NormalizeFileType(fileType)
+
+	// 1. 嗅探内容类型，与声明的 type 比对
+	sniffed, mime, err := util.SniffFileType(file)
+	if err != nil {
+		zap.L().Error("util.SniffFileType() failed", zap.String("filename", file.Filename), zap.Error(err))
+		return nil, err
+	}
+	switch {
+	case !util.IsUploadType(declared):
+		// 只接受白名单中的类型，html 等其他类型即使内容无法识别也拒绝
+		addErr("type", "unsupported_type", "type %q is not allowed, only vector, bitmap, zip and rar files can be uploaded", fileType)
+	case sniffed == "" && util.AllowsUnknownContent(declared):
+		// ai/eps 可能没有可识别的文件头，无法嗅探时放行
+	case !util.FileTypeMatches(declared, sniffed):
+		actual := sniffed
+		if actual == "" {
+			actual = "unknown"
+		}
+		addErr("type", "type_mismatch", "declared type %q does not match file content (detected %s, %s)", fileType, actual, mime)
+	}
+
+	// 2. 位图最小尺寸
+	if util.IsBitmapType(sniffed) {
+		width, height, decodeErr := decodeImageSize(file)
+		if decodeErr != nil {
+			addErr("file", "corrupted", "bitmap could not be decoded: %v", decodeErr)
+		} else if width < limits.minWidth || height < limits.minHeight {
+			addErr("file", "too_small", "bitmap is %dx%d, minimum is %dx%d", width, height, limits.minWidth, limits.minHeight)
+		}
+	}
+	return errs, nil
+}
+
+// validateUploadMetadata 只依赖元数据的校验：文件名后缀 -> usedForEdge -> 所属高校，分块上传在初始化时也会调用
+func validateUploadMetadata(u uploadCandidate) ([]model.FieldError, error) {
+	var errs []model.FieldError
+	addErr := func(field, code, format string, args ...interface{}) {
+		errs = append(errs, model.FieldError{Field: field, Code: code, Message: fmt.Sprintf(format, args...)})
+	}
+	declared := util.NormalizeFileType(u.Type)
+
+	// 1. 文件名后缀与 type 一致，COS 路径和下载文件名都依赖后缀
+	if u.Name != "" {
+		if ext := util.NormalizeFileType(path.Ext(u.Name)); ext != declared {
+			addErr("name", "extension_mismatch", "name extension %q does not match type %q", path.Ext(u.Name), u.Type)
+		}
+	}
+
+	// 2. 只有矢量文件可以作为边缘计算主输入文件
+	if u.UsedForEdge == 1 && !util.IsVectorType(declared) {
+		addErr("usedForEdge", "not_vector", "usedForEdge=1 only applies to vector files (svg/ai/eps/pdf), got %q", u.Type)
+	}
+
+	// 3. title 与 shortName 必须对应同一所已存在且未停用的高校
+	if !u.SkipUniversityCheck {
+		university, dbErr := mysql.GetUniversityByShortName(u.ShortName)
+		switch {
+		case errors.Is(dbErr, gorm.ErrRecordNotFound):
+			addErr("shortName", "not_found", "university %q does not exist", u.ShortName)
+		case dbErr != nil:
+			return nil, dbErr
+		case university.IsDeleted == model.UniversityIsDeleted:
+			addErr("shortName", "deleted", "university %q has been deleted, restore it before uploading", u.ShortName)
+		case university.Title != u.Title:
+			addErr("title", "mismatch", "title %q does not match university %q (%s)", u.Title, u.ShortName, university.Title)
+		}
+	}
+	return errs, nil
+}
+
+// decodeImageSize 只读取图片头获取尺寸
+func decodeImageSize(fileHeader *multipart.FileHeader) (int, int, error) {
+	f, err := fileHeader.Open()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+	cfg, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return 0, 0, err
+	}
+	return cfg.Width, cfg.Height, nil
+}
@@ -19,6 +19,13 @@ type AppConfig struct {
 	RedisConfig *RedisConfig `mapstructure:"redis"`
 	CosConfig   *CosConfig   `mapstructure:"cos"`
 	JWTSecret   string       `mapstructure:"jwt_secret"`
+	// CacheClearSecret 定时触发器调用 /clearCache 时使用的共享密钥，为空则只允许管理员调用
+	CacheClearSecret string `mapstructure:"cache_clear_secret"`
+
+	L1CacheConfig *L1CacheConfig `mapstructure:"l1_cache"`
+	CleanerConfig *CleanerConfig `mapstructure:"cleaner"`
+	TrashConfig   *TrashConfig   `mapstructure:"trash"`
+	UploadConfig  *UploadConfig  `mapstructure:"upload"`
 }
 
 type AppSettings struct {
@@ -27,6 +34,8 @@ type AppSettings struct {
 	Version string `mapstructure:"version"`
 	Mode    string `mapstructure:"mode"`
 	Name    string `mapstructure:"name"`
+	// Admins 拥有后台管理权限的用户名列表（环境变量中使用逗号分隔）
+	Admins []string `mapstructure:"admins"`
 }
 
 type LogConfig struct {
@@ -61,10 +70,48 @@ type CosConfig struct {
 	SecretKey string `mapstructure:"secret_key"`
 }
 
+// L1CacheConfig 进程内 logo 缓存配置，单位均为字节，0 表示使用默认值
+type L1CacheConfig struct {
+	MaxBytes      int64 `mapstructure:"max_bytes"`
+	MaxEntryBytes int64 `mapstructure:"max_entry_bytes"`
+}
+
+// CleanerConfig 过期缓存清理任务配置，0 表示使用默认值，负数表示不限制
+type CleanerConfig struct {
+	BatchSize      int `mapstructure:"batch_size"`      // 单批删除数量（最大 1000）
+	Concurrency    int `mapstructure:"concurrency"`     // 并发批次数
+	MaxPerRun      int `mapstructure:"max_per_run"`     // 单次运行最多清理的数量
+	TimeoutSeconds int `mapstructure:"timeout_seconds"` // 单次运行最长时间
+}
+
+// TrashConfig 回收站配置：软删除的资源超过保留天数后由定时任务彻底删除，0 表示使用默认值，负数表示不自动清理
+type TrashConfig struct {
+	RetentionDays int `mapstructure:"retention_days"` // 保留天数
+	MaxPerRun     int `mapstructure:"max_per_run"`    // 单次运行最多删除的数量
+}
+
+// UploadConfig 上传校验配置，0 表示使用默认值
+type UploadConfig struct {
+	MaxSizeBytes int64 `mapstructure:"max_size_bytes"` // 单个文件大小上限
+	MinWidth     int   `mapstructure:"min_width"`      // 位图最小宽度(px)
+	MinHeight    int   `mapstructure:"min_height"`     // 位图最小高度(px)
+
+	ChunkMaxSizeBytes  int64 `mapstructure:"chunk_max_size_bytes"`  // 分块上传的文件大小上限
+	ChunkPartSizeBytes int64 `mapstructure:"chunk_part_size_bytes"` // 分块大小，除最后一块外每块必须等于该值
+	ChunkSessionHours  int   `mapstructure:"chunk_session_hours"`   // 分块上传会话的有效期，每上传一块刷新
+
+	PresignExpireMinutes int `mapstructure:"presign_expire_minutes"` // 预签名上传/下载地址的有效期
+
+	SimilarThreshold int    `mapstructure:"similar_threshold"` // 感知哈希汉明距离不超过该值视为近似重复
+	SimilarPolicy    string `mapstructure:"similar_policy"`    // 近似重复的处理方式：warn（默认，仍然插入并返回提示）/ block / off
+}
+
 type Universities struct {
 	Slug      string `gorm:"column:slug;primaryKey" json:"slug"`
 	ShortName string `gorm:"column:short_name" json:"short_name"`
 	Title     string `gorm:"column:title" json:"title"`
+	Pinyin    string `gorm:"column:pinyin" json:"pinyin"`
+	Initials  string `gorm:"column:initials" json:"initials"`
 	// 使用 *string 处理 NULL 字段
 	Vis              *string `gorm:"column:vis" json:"vis"`
 	Website          string  `gorm:"column:website" json:"website"`
@@ -200,4 +247,16 @@ func ensureConfigPointers() {
 	if Config.CosConfig == nil {
 		Config.CosConfig = &CosConfig{}
 	}
+	if Config.L1CacheConfig == nil {
+		Config.L1CacheConfig = &L1CacheConfig{}
+	}
+	if Config.CleanerConfig == nil {
+		Config.CleanerConfig = &CleanerConfig{}
+	}
+	if Config.TrashConfig == nil {
+		Config.TrashConfig = &TrashConfig{}
+	}
+	if Config.UploadConfig == nil {
+		Config.UploadConfig = &UploadConfig{}
+	}
 }
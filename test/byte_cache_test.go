@@ -0,0 +1,83 @@
+package test
+
+import (
+	"logo_api/util"
+	"testing"
+	"time"
+)
+
+func TestByteCacheEviction(t *testing.T) {
+	cache := util.NewByteCache(10, 0)
+	var invalidated []string
+	cache.OnInvalidate(func(key string) {
+		invalidated = append(invalidated, key)
+	})
+
+	cache.Set("a", []byte("1234"), "a.png")
+	cache.Set("b", []byte("1234"), "b.png")
+	if _, _, ok := cache.Get("a"); !ok { // a 变为最近使用
+		t.Fatalf("expected a to be cached")
+	}
+	cache.Set("c", []byte("1234"), "c.png") // 超出容量，淘汰最久未使用的 b
+
+	if _, _, ok := cache.Get("b"); ok {
+		t.Errorf("expected b to be evicted")
+	}
+	data, label, ok := cache.Get("a")
+	if !ok || string(data) != "1234" || label != "a.png" {
+		t.Errorf("unexpected entry for a: %q %q %v", data, label, ok)
+	}
+	if len(invalidated) != 1 || invalidated[0] != "b" {
+		t.Errorf("expected hook for b, got %v", invalidated)
+	}
+
+	stats := cache.Stats()
+	if stats.Hits != 2 || stats.Misses != 1 || stats.Evictions != 1 || stats.Entries != 2 || stats.Bytes != 8 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestByteCacheInvalidate(t *testing.T) {
+	cache := util.NewByteCache(1024, 8)
+	cache.Set("variant:1", []byte("x"), "")
+	cache.Set("variant:2", []byte("y"), "")
+	cache.Set("source:1", []byte("z"), "")
+	cache.Set("too-big", []byte("123456789"), "") // 超过单条上限，不缓存
+
+	if _, _, ok := cache.Get("too-big"); ok {
+		t.Errorf("entries larger than maxEntryBytes should not be cached")
+	}
+	if n := cache.InvalidatePrefix("variant:"); n != 2 {
+		t.Errorf("expected 2 variant entries removed, got %d", n)
+	}
+	cache.Invalidate("source:1")
+	if stats := cache.Stats(); stats.Entries != 0 || stats.Bytes != 0 {
+		t.Errorf("expected empty cache, got %+v", stats)
+	}
+}
+
+func TestByteCacheTTL(t *testing.T) {
+	cache := util.NewByteCache(1024, 0)
+	var invalidated []string
+	cache.OnInvalidate(func(key string) {
+		invalidated = append(invalidated, key)
+	})
+	cache.SetWithTTL("variant:1", []byte("x"), "", 20*time.Millisecond)
+	cache.Set("source:1", []byte("y"), "")
+	if _, _, ok := cache.Get("variant:1"); !ok {
+		t.Fatalf("expected variant:1 to be cached before it expires")
+	}
+	time.Sleep(30 * time.Millisecond)
+	if _, _, ok := cache.Get("variant:1"); ok {
+		t.Errorf("expected variant:1 to expire")
+	}
+	if _, _, ok := cache.Get("source:1"); !ok {
+		t.Errorf("entries without ttl should not expire")
+	}
+	if len(invalidated) != 1 || invalidated[0] != "variant:1" {
+		t.Errorf("expected hook for the expired entry, got %v", invalidated)
+	}
+	if stats := cache.Stats(); stats.Entries != 1 || stats.Bytes != 1 {
+		t.Errorf("expired entry should be removed, got %+v", stats)
+	}
+}
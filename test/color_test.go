@@ -29,3 +29,18 @@ func TestNormalizeColor(t *testing.T) {
 		}
 	}
 }
+
+func TestExtractSvgPalette(t *testing.T) {
+	svg := []byte(`<svg xmlns="http://www.w3.org/2000/svg">
+<path fill="#003f88" d="M0 0"/><path fill="#003F88" d="M1 1"/>
+<circle style="fill:rgb(255,255,255);stroke:none" r="1"/>
+<rect fill="none" stroke="url(#g)" fill-rule="evenodd"/><stop stop-color="red"/>
+</svg>`)
+	palette := util.ExtractSvgPalette(svg)
+	if len(palette) != 3 {
+		t.Fatalf("expected 3 colors, got %v", palette)
+	}
+	if palette[0].Hex() != "#003F88" || palette[0].Weight != 0.5 {
+		t.Errorf("expected #003F88 with weight 0.5 first, got %s %.2f", palette[0].Hex(), palette[0].Weight)
+	}
+}
@@ -0,0 +1,62 @@
+package test
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"logo_api/util"
+	"testing"
+)
+
+func TestSniffFileType(t *testing.T) {
+	var pngBuf bytes.Buffer
+	if err := png.Encode(&pngBuf, image.NewRGBA(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatal(err)
+	}
+	svg := []byte(`<?xml version="1.0" encoding="UTF-8"?><svg xmlns="http://www.w3.org/2000/svg"></svg>`)
+
+	cases := []struct {
+		filename string
+		content  []byte
+		want     string
+	}{
+		{"logo.png", pngBuf.Bytes(), "png"},
+		{"logo.svg", svg, "svg"},
+		{"fake.svg", pngBuf.Bytes(), "png"}, // 后缀是 svg，但内容是 png
+		{"notes.txt", []byte("hello"), ""},
+	}
+	for _, c := range cases {
+		fh, err := util.NewFileHeader(c.filename, c.content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, _, err := util.SniffFileType(fh)
+		if err != nil {
+			t.Fatalf("%s: %v", c.filename, err)
+		}
+		if got != c.want {
+			t.Errorf("%s: got %q, want %q", c.filename, got, c.want)
+		}
+	}
+}
+
+func TestFileTypeMatches(t *testing.T) {
+	if !util.FileTypeMatches("JPEG", "jpg") {
+		t.Errorf("jpeg should match jpg")
+	}
+	if !util.FileTypeMatches("ai", "pdf") {
+		t.Errorf("ai should accept pdf-compatible content")
+	}
+	if util.FileTypeMatches("svg", "png") {
+		t.Errorf("svg should not match png")
+	}
+	if util.IsVectorType("png") || !util.IsVectorType(".SVG") {
+		t.Errorf("unexpected vector type detection")
+	}
+	if util.IsUploadType("html") || !util.IsUploadType("JPEG") || !util.IsUploadType("zip") {
+		t.Errorf("unexpected upload type whitelist")
+	}
+	if util.AllowsUnknownContent("svg") || !util.AllowsUnknownContent("ai") {
+		t.Errorf("only ai/eps may have unrecognized content")
+	}
+}
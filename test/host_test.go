@@ -0,0 +1,25 @@
+package test
+
+import (
+	"logo_api/util"
+	"testing"
+)
+
+func TestHostFromURL(t *testing.T) {
+	tests := map[string]string{
+		"https://www.PKU.edu.cn/index.htm": "pku.edu.cn",
+		"lib.pku.edu.cn":                   "lib.pku.edu.cn",
+		"http://www.sdut.edu.cn:8080/":     "sdut.edu.cn",
+		"pku":                              "",
+		"Peking University":                "",
+		"北京大学":                             "",
+	}
+	for input, expected := range tests {
+		if got := util.HostFromURL(input); got != expected {
+			t.Errorf("input: %s, expected: %q, got: %q", input, expected, got)
+		}
+	}
+	if !util.HostMatches("lib.pku.edu.cn", "pku.edu.cn") || util.HostMatches("xpku.edu.cn", "pku.edu.cn") {
+		t.Error("HostMatches should accept subdomains only")
+	}
+}
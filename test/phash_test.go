@@ -0,0 +1,61 @@
+package test
+
+import (
+	"image"
+	"image/color"
+	"logo_api/util"
+	"testing"
+)
+
+// gradient 生成一张水平渐变图，width/height 不同但内容相同
+func gradient(width, height int, invert bool) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			v := uint8(x * 255 / width)
+			if invert {
+				v = 255 - v
+			}
+			img.Set(x, y, color.RGBA{R: v, G: v, B: v, A: 255})
+		}
+	}
+	return img
+}
+
+func TestDHashNearDuplicate(t *testing.T) {
+	small := util.FormatPHash(util.DHash(gradient(64, 64, false)))
+	large := util.FormatPHash(util.DHash(gradient(512, 512, false)))
+	inverted := util.FormatPHash(util.DHash(gradient(512, 512, true)))
+
+	if d := util.PHashDistance(small, large); d > 6 {
+		t.Errorf("resized image should be a near duplicate, distance %d", d)
+	}
+	if d := util.PHashDistance(small, inverted); d < 32 {
+		t.Errorf("inverted image should not be similar, distance %d", d)
+	}
+	if d := util.PHashDistance(small, ""); d != -1 {
+		t.Errorf("empty hash should be invalid, got %d", d)
+	}
+}
+
+func TestExtractPalette(t *testing.T) {
+	// 左 3/4 为蓝色、右 1/4 为白色
+	img := image.NewRGBA(image.Rect(0, 0, 128, 128))
+	for y := 0; y < 128; y++ {
+		for x := 0; x < 128; x++ {
+			c := color.RGBA{R: 0, G: 63, B: 136, A: 255}
+			if x >= 96 {
+				c = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	palette := util.ExtractPalette(img)
+	if len(palette) < 2 || palette[0].Hex() != "#003F88" {
+		t.Fatalf("unexpected palette %s", util.FormatPalette(palette))
+	}
+	parsed := util.ParsePalette(util.FormatPalette(palette))
+	if sim := util.PaletteSimilarity(palette, parsed); sim < 0.99 {
+		t.Errorf("palette should survive format/parse, similarity %f", sim)
+	}
+}
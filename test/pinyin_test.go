@@ -0,0 +1,36 @@
+package test
+
+import (
+	"logo_api/util"
+	"testing"
+)
+
+func TestTitlePinyin(t *testing.T) {
+	tests := []struct {
+		title, full, initials string
+	}{
+		{"山东理工大学", "shandongligongdaxue", "sdlgdx"},
+		{"重庆大学", "chongqingdaxue", "cqdx"},
+		{"长沙理工大学", "changshaligongdaxue", "cslgdx"},
+		{"中国人民解放军91特色医学中心（北京）", "zhongguorenminjiefangjun91teseyixuezhongxinbeijing", "zgrmjfj91tsyxzxbj"},
+	}
+	for _, tt := range tests {
+		full, initials := util.TitlePinyin(tt.title)
+		if full != tt.full || initials != tt.initials {
+			t.Errorf("title: %s, expected: %s/%s, got: %s/%s", tt.title, tt.full, tt.initials, full, initials)
+		}
+	}
+}
+
+func TestNormalizePinyinQuery(t *testing.T) {
+	tests := map[string]string{
+		"SDLG":              "sdlg",
+		"shan dong li'gong": "shandongligong",
+		"山东":                "",
+	}
+	for input, expected := range tests {
+		if got := util.NormalizePinyinQuery(input); got != expected {
+			t.Errorf("input: %s, expected: %q, got: %q", input, expected, got)
+		}
+	}
+}
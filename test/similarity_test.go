@@ -0,0 +1,18 @@
+package test
+
+import (
+	"logo_api/util"
+	"testing"
+)
+
+func TestNameSimilarity(t *testing.T) {
+	if d := util.Levenshtein("清华大学", "清华大雪"); d != 1 {
+		t.Errorf("expected distance 1, got %d", d)
+	}
+	if d := util.Levenshtein("pku", ""); d != 3 {
+		t.Errorf("expected distance 3, got %d", d)
+	}
+	if s := util.NameSimilarity("tsinghua", "tsinghau"); s != 0.75 {
+		t.Errorf("expected similarity 0.75, got %v", s)
+	}
+}
@@ -0,0 +1,62 @@
+package test
+
+import (
+	"bytes"
+	"logo_api/util"
+	"reflect"
+	"testing"
+)
+
+func TestTableRoundTrip(t *testing.T) {
+	header := []string{"slug", "title", "story"}
+	rows := [][]string{
+		{"10001", "北京大学", "多行\n介绍, 含逗号和\"引号\""},
+		{"10002", "中国人民大学", ""},
+	}
+	for _, format := range []string{util.TableFormatCSV, util.TableFormatXLSX} {
+		var buf bytes.Buffer
+		if err := util.WriteTable(format, &buf, header, rows); err != nil {
+			t.Fatalf("%s: WriteTable failed: %v", format, err)
+		}
+		gotHeader, gotRows, err := util.ReadTable(format, buf.Bytes())
+		if err != nil {
+			t.Fatalf("%s: ReadTable failed: %v", format, err)
+		}
+		if !reflect.DeepEqual(gotHeader, header) {
+			t.Errorf("%s: expected header %v, got %v", format, header, gotHeader)
+		}
+		if len(gotRows) != len(rows) {
+			t.Fatalf("%s: expected %d rows, got %d", format, len(rows), len(gotRows))
+		}
+		for i, r := range gotRows {
+			if !reflect.DeepEqual(r.Cells, rows[i]) {
+				t.Errorf("%s: row %d expected %q, got %q", format, i, rows[i], r.Cells)
+			}
+		}
+		// 第二行数据在 XLSX 中位于第 3 行；CSV 中第一行数据的 story 跨两行，第二行数据从第 4 行开始
+		expectedLine := map[string]int{util.TableFormatCSV: 4, util.TableFormatXLSX: 3}[format]
+		if gotRows[1].Line != expectedLine {
+			t.Errorf("%s: expected line %d, got %d", format, expectedLine, gotRows[1].Line)
+		}
+	}
+}
+
+func TestReadJSONTable(t *testing.T) {
+	data := []byte(`[{"slug": "10001", "title": "北京大学", "vis": null}, {"title": "清华大学", "slug": 10003, "city": "北京"}]`)
+	header, rows, err := util.ReadTable(util.TableFormatJSON, data)
+	if err != nil {
+		t.Fatalf("ReadTable failed: %v", err)
+	}
+	if expected := []string{"slug", "title", "vis", "city"}; !reflect.DeepEqual(header, expected) {
+		t.Errorf("expected header %v, got %v", expected, header)
+	}
+	if expected := []string{"10003", "清华大学", "", "北京"}; !reflect.DeepEqual(rows[1].Cells, expected) {
+		t.Errorf("expected %q, got %q", expected, rows[1].Cells)
+	}
+	if len(rows[0].Cells) != len(header) || rows[1].Line != 2 {
+		t.Errorf("unexpected row shape: %+v", rows)
+	}
+	if _, _, err = util.ReadTable(util.TableFormatJSON, []byte(`{"slug": "10001"}`)); err == nil {
+		t.Error("expected error for non-array json")
+	}
+}
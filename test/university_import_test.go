@@ -0,0 +1,104 @@
+package test
+
+import (
+	"errors"
+	"logo_api/service"
+	"reflect"
+	"testing"
+)
+
+func TestResolveImportColumns(t *testing.T) {
+	required := []string{"slug", "shortName", "title", "website", "fullNameEn", "region", "province", "city"}
+	tests := []struct {
+		name       string
+		header     []string
+		mapping    string
+		requireAll bool
+		fields     []string // 按表头顺序参与导入的字段
+		ignored    []string
+		wantErr    bool
+	}{
+		{
+			name:    "自动匹配忽略大小写、下划线和空格",
+			header:  []string{"SLUG", "short_name", "Title", "website", "full name en", "region", "province", "city", "resourceCount"},
+			fields:  required,
+			ignored: []string{"resourceCount"},
+		},
+		{
+			name:    "显式映射优先，映射为空串的列被忽略",
+			header:  []string{"编号", "简称", "名称", "website", "fullNameEn", "region", "province", "city", "vis"},
+			mapping: `{"编号": "slug", "简称": "shortName", "名称": "title", "vis": ""}`,
+			fields:  required,
+			ignored: []string{"vis"},
+		},
+		{
+			name:    "显式映射的列不存在",
+			header:  required,
+			mapping: `{"编号": "slug"}`,
+			wantErr: true,
+		},
+		{
+			name:    "显式映射到未知字段",
+			header:  required,
+			mapping: `{"city": "town"}`,
+			wantErr: true,
+		},
+		{
+			name:    "映射不是 JSON 对象",
+			header:  required,
+			mapping: `["slug"]`,
+			wantErr: true,
+		},
+		{
+			name:    "同一字段对应多列",
+			header:  append([]string{"名称"}, required...),
+			mapping: `{"名称": "title"}`,
+			wantErr: true,
+		},
+		{
+			name:    "缺少必填字段",
+			header:  []string{"slug", "shortName", "title"},
+			wantErr: true,
+		},
+		{
+			name:       "requireAll 时可空字段也必须有列",
+			header:     required,
+			requireAll: true,
+			wantErr:    true,
+		},
+		{
+			name:       "requireAll 时全部字段齐全",
+			header:     append(append([]string{}, required...), "vis", "story"),
+			requireAll: true,
+			fields:     append(append([]string{}, required...), "vis", "story"),
+			ignored:    []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		columns, ignored, err := service.ResolveImportColumns(tt.header, tt.mapping, tt.requireAll)
+		if tt.wantErr {
+			if !errors.Is(err, service.ErrImportMappingInvalid) {
+				t.Errorf("%s: expected ErrImportMappingInvalid, got %v", tt.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		fields := make([]string, 0, len(columns))
+		for _, c := range columns {
+			fields = append(fields, c.Field)
+			if tt.header[c.Index] != c.Column {
+				t.Errorf("%s: column %q points to header %q", tt.name, c.Column, tt.header[c.Index])
+			}
+		}
+		if !reflect.DeepEqual(fields, tt.fields) {
+			t.Errorf("%s: expected fields %v, got %v", tt.name, tt.fields, fields)
+		}
+		if !reflect.DeepEqual(ignored, tt.ignored) {
+			t.Errorf("%s: expected ignored %v, got %v", tt.name, tt.ignored, ignored)
+		}
+	}
+}
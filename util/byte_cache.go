@@ -0,0 +1,220 @@
+package util
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ByteCacheStats L1 内存缓存的运行统计
+type ByteCacheStats struct {
+	Hits          int64 `json:"hits"`
+	Misses        int64 `json:"misses"`
+	Evictions     int64 `json:"evictions"`
+	Entries       int   `json:"entries"`
+	Bytes         int64 `json:"bytes"`
+	MaxBytes      int64 `json:"maxBytes"`
+	MaxEntryBytes int64 `json:"maxEntryBytes"`
+}
+
+// InvalidateHook 缓存条目被主动失效或被 LRU 淘汰时的回调
+type InvalidateHook func(key string)
+
+type byteCacheEntry struct {
+	key      string
+	data     []byte
+	label    string    // 附带信息（如资源文件名），随数据一起返回
+	expireAt time.Time // 过期时间，零值表示不过期
+}
+
+// ByteCache 有容量上限（按字节计）的进程内 LRU 缓存，用于缓存热门 logo 的二进制数据
+type ByteCache struct {
+	mu            sync.Mutex
+	maxBytes      int64
+	maxEntryBytes int64
+	curBytes      int64
+	ll            *list.List
+	items         map[string]*list.Element
+	hooks         []InvalidateHook
+
+	hits      atomic.Int64
+	misses    atomic.Int64
+	evictions atomic.Int64
+}
+
+// NewByteCache 创建 L1 缓存。maxBytes 为总容量，maxEntryBytes 为单个条目的上限（超过则不缓存）
+func NewByteCache(maxBytes, maxEntryBytes int64) *ByteCache {
+	if maxEntryBytes <= 0 || maxEntryBytes > maxBytes {
+		maxEntryBytes = maxBytes
+	}
+	return &ByteCache{
+		maxBytes:      maxBytes,
+		maxEntryBytes: maxEntryBytes,
+		ll:            list.New(),
+		items:         make(map[string]*list.Element),
+	}
+}
+
+// Get 读取缓存，命中时将条目移动到链表头部；已过期的条目被移除并视为未命中
+func (c *ByteCache) Get(key string) ([]byte, string, bool) {
+	if c == nil {
+		return nil, "", false
+	}
+	c.mu.Lock()
+	ele, ok := c.items[key]
+	if ok {
+		entry := ele.Value.(*byteCacheEntry)
+		if entry.expireAt.IsZero() || time.Now().Before(entry.expireAt) {
+			c.ll.MoveToFront(ele)
+			c.hits.Add(1)
+			c.mu.Unlock()
+			return entry.data, entry.label, true
+		}
+		c.removeElement(ele)
+	}
+	c.misses.Add(1)
+	hooks := c.hooks
+	c.mu.Unlock()
+	if ok {
+		for _, hook := range hooks {
+			hook(key)
+		}
+	}
+	return nil, "", false
+}
+
+// Set 写入不过期的缓存，超出容量时从链表尾部淘汰最久未使用的条目
+func (c *ByteCache) Set(key string, data []byte, label string) {
+	c.SetWithTTL(key, data, label, 0)
+}
+
+// SetWithTTL 写入缓存，ttl 之后的 Get 视为未命中；ttl <= 0 表示不过期
+// 用于内容可能在其他实例中被改变的条目（如转换结果），避免本实例长期返回旧数据
+func (c *ByteCache) SetWithTTL(key string, data []byte, label string, ttl time.Duration) {
+	if c == nil || c.maxBytes <= 0 || int64(len(data)) > c.maxEntryBytes {
+		return
+	}
+	var expireAt time.Time
+	if ttl > 0 {
+		expireAt = time.Now().Add(ttl)
+	}
+	var evicted []string
+	c.mu.Lock()
+	if ele, ok := c.items[key]; ok {
+		entry := ele.Value.(*byteCacheEntry)
+		c.curBytes += int64(len(data)) - int64(len(entry.data))
+		entry.data = data
+		entry.label = label
+		entry.expireAt = expireAt
+		c.ll.MoveToFront(ele)
+	} else {
+		c.items[key] = c.ll.PushFront(&byteCacheEntry{key: key, data: data, label: label, expireAt: expireAt})
+		c.curBytes += int64(len(data))
+	}
+	for c.curBytes > c.maxBytes {
+		tail := c.ll.Back()
+		if tail == nil {
+			break
+		}
+		evicted = append(evicted, c.removeElement(tail))
+		c.evictions.Add(1)
+	}
+	hooks := c.hooks
+	c.mu.Unlock()
+	// 回调放在锁外执行，避免回调中再次访问缓存造成死锁
+	for _, key := range evicted {
+		for _, hook := range hooks {
+			hook(key)
+		}
+	}
+}
+
+// Invalidate 主动失效指定的 key
+func (c *ByteCache) Invalidate(keys ...string) {
+	if c == nil {
+		return
+	}
+	var removed []string
+	c.mu.Lock()
+	for _, key := range keys {
+		if ele, ok := c.items[key]; ok {
+			removed = append(removed, c.removeElement(ele))
+		}
+	}
+	hooks := c.hooks
+	c.mu.Unlock()
+	for _, key := range removed {
+		for _, hook := range hooks {
+			hook(key)
+		}
+	}
+}
+
+// InvalidatePrefix 失效所有以 prefix 开头的 key，返回被移除的条目数
+func (c *ByteCache) InvalidatePrefix(prefix string) int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	keys := make([]string, 0)
+	for key := range c.items {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	c.mu.Unlock()
+	c.Invalidate(keys...)
+	return len(keys)
+}
+
+// Purge 清空全部缓存（不重置命中计数）
+func (c *ByteCache) Purge() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	keys := make([]string, 0, len(c.items))
+	for key := range c.items {
+		keys = append(keys, key)
+	}
+	c.mu.Unlock()
+	c.Invalidate(keys...)
+}
+
+// OnInvalidate 注册失效回调，条目被 Invalidate/Purge、LRU 淘汰或过期后被读取时都会触发
+func (c *ByteCache) OnInvalidate(hook InvalidateHook) {
+	if c == nil || hook == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hooks = append(c.hooks, hook)
+}
+
+// Stats 返回当前的命中/未命中计数和容量占用
+func (c *ByteCache) Stats() ByteCacheStats {
+	if c == nil {
+		return ByteCacheStats{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return ByteCacheStats{
+		Hits:          c.hits.Load(),
+		Misses:        c.misses.Load(),
+		Evictions:     c.evictions.Load(),
+		Entries:       c.ll.Len(),
+		Bytes:         c.curBytes,
+		MaxBytes:      c.maxBytes,
+		MaxEntryBytes: c.maxEntryBytes,
+	}
+}
+
+// removeElement 从链表和索引中移除条目（调用方必须持有锁）
+func (c *ByteCache) removeElement(ele *list.Element) string {
+	entry := c.ll.Remove(ele).(*byteCacheEntry)
+	delete(c.items, entry.key)
+	c.curBytes -= int64(len(entry.data))
+	return entry.key
+}
@@ -12,8 +12,16 @@ import (
 	"net/url"
 	"os"
 	"strings"
+	"time"
 )
 
+// CosDownloadsPrefix 所有资源文件在 COS 中的根目录，完整路径为 beacon/downloads/<short_name>/<name>
+const CosDownloadsPrefix = "beacon/downloads/"
+
+// CosStagingPrefix 预签名直传的暂存目录，完整路径为 beacon/uploads/<uploadId>/<name>，校验通过后复制到 CosDownloadsPrefix
+// 未完成回调的暂存对象不在资源表中，由存储桶对该前缀配置的生命周期规则清理
+const CosStagingPrefix = "beacon/uploads/"
+
 type CosClient struct {
 	Client *cos.Client
 }
@@ -66,8 +74,9 @@ func (c *CosClient) GetObjectByResourceName(resourceName string, shortName strin
 	return data, nil
 }
 
-// GetObjectByResourceNameAndSvgToBitmap 从腾讯云COS上获取矢量图资源，并进行格式转换，最后返回位图相关信息
-func (c *CosClient) GetObjectByResourceNameAndSvgToBitmap(resourceName, title, shortName, resourceType string, size int, width int, height int, bgColor string) (data []byte, bitmapInfo BitmapResourceInfo, err error) {
+// SvgToBitmap 对矢量图资源进行格式转换并上传到 shortName 目录，最后返回位图相关信息
+// 源文件由调用方从COS读取，便于在高校重命名期间从旧目录回退读取
+func (c *CosClient) SvgToBitmap(svgData []byte, resourceName, title, shortName, resourceType string, size int, width int, height int, bgColor string) (data []byte, bitmapInfo BitmapResourceInfo, err error) {
 	// 创建临时文件（系统临时目录下，自动生成唯一文件名）
 	tmpFile, err := os.CreateTemp("", resourceName) // "" 表示系统临时目录
 	if err != nil {
@@ -77,17 +86,8 @@ func (c *CosClient) GetObjectByResourceNameAndSvgToBitmap(resourceName, title, s
 	defer os.Remove(tmpFile.Name())
 	defer tmpFile.Close()
 
-	cosPath := fmt.Sprintf("beacon/downloads/%s/%s", shortName, resourceName)
-	resp, err := c.Client.Object.Get(context.Background(), cosPath, nil)
-	if err != nil {
-		zap.L().Error("cos.Object.Get() err:", zap.Error(err))
-		return nil, BitmapResourceInfo{}, err
-	}
-	defer resp.Body.Close()
-
-	// 直接把远程数据写入临时文件
-	if _, err = io.Copy(tmpFile, resp.Body); err != nil {
-		zap.L().Error("io.Copy() err:", zap.Error(err))
+	if _, err = tmpFile.Write(svgData); err != nil {
+		zap.L().Error("tmpFile.Write() err:", zap.Error(err))
 		return nil, BitmapResourceInfo{}, err
 	}
 	// 关闭临时文件用于后续读取
@@ -224,63 +224,229 @@ func (c *CosClient) DeleteObject(ctx context.Context, cosPath string) error {
 	return nil
 }
 
-func (c *CosClient) RenameFolder(ctx context.Context, oldShortName, newShortName string) error {
-	// 假设 oldShortName="beacon", newShortName="beacon7"
-	oldPrefix := fmt.Sprintf("beacon/downloads/%s/", oldShortName)
-	newPrefix := fmt.Sprintf("beacon/downloads/%s/", newShortName)
+// ObjectInfo COS 对象的基本信息
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	ETag         string // 简单上传时为文件 md5，分块上传时形如 "xxx-N"
+	LastModified time.Time
+}
 
-	// 分页处理标识
+// ListObjects 分页列出指定前缀下的所有对象
+func (c *CosClient) ListObjects(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
 	isTruncated := true
 	marker := ""
-
 	for isTruncated {
-		opt := &cos.BucketGetOptions{
-			Prefix: oldPrefix,
-			Marker: marker, // 用于获取下一页
-		}
-
-		// 采用分页，因为 c.Client.Bucket.Get 只能获取前 1000 份数据
-		result, _, err := c.Client.Bucket.Get(ctx, opt)
+		result, _, err := c.Client.Bucket.Get(ctx, &cos.BucketGetOptions{
+			Prefix:  prefix,
+			Marker:  marker,
+			MaxKeys: 1000,
+		})
 		if err != nil {
-			zap.L().Error("c.Client.Bucket.Get() err:", zap.Error(err))
-			return err
-		}
-		// 如果第一页就没有内容，说明文件夹根本不存在, 直接退出循环
-		if len(result.Contents) == 0 && marker == "" {
-			zap.L().Warn("RenameFolder failed: old folder not found", zap.String("prefix", oldPrefix))
-			return fmt.Errorf("old folder %s does not exist", oldShortName)
+			zap.L().Error("c.Client.Bucket.Get() err:", zap.String("prefix", prefix), zap.Error(err))
+			return nil, err
 		}
-
 		for _, obj := range result.Contents {
-			oldKey := obj.Key
-			// 路径替换：将 beacon/downloads/old/... 替换为 beacon/downloads/new/...
-			newKey := strings.Replace(oldKey, oldPrefix, newPrefix, 1)
-
-			// 1. 复制：创建源路径和目标路径的映射
-			// COS Copy API 需要 source 格式: bucketname-appid.cos.region.myqcloud.com/key
-			source := fmt.Sprintf("%s/%s", c.Client.BaseURL.BucketURL.Host, oldKey)
-			_, _, err := c.Client.Object.Copy(ctx, newKey, source, nil)
-			if err != nil {
-				zap.L().Error("Copy object failed", zap.String("oldKey", oldKey), zap.Error(err))
-				return err
-			}
-
-			// 2. 删除：删除原路径文件
-			err = c.DeleteObject(ctx, oldKey)
-			if err != nil {
-				zap.L().Error("Delete old object failed", zap.String("oldKey", oldKey), zap.Error(err))
-				return err
+			// 跳过"文件夹"占位对象
+			if strings.HasSuffix(obj.Key, "/") {
+				continue
 			}
+			lastModified, _ := time.Parse(time.RFC3339, obj.LastModified)
+			objects = append(objects, ObjectInfo{
+				Key:          obj.Key,
+				Size:         obj.Size,
+				ETag:         strings.Trim(obj.ETag, "\""),
+				LastModified: lastModified,
+			})
 		}
-
-		// 更新分页状态
 		isTruncated = result.IsTruncated
 		marker = result.NextMarker
+		// 未指定 delimiter 时 NextMarker 可能为空，此时以本页最后一个 key 作为下一页的起点
+		if isTruncated && marker == "" && len(result.Contents) > 0 {
+			marker = result.Contents[len(result.Contents)-1].Key
+		}
+	}
+	return objects, nil
+}
+
+// MaxDeleteMultiKeys COS 批量删除单次最多支持 1000 个对象
+const MaxDeleteMultiKeys = 1000
+
+// DeleteObjects 批量删除腾讯云COS中的对象，返回删除失败的路径及原因；整批请求失败时返回 error
+func (c *CosClient) DeleteObjects(ctx context.Context, cosPaths []string) (map[string]string, error) {
+	failed := make(map[string]string)
+	if len(cosPaths) == 0 {
+		return failed, nil
+	}
+	if len(cosPaths) > MaxDeleteMultiKeys {
+		return nil, fmt.Errorf("too many objects in one batch: %d > %d", len(cosPaths), MaxDeleteMultiKeys)
+	}
+	objects := make([]cos.Object, len(cosPaths))
+	for i, p := range cosPaths {
+		objects[i] = cos.Object{Key: p}
+	}
+	// Quiet 模式只返回删除失败的对象
+	res, _, err := c.Client.Object.DeleteMulti(ctx, &cos.ObjectDeleteMultiOptions{
+		Quiet:   true,
+		Objects: objects,
+	})
+	if err != nil {
+		zap.L().Error("DeleteObjects() err:", zap.Int("count", len(cosPaths)), zap.Error(err))
+		return nil, err
+	}
+	for _, e := range res.Errors {
+		failed[e.Key] = fmt.Sprintf("%s: %s", e.Code, e.Message)
+	}
+	zap.L().Info("DeleteObjects() success", zap.Int("count", len(cosPaths)), zap.Int("failed", len(failed)))
+	return failed, nil
+}
+
+// CopyObject 在同一存储桶内复制对象，目标已存在时直接覆盖，因此可以安全地重复执行
+func (c *CosClient) CopyObject(ctx context.Context, srcPath, dstPath string) error {
+	// COS Copy API 需要 source 格式: bucketname-appid.cos.region.myqcloud.com/key
+	source := fmt.Sprintf("%s/%s", c.Client.BaseURL.BucketURL.Host, srcPath)
+	if _, _, err := c.Client.Object.Copy(ctx, dstPath, source, nil); err != nil {
+		zap.L().Error("CopyObject() err:", zap.String("src", srcPath), zap.String("dst", dstPath), zap.Error(err))
+		return err
+	}
+	zap.L().Info("CopyObject() success", zap.String("src", srcPath), zap.String("dst", dstPath))
+	return nil
+}
+
+// ObjectExists 判断 COS 中对应路径的对象是否存在
+func (c *CosClient) ObjectExists(ctx context.Context, cosPath string) (bool, error) {
+	ok, err := c.Client.Object.IsExist(ctx, cosPath)
+	if err != nil {
+		zap.L().Error("ObjectExists() err:", zap.String("cosPath", cosPath), zap.Error(err))
+		return false, err
+	}
+	return ok, nil
+}
+
+// UploadedPart 分块上传中已上传的一个分块
+type UploadedPart struct {
+	PartNumber int
+	ETag       string
+}
+
+// InitiateMultipartUpload 初始化分块上传，返回 COS 的 UploadID
+func (c *CosClient) InitiateMultipartUpload(ctx context.Context, cosPath string) (string, error) {
+	result, _, err := c.Client.Object.InitiateMultipartUpload(ctx, cosPath, nil)
+	if err != nil {
+		zap.L().Error("c.Client.Object.InitiateMultipartUpload() err:", zap.String("cosPath", cosPath), zap.Error(err))
+		return "", err
 	}
+	return result.UploadID, nil
+}
 
-	zap.L().Info("Rename folder success",
-		zap.String("from", oldPrefix),
-		zap.String("to", newPrefix))
+// UploadPart 上传一个分块，返回分块的 ETag；同一 partNumber 重复上传会覆盖之前的分块
+func (c *CosClient) UploadPart(ctx context.Context, cosPath, uploadID string, partNumber int, r io.Reader, size int64) (string, error) {
+	resp, err := c.Client.Object.UploadPart(ctx, cosPath, uploadID, partNumber, r, &cos.ObjectUploadPartOptions{ContentLength: size})
+	if err != nil {
+		zap.L().Error("c.Client.Object.UploadPart() err:", zap.String("cosPath", cosPath), zap.Int("partNumber", partNumber), zap.Error(err))
+		return "", err
+	}
+	return resp.Header.Get("ETag"), nil
+}
+
+// CompleteMultipartUpload 按分块号顺序合并分块
+func (c *CosClient) CompleteMultipartUpload(ctx context.Context, cosPath, uploadID string, parts []UploadedPart) error {
+	opt := &cos.CompleteMultipartUploadOptions{Parts: make([]cos.Object, 0, len(parts))}
+	for _, p := range parts {
+		opt.Parts = append(opt.Parts, cos.Object{PartNumber: p.PartNumber, ETag: p.ETag})
+	}
+	if _, _, err := c.Client.Object.CompleteMultipartUpload(ctx, cosPath, uploadID, opt); err != nil {
+		zap.L().Error("c.Client.Object.CompleteMultipartUpload() err:", zap.String("cosPath", cosPath), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// MultipartUpload 进行中（尚未合并或放弃）的分块上传
+type MultipartUpload struct {
+	Key       string
+	UploadID  string
+	Initiated time.Time
+}
+
+// ListMultipartUploads 分页列出指定前缀下所有进行中的分块上传
+func (c *CosClient) ListMultipartUploads(ctx context.Context, prefix string) ([]MultipartUpload, error) {
+	var uploads []MultipartUpload
+	opt := &cos.ListMultipartUploadsOptions{Prefix: prefix, MaxUploads: 1000}
+	for {
+		result, _, err := c.Client.Bucket.ListMultipartUploads(ctx, opt)
+		if err != nil {
+			zap.L().Error("c.Client.Bucket.ListMultipartUploads() err:", zap.String("prefix", prefix), zap.Error(err))
+			return nil, err
+		}
+		for _, u := range result.Uploads {
+			initiated, _ := time.Parse(time.RFC3339, u.Initiated)
+			uploads = append(uploads, MultipartUpload{Key: u.Key, UploadID: u.UploadID, Initiated: initiated})
+		}
+		if !result.IsTruncated {
+			return uploads, nil
+		}
+		opt.KeyMarker, opt.UploadIDMarker = result.NextKeyMarker, result.NextUploadIDMarker
+	}
+}
 
+// AbortMultipartUpload 放弃分块上传，COS 会删除已上传的分块
+func (c *CosClient) AbortMultipartUpload(ctx context.Context, cosPath, uploadID string) error {
+	if _, err := c.Client.Object.AbortMultipartUpload(ctx, cosPath, uploadID); err != nil {
+		zap.L().Error("c.Client.Object.AbortMultipartUpload() err:", zap.String("cosPath", cosPath), zap.Error(err))
+		return err
+	}
 	return nil
 }
+
+// PresignPutURL 生成限时的 PUT 上传地址；contentMd5 (base64) 参与签名，客户端上传时必须携带相同的 Content-MD5 请求头，COS 会校验内容
+func (c *CosClient) PresignPutURL(ctx context.Context, cosPath, contentMd5 string, expire time.Duration) (string, error) {
+	header := http.Header{}
+	header.Set("Content-MD5", contentMd5)
+	u, err := c.Client.Object.GetPresignedURL2(ctx, http.MethodPut, cosPath, expire, &cos.PresignedURLOptions{Header: &header})
+	if err != nil {
+		zap.L().Error("c.Client.Object.GetPresignedURL2() err:", zap.String("cosPath", cosPath), zap.Error(err))
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// PresignGetURL 生成限时的下载地址，filename 非空时以附件形式下载
+func (c *CosClient) PresignGetURL(ctx context.Context, cosPath, filename string, expire time.Duration) (string, error) {
+	var opt *cos.ObjectGetOptions
+	if filename != "" {
+		opt = &cos.ObjectGetOptions{ResponseContentDisposition: fmt.Sprintf("attachment; filename=%q", filename)}
+	}
+	u, err := c.Client.Object.GetPresignedURL2(ctx, http.MethodGet, cosPath, expire, opt)
+	if err != nil {
+		zap.L().Error("c.Client.Object.GetPresignedURL2() err:", zap.String("cosPath", cosPath), zap.Error(err))
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// HeadObject 查询对象的大小和 ETag（已去掉引号）
+func (c *CosClient) HeadObject(ctx context.Context, cosPath string) (ObjectInfo, error) {
+	resp, err := c.Client.Object.Head(ctx, cosPath, nil)
+	if err != nil {
+		zap.L().Error("c.Client.Object.Head() err:", zap.String("cosPath", cosPath), zap.Error(err))
+		return ObjectInfo{}, err
+	}
+	return ObjectInfo{
+		Key:  cosPath,
+		Size: resp.ContentLength,
+		ETag: strings.Trim(resp.Header.Get("ETag"), `"`),
+	}, nil
+}
+
+// GetObjectHead 读取对象的前 n 个字节，用于嗅探文件类型
+func (c *CosClient) GetObjectHead(ctx context.Context, cosPath string, n int64) ([]byte, error) {
+	resp, err := c.Client.Object.Get(ctx, cosPath, &cos.ObjectGetOptions{Range: fmt.Sprintf("bytes=0-%d", n-1)})
+	if err != nil {
+		zap.L().Error("c.Client.Object.Get() err:", zap.String("cosPath", cosPath), zap.Error(err))
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(io.LimitReader(resp.Body, n))
+}
@@ -0,0 +1,125 @@
+package util
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	// 注册 image.DecodeConfig 需要的解码器（png/jpeg 已由 image.go 引入）
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/webp"
+	_ "image/gif"
+)
+
+// sniffLen 读取文件头的字节数，SVG 可能在较长的 XML 声明/注释之后才出现 <svg 标签
+const sniffLen = 4096
+
+// vectorTypes 矢量格式，可以作为边缘计算主输入文件
+var vectorTypes = map[string]bool{"svg": true, "ai": true, "eps": true, "pdf": true}
+
+// bitmapTypes 位图格式，需要检查尺寸
+var bitmapTypes = map[string]bool{"png": true, "jpg": true, "gif": true, "webp": true, "bmp": true}
+
+// archiveTypes 允许上传的压缩包格式
+var archiveTypes = map[string]bool{"zip": true, "rar": true}
+
+// unsniffableTypes 部分文件没有可识别的文件头（如旧版 AI、不以 %!PS 开头的 EPS），内容无法识别时不视为类型不符
+var unsniffableTypes = map[string]bool{"ai": true, "eps": true}
+
+// typeAliases 声明类型的别名
+var typeAliases = map[string]string{"jpeg": "jpg"}
+
+// compatibleTypes 声明类型与嗅探结果的兼容关系：AI 文件本质上是 PDF 或 PostScript
+var compatibleTypes = map[string][]string{
+	"ai": {"ai", "pdf", "eps"},
+}
+
+// NormalizeFileType 统一声明的文件类型：去掉前导点、转小写并处理别名
+func NormalizeFileType(t string) string {
+	t = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(t), "."))
+	if alias, ok := typeAliases[t]; ok {
+		return alias
+	}
+	return t
+}
+
+// IsVectorType 是否为矢量格式
+func IsVectorType(t string) bool {
+	return vectorTypes[NormalizeFileType(t)]
+}
+
+// IsBitmapType 是否为位图格式
+func IsBitmapType(t string) bool {
+	return bitmapTypes[NormalizeFileType(t)]
+}
+
+// IsUploadType 是否为允许上传的类型：矢量、位图和压缩包
+func IsUploadType(t string) bool {
+	t = NormalizeFileType(t)
+	return vectorTypes[t] || bitmapTypes[t] || archiveTypes[t]
+}
+
+// AllowsUnknownContent 内容无法识别时是否仍接受该声明类型
+func AllowsUnknownContent(t string) bool {
+	return unsniffableTypes[NormalizeFileType(t)]
+}
+
+// FileTypeMatches 嗅探出的实际类型是否与声明类型一致
+func FileTypeMatches(declared, sniffed string) bool {
+	declared = NormalizeFileType(declared)
+	if declared == sniffed {
+		return true
+	}
+	for _, t := range compatibleTypes[declared] {
+		if t == sniffed {
+			return true
+		}
+	}
+	return false
+}
+
+// SniffFileType 根据文件内容判断实际类型，返回统一后的类型名（如 svg、png、jpg、zip）以及检测到的 MIME，无法识别时类型为空
+func SniffFileType(fileHeader *multipart.FileHeader) (string, string, error) {
+	f, err := fileHeader.Open()
+	if err != nil {
+		return "", "", err
+	}
+	defer f.Close()
+
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", "", err
+	}
+	head = head[:n]
+	mime := http.DetectContentType(head)
+
+	switch {
+	case strings.HasPrefix(mime, "image/png"):
+		return "png", mime, nil
+	case strings.HasPrefix(mime, "image/jpeg"):
+		return "jpg", mime, nil
+	case strings.HasPrefix(mime, "image/gif"):
+		return "gif", mime, nil
+	case strings.HasPrefix(mime, "image/webp"):
+		return "webp", mime, nil
+	case strings.HasPrefix(mime, "image/bmp"):
+		return "bmp", mime, nil
+	case strings.HasPrefix(mime, "application/pdf"):
+		return "pdf", mime, nil
+	case strings.HasPrefix(mime, "application/postscript"):
+		return "eps", mime, nil
+	case strings.HasPrefix(mime, "application/zip"):
+		return "zip", mime, nil
+	case strings.HasPrefix(mime, "application/x-rar-compressed"):
+		return "rar", mime, nil
+	case strings.HasPrefix(mime, "text/"):
+		// SVG 会被识别为 text/xml 或 text/plain，需要检查是否包含 <svg 根元素
+		if bytes.Contains(bytes.ToLower(head), []byte("<svg")) {
+			return "svg", "image/svg+xml", nil
+		}
+	}
+	return "", mime, nil
+}
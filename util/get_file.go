@@ -1,8 +1,10 @@
 package util
 
 import (
+	"bytes"
 	"crypto/md5"
 	"encoding/hex"
+	"fmt"
 	"image"
 	"io"
 	"mime/multipart"
@@ -40,3 +42,29 @@ func GetImageInfo(fileHeader *multipart.FileHeader) (width, height int, isVector
 
 	return 0, 0, 0, 0
 }
+
+// NewFileHeader 把内存中的文件内容包装成 multipart.FileHeader，便于复用基于上传文件的校验、MD5 和 COS 上传逻辑
+func NewFileHeader(filename string, data []byte) (*multipart.FileHeader, error) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("file", filename)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = part.Write(data); err != nil {
+		return nil, err
+	}
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+	// maxMemory 大于文件大小，保证内容留在内存中而不是写入临时文件
+	form, err := multipart.NewReader(&body, w.Boundary()).ReadForm(int64(len(data)) + 1<<20)
+	if err != nil {
+		return nil, err
+	}
+	files := form.File["file"]
+	if len(files) == 0 {
+		return nil, fmt.Errorf("failed to build file header for %s", filename)
+	}
+	return files[0], nil
+}
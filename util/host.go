@@ -0,0 +1,37 @@
+package util
+
+import (
+	"net/url"
+	"strings"
+)
+
+// HostFromURL 从网址或域名中取出小写主机名并去掉 www. 前缀，如 https://www.PKU.edu.cn/index.htm -> pku.edu.cn
+// 输入不像域名（没有点号、含空格或非 ASCII 字符）时返回空串
+func HostFromURL(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" || strings.ContainsAny(s, " \t") {
+		return ""
+	}
+	if !strings.Contains(s, "://") {
+		s = "http://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return ""
+	}
+	host := strings.TrimSuffix(strings.TrimPrefix(u.Hostname(), "www."), ".")
+	if !strings.Contains(host, ".") {
+		return ""
+	}
+	for _, r := range host {
+		if r >= 0x80 {
+			return ""
+		}
+	}
+	return host
+}
+
+// HostMatches host 与 siteHost 相同或是其子域名，如 lib.pku.edu.cn 匹配 pku.edu.cn
+func HostMatches(host, siteHost string) bool {
+	return host != "" && siteHost != "" && (host == siteHost || strings.HasSuffix(host, "."+siteHost))
+}
@@ -0,0 +1,246 @@
+package util
+
+import (
+	"bytes"
+	"fmt"
+	xdraw "golang.org/x/image/draw"
+	"image"
+	"io"
+	"math"
+	"mime/multipart"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// paletteSampleSize 提取主色前把图片缩小到的边长(px)
+	paletteSampleSize = 64
+	// paletteMaxColors 每张图片保留的主色数量
+	paletteMaxColors = 5
+	// paletteMergeDistance RGB 距离小于该值的颜色合并为同一主色
+	paletteMergeDistance = 48
+	// maxRGBDistance RGB 空间中的最大欧氏距离，用于归一化
+	maxRGBDistance = 441.67
+)
+
+// PaletteColor 主色及其在不透明像素中的占比
+type PaletteColor struct {
+	R, G, B uint8
+	Weight  float64 // 0~1
+}
+
+// Hex 以 NormalizeColor 相同的 #RRGGBB 格式输出
+func (p PaletteColor) Hex() string {
+	return fmt.Sprintf("#%02X%02X%02X", p.R, p.G, p.B)
+}
+
+// ImageFeatures 资源图片的检索特征：感知哈希和主色
+type ImageFeatures struct {
+	PHash   string
+	Palette string // FormatPalette 的结果
+}
+
+// ComputeImageFeatures 计算上传文件的检索特征；ai/eps/pdf/压缩包无法解码，返回空特征
+func ComputeImageFeatures(fileHeader *multipart.FileHeader, fileType string) (ImageFeatures, error) {
+	fileType = NormalizeFileType(fileType)
+	if !IsBitmapType(fileType) && fileType != "svg" {
+		return ImageFeatures{}, nil
+	}
+	f, err := fileHeader.Open()
+	if err != nil {
+		return ImageFeatures{}, err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return ImageFeatures{}, err
+	}
+	return ComputeImageFeaturesBytes(data, fileType)
+}
+
+// ComputeImageFeaturesBytes 与 ComputeImageFeatures 相同，输入为内存中的文件内容
+func ComputeImageFeaturesBytes(data []byte, fileType string) (ImageFeatures, error) {
+	img, err := DecodeLogoImage(data, fileType)
+	if err != nil && NormalizeFileType(fileType) == "svg" {
+		// 栅格化失败（如缺少 rsvg-convert）时，主色退回到直接读取 SVG 中的颜色值
+		if palette := ExtractSvgPalette(data); len(palette) > 0 {
+			return ImageFeatures{Palette: FormatPalette(palette)}, nil
+		}
+	}
+	if err != nil || img == nil {
+		return ImageFeatures{}, err
+	}
+	return ImageFeatures{
+		PHash:   FormatPHash(DHash(img)),
+		Palette: FormatPalette(ExtractPalette(img)),
+	}, nil
+}
+
+// DecodeLogoImage 解码位图，SVG 先用 rsvg-convert 栅格化；其他类型返回 nil
+func DecodeLogoImage(data []byte, fileType string) (image.Image, error) {
+	fileType = NormalizeFileType(fileType)
+	switch {
+	case IsBitmapType(fileType):
+		img, _, err := image.Decode(bytes.NewReader(data))
+		return img, err
+	case fileType == "svg":
+		return rasterizeSvg(data)
+	}
+	return nil, nil
+}
+
+// ExtractPalette 提取图片主色：缩小后按 4bit/通道量化统计，再把相近的颜色合并，透明像素不参与统计
+func ExtractPalette(img image.Image) []PaletteColor {
+	small := image.NewNRGBA(image.Rect(0, 0, paletteSampleSize, paletteSampleSize))
+	xdraw.ApproxBiLinear.Scale(small, small.Bounds(), img, img.Bounds(), xdraw.Src, nil)
+
+	buckets := make(map[int]*paletteBucket)
+	total := 0
+	for y := 0; y < paletteSampleSize; y++ {
+		for x := 0; x < paletteSampleSize; x++ {
+			c := small.NRGBAAt(x, y)
+			if c.A < 128 {
+				continue
+			}
+			key := int(c.R>>4)<<8 | int(c.G>>4)<<4 | int(c.B>>4)
+			bk, ok := buckets[key]
+			if !ok {
+				bk = &paletteBucket{}
+				buckets[key] = bk
+			}
+			bk.add(float64(c.R), float64(c.G), float64(c.B))
+			total++
+		}
+	}
+	return mergePalette(buckets, total)
+}
+
+// paletteBucket 同一量化颜色的累加值，用于求平均色
+type paletteBucket struct {
+	r, g, b float64
+	count   int
+}
+
+func (bk *paletteBucket) add(r, g, b float64) {
+	bk.r += r
+	bk.g += g
+	bk.b += b
+	bk.count++
+}
+
+func (bk *paletteBucket) mean() (r, g, b float64) {
+	n := float64(bk.count)
+	return bk.r / n, bk.g / n, bk.b / n
+}
+
+// mergePalette 从占比最大的颜色开始，与已选主色相近的合并进去，保留前 paletteMaxColors 个
+func mergePalette(buckets map[int]*paletteBucket, total int) []PaletteColor {
+	if total == 0 {
+		return nil
+	}
+	sorted := make([]*paletteBucket, 0, len(buckets))
+	for _, bk := range buckets {
+		sorted = append(sorted, bk)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].count > sorted[j].count })
+
+	var merged []*paletteBucket
+	for _, bk := range sorted {
+		r, g, b := bk.mean()
+		joined := false
+		for _, m := range merged {
+			mr, mg, mb := m.mean()
+			if math.Sqrt((r-mr)*(r-mr)+(g-mg)*(g-mg)+(b-mb)*(b-mb)) < paletteMergeDistance {
+				m.r += bk.r
+				m.g += bk.g
+				m.b += bk.b
+				m.count += bk.count
+				joined = true
+				break
+			}
+		}
+		if !joined {
+			cp := *bk
+			merged = append(merged, &cp)
+		}
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].count > merged[j].count })
+	if len(merged) > paletteMaxColors {
+		merged = merged[:paletteMaxColors]
+	}
+	palette := make([]PaletteColor, 0, len(merged))
+	for _, m := range merged {
+		r, g, b := m.mean()
+		palette = append(palette, PaletteColor{
+			R:      uint8(math.Round(r)),
+			G:      uint8(math.Round(g)),
+			B:      uint8(math.Round(b)),
+			Weight: float64(m.count) / float64(total),
+		})
+	}
+	return palette
+}
+
+// FormatPalette 序列化主色，格式为 "#RRGGBB:占比百分数,..."，如 "#003F88:62,#FFFFFF:30"
+func FormatPalette(palette []PaletteColor) string {
+	parts := make([]string, 0, len(palette))
+	for _, p := range palette {
+		parts = append(parts, fmt.Sprintf("%s:%d", p.Hex(), int(math.Round(p.Weight*100))))
+	}
+	return strings.Join(parts, ",")
+}
+
+// ParsePalette 解析 FormatPalette 的结果，格式错误的项被忽略
+func ParsePalette(s string) []PaletteColor {
+	var palette []PaletteColor
+	for _, part := range strings.Split(s, ",") {
+		hex, pct, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok || len(hex) != 7 || hex[0] != '#' {
+			continue
+		}
+		rgb, err := strconv.ParseUint(hex[1:], 16, 32)
+		if err != nil {
+			continue
+		}
+		weight, err := strconv.Atoi(pct)
+		if err != nil {
+			continue
+		}
+		palette = append(palette, PaletteColor{
+			R:      uint8(rgb >> 16),
+			G:      uint8(rgb >> 8),
+			B:      uint8(rgb),
+			Weight: float64(weight) / 100,
+		})
+	}
+	return palette
+}
+
+// PaletteSimilarity 两组主色的相似度 (0~1)：每个主色按占比加权，取另一组中最接近颜色的距离，双向平均
+func PaletteSimilarity(a, b []PaletteColor) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	return 1 - (paletteDistance(a, b)+paletteDistance(b, a))/2
+}
+
+// paletteDistance a 中每个主色到 b 中最近颜色的加权平均距离，归一化到 0~1
+func paletteDistance(a, b []PaletteColor) float64 {
+	var sum, weights float64
+	for _, ca := range a {
+		nearest := maxRGBDistance
+		for _, cb := range b {
+			dr := float64(ca.R) - float64(cb.R)
+			dg := float64(ca.G) - float64(cb.G)
+			db := float64(ca.B) - float64(cb.B)
+			nearest = math.Min(nearest, math.Sqrt(dr*dr+dg*dg+db*db))
+		}
+		sum += ca.Weight * nearest / maxRGBDistance
+		weights += ca.Weight
+	}
+	if weights == 0 {
+		return 1
+	}
+	return sum / weights
+}
@@ -0,0 +1,77 @@
+package util
+
+import (
+	"fmt"
+	xdraw "golang.org/x/image/draw"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// phashRasterSize 矢量文件栅格化后计算感知哈希的边长(px)，dHash 只需要 9x8，足够即可
+const phashRasterSize = 256
+
+// DHash 计算图片的差异哈希 (dHash)：缩放为 9x8 灰度图，比较每行相邻像素的亮度得到 64 位
+// 透明区域先铺白底，避免同一 logo 的透明/白底版本得到不同的哈希
+func DHash(img image.Image) uint64 {
+	flat := image.NewRGBA(img.Bounds())
+	draw.Draw(flat, flat.Bounds(), &image.Uniform{C: color.White}, image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), img, img.Bounds().Min, draw.Over)
+
+	small := image.NewGray(image.Rect(0, 0, 9, 8))
+	xdraw.ApproxBiLinear.Scale(small, small.Bounds(), flat, flat.Bounds(), xdraw.Src, nil)
+
+	var hash uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			hash <<= 1
+			if small.GrayAt(x, y).Y < small.GrayAt(x+1, y).Y {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// FormatPHash 把哈希格式化为 16 位十六进制字符串，便于存储和比较
+func FormatPHash(hash uint64) string {
+	return fmt.Sprintf("%016x", hash)
+}
+
+// PHashDistance 两个十六进制哈希的汉明距离，任一哈希无效时返回 -1
+func PHashDistance(a, b string) int {
+	ha, errA := strconv.ParseUint(a, 16, 64)
+	hb, errB := strconv.ParseUint(b, 16, 64)
+	if errA != nil || errB != nil || len(a) != 16 || len(b) != 16 {
+		return -1
+	}
+	return bits.OnesCount64(ha ^ hb)
+}
+
+// rasterizeSvg 把 SVG 写入临时目录并转换为 PNG 后解码
+func rasterizeSvg(data []byte) (image.Image, error) {
+	dir, err := os.MkdirTemp("", "logo-raster-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	svgPath := filepath.Join(dir, "src.svg")
+	pngPath := filepath.Join(dir, "out.png")
+	if err = os.WriteFile(svgPath, data, 0o644); err != nil {
+		return nil, err
+	}
+	if err = ConvertSvgToBitmap(svgPath, pngPath, "png", phashRasterSize, 0, 0, "#FFFFFF"); err != nil {
+		return nil, err
+	}
+	out, err := os.Open(pngPath)
+	if err != nil {
+		return nil, err
+	}
+	defer out.Close()
+	return png.Decode(out)
+}
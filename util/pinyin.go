@@ -0,0 +1,92 @@
+package util
+
+import (
+	"github.com/mozillazg/go-pinyin"
+	"strings"
+	"unicode"
+)
+
+// pinyinPhraseOverrides 高校名称中常见的多音字词组，go-pinyin 逐字取第一个读音，这些词组需要单独指定
+var pinyinPhraseOverrides = map[string][]string{
+	"重庆": {"chong", "qing"},
+	"长春": {"chang", "chun"},
+	"长沙": {"chang", "sha"},
+	"长安": {"chang", "an"},
+	"长江": {"chang", "jiang"},
+	"长治": {"chang", "zhi"},
+	"延长": {"yan", "chang"},
+	"厦门": {"xia", "men"},
+	"蚌埠": {"beng", "bu"},
+	"六安": {"lu", "an"},
+	"番禺": {"pan", "yu"},
+	"银行": {"yin", "hang"},
+	"音乐": {"yin", "yue"},
+	"会计": {"kuai", "ji"},
+}
+
+// maxPinyinPhraseLen pinyinPhraseOverrides 中最长词组的字数
+const maxPinyinPhraseLen = 2
+
+// TitlePinyin 计算高校中文名称的全拼和首字母，如 山东理工大学 -> shandongligongdaxue, sdlgdx
+// 英文字母和数字原样保留（转为小写），括号、空格等其他字符忽略
+func TitlePinyin(title string) (full, initials string) {
+	args := pinyin.NewArgs()
+	runes := []rune(title)
+	var fullBuilder, initialBuilder strings.Builder
+	write := func(syllable string) {
+		if syllable == "" {
+			return
+		}
+		fullBuilder.WriteString(syllable)
+		initialBuilder.WriteByte(syllable[0])
+	}
+	for i := 0; i < len(runes); {
+		if syllables, n := matchPinyinPhrase(runes[i:]); n > 0 {
+			for _, s := range syllables {
+				write(s)
+			}
+			i += n
+			continue
+		}
+		r := runes[i]
+		switch {
+		case unicode.Is(unicode.Han, r):
+			if p := pinyin.SinglePinyin(r, args); len(p) > 0 {
+				write(p[0])
+			}
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			lower := byte(unicode.ToLower(r))
+			fullBuilder.WriteByte(lower)
+			initialBuilder.WriteByte(lower)
+		}
+		i++
+	}
+	return fullBuilder.String(), initialBuilder.String()
+}
+
+// matchPinyinPhrase 从 runes 开头匹配最长的多音字词组
+func matchPinyinPhrase(runes []rune) ([]string, int) {
+	for n := min(maxPinyinPhraseLen, len(runes)); n >= 2; n-- {
+		if syllables, ok := pinyinPhraseOverrides[string(runes[:n])]; ok {
+			return syllables, n
+		}
+	}
+	return nil, 0
+}
+
+// NormalizePinyinQuery 把用户输入的拼音关键字规整为与存储一致的形式：小写，去掉空格、隔音符号和连字符
+// 关键字中含有字母数字以外的字符（如汉字）时返回空串，表示不按拼音匹配
+func NormalizePinyinQuery(keyword string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(keyword) {
+		switch {
+		case r == ' ' || r == '\'' || r == '-' || r == '’':
+			continue
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b.WriteRune(r)
+		default:
+			return ""
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,55 @@
+package util
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// svgColorRegex 匹配 SVG 属性或 style 中的 fill / stroke / stop-color 颜色值
+var svgColorRegex = regexp.MustCompile(`(?i)(?:fill|stroke|stop-color)\s*[=:]\s*["']?\s*(#[0-9a-f]{3,8}\b|rgba?\([^)]*\)|[a-z]+)`)
+
+// ExtractSvgPalette 直接读取 SVG 源码中的颜色值提取主色，按出现次数加权
+// 无需栅格化，得到的是设计稿中的原始品牌色；none、url(#gradient)、currentColor 等非具体颜色被忽略
+func ExtractSvgPalette(data []byte) []PaletteColor {
+	buckets := make(map[int]*paletteBucket)
+	total := 0
+	for _, m := range svgColorRegex.FindAllSubmatch(data, -1) {
+		hex := svgColorToHex(string(m[1]))
+		if hex == "" {
+			continue
+		}
+		rgb, err := strconv.ParseUint(hex[1:], 16, 32)
+		if err != nil {
+			continue
+		}
+		key := int(rgb)
+		bk, ok := buckets[key]
+		if !ok {
+			bk = &paletteBucket{}
+			buckets[key] = bk
+		}
+		bk.add(float64(rgb>>16&0xFF), float64(rgb>>8&0xFF), float64(rgb&0xFF))
+		total++
+	}
+	return mergePalette(buckets, total)
+}
+
+// svgColorToHex 把 SVG 中的颜色值统一为 NormalizeColor 的 #RRGGBB 格式，不是具体颜色时返回空串
+func svgColorToHex(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if !strings.HasPrefix(value, "#") && !strings.HasPrefix(value, "rgb") {
+		// 颜色名称只接受已知的，避免 none / inherit 等关键字被当作十六进制解析
+		if _, ok := colorNames[value]; !ok {
+			return ""
+		}
+	}
+	hex := NormalizeColor(value)
+	if len(hex) != 7 {
+		return ""
+	}
+	if _, err := strconv.ParseUint(hex[1:], 16, 32); err != nil {
+		return ""
+	}
+	return hex
+}
@@ -0,0 +1,217 @@
+package util
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// 表格导入导出支持的格式
+const (
+	TableFormatCSV  = "csv"
+	TableFormatXLSX = "xlsx"
+	TableFormatJSON = "json"
+)
+
+// utf8BOM Excel 需要 BOM 才能正确识别 UTF-8 编码的 CSV
+const utf8BOM = "\ufeff"
+
+var (
+	// ErrTableFormatUnsupported 不支持的表格格式
+	ErrTableFormatUnsupported = errors.New("unsupported table format, expected csv, xlsx or json")
+	// ErrTableInvalid 表格内容无法解析
+	ErrTableInvalid = errors.New("table file cannot be parsed")
+)
+
+// TableRow 表格中的一行数据，Line 为源文件中的行号（JSON 为数组中的第几个对象），从 1 开始
+type TableRow struct {
+	Line  int
+	Cells []string
+}
+
+// DetectTableFormat 优先使用显式指定的格式，否则按文件扩展名判断
+func DetectTableFormat(format, filename string) (string, error) {
+	if format == "" {
+		format = strings.TrimPrefix(path.Ext(filename), ".")
+	}
+	switch f := strings.ToLower(format); f {
+	case TableFormatCSV, TableFormatXLSX, TableFormatJSON:
+		return f, nil
+	}
+	return "", ErrTableFormatUnsupported
+}
+
+// TableContentType 导出文件的 Content-Type
+func TableContentType(format string) string {
+	switch format {
+	case TableFormatCSV:
+		return "text/csv; charset=utf-8"
+	case TableFormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case TableFormatJSON:
+		return "application/json; charset=utf-8"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// ReadTable 读取表格，返回表头和数据行；空行被跳过，数据行的单元格数与表头一致
+// CSV/XLSX 的第一行为表头；JSON 须为对象数组，表头为全部对象键的并集（按首次出现的顺序）
+func ReadTable(format string, data []byte) (header []string, rows []TableRow, err error) {
+	var records []TableRow
+	switch format {
+	case TableFormatCSV:
+		records, err = readCSV(data)
+	case TableFormatXLSX:
+		records, err = ReadXLSX(data)
+	case TableFormatJSON:
+		return readJSONTable(data)
+	default:
+		return nil, nil, ErrTableFormatUnsupported
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	for len(records) > 0 && isBlankRow(records[0].Cells) {
+		records = records[1:]
+	}
+	if len(records) == 0 {
+		return nil, nil, fmt.Errorf("%w: missing header row", ErrTableInvalid)
+	}
+	for _, h := range records[0].Cells {
+		header = append(header, strings.TrimSpace(h))
+	}
+	for _, r := range records[1:] {
+		if isBlankRow(r.Cells) {
+			continue
+		}
+		cells := make([]string, len(header))
+		copy(cells, r.Cells)
+		rows = append(rows, TableRow{Line: r.Line, Cells: cells})
+	}
+	return header, rows, nil
+}
+
+// WriteTable 把表头和数据行写成 CSV 或 XLSX；JSON 导出直接序列化结构体，不经过这里
+func WriteTable(format string, w io.Writer, header []string, rows [][]string) error {
+	switch format {
+	case TableFormatCSV:
+		if _, err := io.WriteString(w, utf8BOM); err != nil {
+			return err
+		}
+		cw := csv.NewWriter(w)
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+		if err := cw.WriteAll(rows); err != nil {
+			return err
+		}
+		return cw.Error()
+	case TableFormatXLSX:
+		return WriteXLSX(w, "Sheet1", append([][]string{header}, rows...))
+	default:
+		return ErrTableFormatUnsupported
+	}
+}
+
+// readCSV 读取全部记录及其起始行号，允许各行列数不同
+func readCSV(data []byte) ([]TableRow, error) {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte(utf8BOM))))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	var rows []TableRow
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrTableInvalid, err)
+		}
+		line, _ := r.FieldPos(0)
+		rows = append(rows, TableRow{Line: line, Cells: record})
+	}
+}
+
+// readJSONTable 按顺序读取对象数组，保留键的首次出现顺序作为表头；null 视为空串，数字和布尔值保留原文
+func readJSONTable(data []byte) (header []string, rows []TableRow, err error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+		return nil, nil, fmt.Errorf("%w: expected an array of objects", ErrTableInvalid)
+	}
+	columns := make(map[string]int)
+	for dec.More() {
+		if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+			return nil, nil, fmt.Errorf("%w: item %d is not an object", ErrTableInvalid, len(rows)+1)
+		}
+		object := make(map[string]json.RawMessage)
+		var keys []string
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, nil, fmt.Errorf("%w: %v", ErrTableInvalid, err)
+			}
+			key, _ := tok.(string)
+			var value json.RawMessage
+			if err = dec.Decode(&value); err != nil {
+				return nil, nil, fmt.Errorf("%w: %v", ErrTableInvalid, err)
+			}
+			if _, ok := object[key]; !ok {
+				keys = append(keys, key)
+			}
+			object[key] = value
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrTableInvalid, err)
+		}
+		for _, key := range keys {
+			if _, ok := columns[key]; !ok {
+				columns[key] = len(header)
+				header = append(header, key)
+			}
+		}
+		cells := make([]string, len(header))
+		for key, value := range object {
+			cells[columns[key]] = jsonCellText(value)
+		}
+		rows = append(rows, TableRow{Line: len(rows) + 1, Cells: cells})
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrTableInvalid, err)
+	}
+	// 先出现的对象可能缺少后出现的键，补齐到表头长度
+	for i := range rows {
+		if n := len(rows[i].Cells); n < len(header) {
+			rows[i].Cells = append(rows[i].Cells, make([]string, len(header)-n)...)
+		}
+	}
+	return header, rows, nil
+}
+
+// jsonCellText 把 JSON 值转成单元格文本
+func jsonCellText(value json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(value, &s); err == nil {
+		return s
+	}
+	if text := string(bytes.TrimSpace(value)); text != "null" {
+		return text
+	}
+	return ""
+}
+
+// isBlankRow 是否所有单元格都为空白
+func isBlankRow(cells []string) bool {
+	for _, c := range cells {
+		if strings.TrimSpace(c) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,273 @@
+package util
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	// xlsxMaxPartSize 单个 XML 部件解压后的大小上限，防止压缩炸弹
+	xlsxMaxPartSize = 64 << 20
+	// xlsxMaxColumns Excel 的最大列数 (XFD)
+	xlsxMaxColumns = 16384
+	// xlsxRelNS 工作簿关系的命名空间，r:id 属性位于其中
+	xlsxRelNS = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+)
+
+// xlsxWorkbook xl/workbook.xml 中的工作表列表
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+// xlsxRelationships xl/_rels/workbook.xml.rels
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// xlsxText 共享字符串或内联字符串，富文本由多个 r 片段组成
+type xlsxText struct {
+	T string `xml:"t"`
+	R []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.R) == 0 {
+		return t.T
+	}
+	var sb strings.Builder
+	for _, r := range t.R {
+		sb.WriteString(r.T)
+	}
+	return sb.String()
+}
+
+// xlsxSheet 工作表中的单元格数据
+type xlsxSheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string   `xml:"r,attr"`
+			Type   string   `xml:"t,attr"`
+			Value  string   `xml:"v"`
+			Inline xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// ReadXLSX 读取工作簿中第一个工作表的全部行，Line 为 Excel 中的行号
+// 只取单元格的值：数字保留原文，日期等格式不做转换，公式取缓存的计算结果
+func ReadXLSX(data []byte) ([]TableRow, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: not a valid xlsx file", ErrTableInvalid)
+	}
+	parts := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		parts[f.Name] = f
+	}
+
+	sheetPath, err := xlsxFirstSheetPath(parts)
+	if err != nil {
+		return nil, err
+	}
+	var shared []string
+	if f, ok := parts["xl/sharedStrings.xml"]; ok {
+		var sst struct {
+			Items []xlsxText `xml:"si"`
+		}
+		if err = decodeXLSXPart(f, &sst); err != nil {
+			return nil, err
+		}
+		for _, si := range sst.Items {
+			shared = append(shared, si.String())
+		}
+	}
+	f, ok := parts[sheetPath]
+	if !ok {
+		return nil, fmt.Errorf("%w: worksheet %s not found", ErrTableInvalid, sheetPath)
+	}
+	var sheet xlsxSheet
+	if err = decodeXLSXPart(f, &sheet); err != nil {
+		return nil, err
+	}
+
+	rows := make([]TableRow, 0, len(sheet.Rows))
+	for i, row := range sheet.Rows {
+		line := row.R
+		if line == 0 {
+			line = i + 1
+		}
+		var cells []string
+		for j, c := range row.Cells {
+			col := j
+			if c.Ref != "" {
+				if col, err = xlsxColumnIndex(c.Ref); err != nil {
+					return nil, err
+				}
+			}
+			var value string
+			switch c.Type {
+			case "s":
+				idx, err := strconv.Atoi(strings.TrimSpace(c.Value))
+				if err != nil || idx < 0 || idx >= len(shared) {
+					return nil, fmt.Errorf("%w: invalid shared string index in cell %s", ErrTableInvalid, c.Ref)
+				}
+				value = shared[idx]
+			case "inlineStr":
+				value = c.Inline.String()
+			default:
+				value = c.Value
+			}
+			for len(cells) <= col {
+				cells = append(cells, "")
+			}
+			cells[col] = value
+		}
+		rows = append(rows, TableRow{Line: line, Cells: cells})
+	}
+	return rows, nil
+}
+
+// xlsxFirstSheetPath 通过 workbook.xml 和它的关系文件找到第一个工作表的路径
+func xlsxFirstSheetPath(parts map[string]*zip.File) (string, error) {
+	const fallback = "xl/worksheets/sheet1.xml"
+	wbFile, ok := parts["xl/workbook.xml"]
+	relsFile, relsOK := parts["xl/_rels/workbook.xml.rels"]
+	if !ok || !relsOK {
+		return fallback, nil
+	}
+	var wb xlsxWorkbook
+	if err := decodeXLSXPart(wbFile, &wb); err != nil {
+		return "", err
+	}
+	var rels xlsxRelationships
+	if err := decodeXLSXPart(relsFile, &rels); err != nil {
+		return "", err
+	}
+	if len(wb.Sheets) == 0 {
+		return "", fmt.Errorf("%w: workbook has no sheets", ErrTableInvalid)
+	}
+	for _, rel := range rels.Relationships {
+		if rel.ID != wb.Sheets[0].RID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return "xl/" + rel.Target, nil
+	}
+	return fallback, nil
+}
+
+// decodeXLSXPart 解析压缩包中的一个 XML 部件
+func decodeXLSXPart(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrTableInvalid, err)
+	}
+	defer rc.Close()
+	if err = xml.NewDecoder(io.LimitReader(rc, xlsxMaxPartSize)).Decode(v); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrTableInvalid, f.Name, err)
+	}
+	return nil
+}
+
+// xlsxColumnIndex 把单元格引用（如 AB12）的列字母转成从 0 开始的列号
+func xlsxColumnIndex(ref string) (int, error) {
+	col := 0
+	for _, r := range ref {
+		if r >= 'a' && r <= 'z' {
+			r -= 'a' - 'A'
+		}
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A') + 1
+		if col > xlsxMaxColumns {
+			return 0, fmt.Errorf("%w: cell reference %s out of range", ErrTableInvalid, ref)
+		}
+	}
+	if col == 0 {
+		return 0, fmt.Errorf("%w: invalid cell reference %s", ErrTableInvalid, ref)
+	}
+	return col - 1, nil
+}
+
+// xlsxColumnName 把从 0 开始的列号转成列字母，如 27 -> AB
+func xlsxColumnName(col int) string {
+	var name []byte
+	for col++; col > 0; col = (col - 1) / 26 {
+		name = append([]byte{byte('A' + (col-1)%26)}, name...)
+	}
+	return string(name)
+}
+
+// WriteXLSX 生成只有一个工作表的最小 xlsx 文件，所有单元格都按文本（内联字符串）写入
+func WriteXLSX(w io.Writer, sheetName string, rows [][]string) error {
+	zw := zip.NewWriter(w)
+	var escapedName bytes.Buffer
+	_ = xml.EscapeText(&escapedName, []byte(sheetName))
+	static := []struct{ name, content string }{
+		{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+			`</Types>`},
+		{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="` + xlsxRelNS + `/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="` + xlsxRelNS + `">` +
+			`<sheets><sheet name="` + escapedName.String() + `" sheetId="1" r:id="rId1"/></sheets>` +
+			`</workbook>`},
+		{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="` + xlsxRelNS + `/worksheet" Target="worksheets/sheet1.xml"/>` +
+			`</Relationships>`},
+	}
+	for _, part := range static {
+		pw, err := zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err = io.WriteString(pw, part.content); err != nil {
+			return err
+		}
+	}
+
+	pw, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	var sb bytes.Buffer
+	sb.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for i, row := range rows {
+		fmt.Fprintf(&sb, `<row r="%d">`, i+1)
+		for j, value := range row {
+			if value == "" {
+				continue
+			}
+			fmt.Fprintf(&sb, `<c r="%s%d" t="inlineStr"><is><t xml:space="preserve">`, xlsxColumnName(j), i+1)
+			_ = xml.EscapeText(&sb, []byte(value))
+			sb.WriteString(`</t></is></c>`)
+		}
+		sb.WriteString(`</row>`)
+	}
+	sb.WriteString(`</sheetData></worksheet>`)
+	if _, err = pw.Write(sb.Bytes()); err != nil {
+		return err
+	}
+	return zw.Close()
+}